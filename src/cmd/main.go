@@ -1,12 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
-
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// Embeds the zone database so X-Timezone works on images without tzdata
+	_ "time/tzdata"
+
+	"github.com/alielmi98/golang-otp-auth/di"
 	"github.com/alielmi98/golang-otp-auth/docs"
 	_ "github.com/alielmi98/golang-otp-auth/docs"
+	abuseHandler "github.com/alielmi98/golang-otp-auth/internal/abuse/api/handler"
+	abuseRouter "github.com/alielmi98/golang-otp-auth/internal/abuse/api/router"
+	healthHandler "github.com/alielmi98/golang-otp-auth/internal/health/api/handler"
+	healthRouter "github.com/alielmi98/golang-otp-auth/internal/health/api/router"
 	"github.com/alielmi98/golang-otp-auth/internal/middlewares"
+	oauthHandler "github.com/alielmi98/golang-otp-auth/internal/oauth/api/handler"
+	oauthRouter "github.com/alielmi98/golang-otp-auth/internal/oauth/api/router"
+	phonePolicyHandler "github.com/alielmi98/golang-otp-auth/internal/phonepolicy/api/handler"
+	phonePolicyRouter "github.com/alielmi98/golang-otp-auth/internal/phonepolicy/api/router"
 	"github.com/alielmi98/golang-otp-auth/internal/user/api/handler"
 	usersRouter "github.com/alielmi98/golang-otp-auth/internal/user/api/router"
 	"github.com/alielmi98/golang-otp-auth/internal/user/api/validation"
@@ -15,12 +32,16 @@ import (
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/alielmi98/golang-otp-auth/pkg/constants"
 	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
 	"github.com/go-playground/validator/v10"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // @securityDefinitions.apikey AuthBearer
@@ -29,37 +50,113 @@ import (
 func main() {
 
 	cfg := config.GetConfig()
+	logging.InitLogger(cfg)
+	logger := logging.GetLogger()
 
-	err := cache.InitRedis(cfg)
+	shutdownTracer, err := tracing.InitTracer(cfg)
+	if err != nil {
+		logger.Fatal(constants.General, constants.Startup, err.Error(), nil)
+	}
+	defer shutdownTracer(context.Background())
+
+	err = cache.InitRedis(cfg)
 	defer cache.CloseRedis()
 	if err != nil {
-		log.Fatalf("caller:%s  Level:%s  Msg:%s", constants.Redis, constants.Startup, err.Error())
+		logger.Fatal(constants.Redis, constants.Startup, err.Error(), nil)
 	}
 
 	err = db.InitDb(cfg)
 	defer db.CloseDb()
 	if err != nil {
-		log.Fatalf("caller:%s  Level:%s  Msg:%s", constants.Postgres, constants.Startup, err.Error())
+		logger.Fatal(constants.Postgres, constants.Startup, err.Error(), nil)
 	}
 
-	migrations.Up1()
+	migrations.Up1(cfg)
+	migrations.Up2()
+	migrations.Up3()
+	migrations.Up4()
+	migrations.Up5()
+	migrations.Up6()
+	migrations.Up7()
+	migrations.Up8()
+	migrations.Up9()
 	InitServer(cfg)
 
 }
 func InitServer(cfg *config.Config) {
 	r := gin.New()
-	RegisterValidators()
+	// Rate limits and abuse scores key on the client IP, so X-Forwarded-For is only read from known proxies
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logging.GetLogger().Fatal(constants.General, constants.Startup, err.Error(), nil)
+	}
+	RegisterValidators(cfg)
 
 	userHandler := handler.NewUserHandler(cfg)
-	r.Use(middlewares.Cors(cfg))
-	RegisterRoutes(r, cfg, userHandler)
+	phonePolicies := phonePolicyHandler.NewPhonePolicyHandler(cfg)
+	abuse := abuseHandler.NewAbuseHandler(cfg)
+	oauth := oauthHandler.NewOAuthHandler(cfg)
+	oauthClients := oauthHandler.NewClientHandler(cfg)
+	health := healthHandler.NewHealthHandler(cfg)
+	// The inner Recovery lets a handler panic still reach the metrics and the access log as a 500;
+	// the outer one catches panics in the middlewares themselves
+	r.Use(middlewares.Recovery(), middlewares.RequestId(), middlewares.Locale(cfg), middlewares.ProblemDetails(cfg),
+		middlewares.Cors(cfg), middlewares.Prometheus(),
+		otelgin.Middleware(cfg.Tracing.ServiceName), middlewares.AccessLog(cfg), middlewares.Recovery())
+	healthRouter.Health(r, health)
+	oauthRouter.WellKnown(r, oauth)
+	RegisterRoutes(r, cfg, userHandler, phonePolicies, abuse, oauth, oauthClients)
 	RegisterSwagger(r, cfg)
-	log.Printf("Caller:%s Level:%s Msg:%s", constants.General, constants.Startup, "Started")
-	r.Run(fmt.Sprintf(":%s", cfg.Server.InternalPort))
 
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.Server.InternalPort),
+		Handler: r,
+	}
+	metricsSrv := NewMetricsServer(cfg)
+	logger := logging.GetLogger()
+	for _, s := range []*http.Server{srv, metricsSrv} {
+		go func(s *http.Server) {
+			logger.Info(constants.General, constants.Startup, "Started "+s.Addr, nil)
+			if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatal(constants.General, constants.Startup, err.Error(), nil)
+			}
+		}(s)
+	}
+
+	WaitForShutdown(cfg, health, srv, metricsSrv)
 }
 
-func RegisterRoutes(r *gin.Engine, cfg *config.Config, userHandler *handler.UsersHandler) {
+// NewMetricsServer serves /metrics on server.metricsPort, which is meant to be reachable by the
+// scraper only and not published like the API port
+func NewMetricsServer(cfg *config.Config) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.Server.MetricsPort),
+		Handler: mux,
+	}
+}
+
+// WaitForShutdown blocks until SIGINT/SIGTERM, fails readiness for the drain delay and then stops the servers
+func WaitForShutdown(cfg *config.Config, health *healthHandler.HealthHandler, servers ...*http.Server) {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger := logging.GetLogger()
+	logger.Info(constants.General, constants.Shutdown, "Shutting down", nil)
+	health.SetShuttingDown()
+	time.Sleep(cfg.Server.DrainDelay * time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout*time.Second)
+	defer cancel()
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Error(constants.General, constants.Shutdown, err.Error(), nil)
+		}
+	}
+}
+
+func RegisterRoutes(r *gin.Engine, cfg *config.Config, userHandler *handler.UsersHandler, phonePolicies *phonePolicyHandler.PhonePolicyHandler, abuse *abuseHandler.AbuseHandler, oauth *oauthHandler.OAuthHandler, oauthClients *oauthHandler.ClientHandler) {
 	api := r.Group("/api")
 
 	v1 := api.Group("/v1")
@@ -68,15 +165,31 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config, userHandler *handler.User
 		users := v1.Group("/users")
 		usersRouter.Users(users, cfg, userHandler)
 
+		//OAuth
+		oauthRouter.OAuth(v1.Group("/oauth"), cfg, oauth)
+
+		//Admin
+		admin := v1.Group("/admin", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)),
+			middlewares.Authorization([]string{constants.AdminRoleName}))
+		phonePolicyRouter.PhonePolicies(admin.Group("/phone-policies"), phonePolicies)
+		abuseRouter.Abuse(admin.Group("/abuse"), abuse)
+		oauthRouter.Clients(admin.Group("/oauth-clients"), oauthClients)
+
 	}
 }
 
-func RegisterValidators() {
+func RegisterValidators(cfg *config.Config) {
 	val, ok := binding.Validator.Engine().(*validator.Validate)
 	if ok {
-		err := val.RegisterValidation("mobile", validation.IranianMobileNumberValidator, true)
+		val.RegisterTagNameFunc(validation.JsonFieldName)
+		err := val.RegisterValidation("mobile", validation.MobileNumberValidator(cfg), true)
+		if err != nil {
+			logging.GetLogger().Error(constants.Validation, constants.Startup, err.Error(), nil)
+		}
+		// Replaces the built-in email rule so binding accepts exactly what the email channel delivers to
+		err = val.RegisterValidation("email", validation.EmailValidator, true)
 		if err != nil {
-			log.Printf("Caller:%s Level:%s Msg:%s", constants.Validation, constants.Startup, err.Error())
+			logging.GetLogger().Error(constants.Validation, constants.Startup, err.Error(), nil)
 		}
 	}
 }
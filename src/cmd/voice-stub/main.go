@@ -0,0 +1,25 @@
+// Command voice-stub serves the stub voice gateway for local runs: set voice.provider to http and
+// voice.url to http://localhost:8091/api/call, and the text each call would read is printed here.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/infra/notification"
+)
+
+func main() {
+	addr := flag.String("addr", ":8091", "listen address")
+	flag.Parse()
+
+	gateway := notification.NewStubVoiceGateway()
+	gateway.OnCall = func(call notification.VoiceCallRequest) {
+		log.Printf("call to %s from %s [%s]: %s", call.To, call.CallerId, call.Language, call.Message)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/call", gateway)
+	log.Printf("stub voice gateway listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
@@ -1,12 +1,29 @@
 package di
 
 import (
+	"sync"
+	"time"
+
 	contractAuth "github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	contractChallenge "github.com/alielmi98/golang-otp-auth/internal/user/domain/challenge"
+	contractNotification "github.com/alielmi98/golang-otp-auth/internal/user/domain/notification"
 	contractAuthRepo "github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
 
 	infraAuth "github.com/alielmi98/golang-otp-auth/internal/user/infra/auth"
+	infraChallenge "github.com/alielmi98/golang-otp-auth/internal/user/infra/challenge"
+	infraNotification "github.com/alielmi98/golang-otp-auth/internal/user/infra/notification"
 	infraAuthRepo "github.com/alielmi98/golang-otp-auth/internal/user/infra/repository"
 
+	infraPhonePolicyRepo "github.com/alielmi98/golang-otp-auth/internal/phonepolicy/infra/repository"
+	phonePolicyUsecase "github.com/alielmi98/golang-otp-auth/internal/phonepolicy/usecase"
+
+	abuseUsecase "github.com/alielmi98/golang-otp-auth/internal/abuse/usecase"
+
+	contractOAuth "github.com/alielmi98/golang-otp-auth/internal/oauth/domain/auth"
+	contractOAuthRepo "github.com/alielmi98/golang-otp-auth/internal/oauth/domain/repository"
+	infraOAuth "github.com/alielmi98/golang-otp-auth/internal/oauth/infra/auth"
+	infraOAuthRepo "github.com/alielmi98/golang-otp-auth/internal/oauth/infra/repository"
+
 	"github.com/alielmi98/golang-otp-auth/pkg/cache"
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
@@ -21,10 +38,155 @@ func GetUserRepository(cfg *config.Config) contractAuthRepo.UserRepository {
 	return infraAuthRepo.NewUserPgRepo()
 }
 
-func GetOtpProvider(cfg *config.Config) contractAuth.OtpProvider {
+func GetOtpAuditRepository(cfg *config.Config) contractAuthRepo.OtpAuditRepository {
+	return infraAuthRepo.NewOtpAuditPgRepo()
+}
+
+// GetSmsSender returns the gateway sender, or a log-only sender when sms.provider is "log"
+func GetSmsSender(cfg *config.Config) contractNotification.SmsSender {
+	if cfg.Sms.Provider == "log" {
+		return infraNotification.NewLogSmsSender()
+	}
+	return infraNotification.NewHttpSmsSender(cfg)
+}
+
+// GetVoiceCaller returns the gateway caller, or a log-only caller when voice.provider is "log"
+func GetVoiceCaller(cfg *config.Config) contractNotification.VoiceCaller {
+	if cfg.Voice.Provider == "log" {
+		return infraNotification.NewLogVoiceCaller()
+	}
+	return infraNotification.NewHttpVoiceCaller(cfg)
+}
+
+// GetEmailSender returns the SMTP sender, or a log-only sender when email.provider is "log"
+func GetEmailSender(cfg *config.Config) contractNotification.EmailSender {
+	if cfg.Email.Provider == "log" {
+		return infraNotification.NewLogEmailSender()
+	}
+	return infraNotification.NewSmtpEmailSender(cfg)
+}
+
+var (
+	phonePolicyOnce    sync.Once
+	phonePolicyService *phonePolicyUsecase.PhonePolicyUsecase
+)
+
+// GetPhonePolicyUsecase returns the process-wide instance, so the admin API and the OTP flow
+// share one in-memory copy of the rules
+func GetPhonePolicyUsecase(cfg *config.Config) *phonePolicyUsecase.PhonePolicyUsecase {
+	phonePolicyOnce.Do(func() {
+		phonePolicyService = phonePolicyUsecase.NewPhonePolicyUsecase(cfg, infraPhonePolicyRepo.NewPhonePolicyPgRepo())
+	})
+	return phonePolicyService
+}
+
+func GetAbuseUsecase(cfg *config.Config) *abuseUsecase.AbuseUsecase {
+	return abuseUsecase.NewAbuseUsecase(cfg)
+}
+
+// GetChallengeVerifier returns the CAPTCHA adapter named by challenge.provider, or the built-in proof of work
+func GetChallengeVerifier(cfg *config.Config) contractChallenge.Verifier {
+	switch cfg.Challenge.Provider {
+	case infraChallenge.ProviderHCaptcha, infraChallenge.ProviderRecaptcha:
+		return infraChallenge.NewCaptchaVerifier(cfg, cfg.Challenge.Provider)
+	default:
+		return infraChallenge.NewPowVerifier(cfg)
+	}
+}
+
+func GetOtpProvider(cfg *config.Config) contractAuth.ResendableOtpProvider {
 	return infraAuth.NewOtpProvider(cfg)
 }
 
+// GetMagicLinkProvider returns the OtpProvider that keeps login-link nonces
+func GetMagicLinkProvider(cfg *config.Config) contractAuth.RevocableOtpProvider {
+	return infraAuth.NewMagicLinkProvider(cfg)
+}
+
+func GetTotpRepository(cfg *config.Config) contractAuthRepo.TotpRepository {
+	return infraAuthRepo.NewTotpPgRepo()
+}
+
+func GetRecoveryCodeRepository(cfg *config.Config) contractAuthRepo.RecoveryCodeRepository {
+	return infraAuthRepo.NewRecoveryCodePgRepo()
+}
+
+func GetPasskeyRepository(cfg *config.Config) contractAuthRepo.PasskeyRepository {
+	return infraAuthRepo.NewPasskeyPgRepo()
+}
+
+// GetPasskeyProvider returns the WebAuthn PasskeyProvider
+func GetPasskeyProvider(cfg *config.Config) contractAuth.PasskeyProvider {
+	return infraAuth.NewWebAuthnProvider(cfg, GetUserRepository(cfg), GetPasskeyRepository(cfg))
+}
+
+// GetTotpProvider returns the authenticator-app OtpProvider strategy, keyed by user id
+func GetTotpProvider(cfg *config.Config) contractAuth.OtpProvider {
+	return infraAuth.NewTotpProvider(cfg, GetTotpRepository(cfg))
+}
+
+// GetTotpRateLimitService limits authenticator code guesses per user
+func GetTotpRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
+	rateLimiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	return ratelimit.NewOTPRateLimitService(rateLimiter, ratelimit.OTPRateLimitConfig{
+		Policy:      "totp_verify",
+		KeyPrefix:   "totp_verify",
+		MaxAttempts: cfg.Totp.MaxVerifyAttempts,
+		Window:      cfg.Totp.MaxVerifyWindow * time.Second,
+	})
+}
+
+// GetQrLoginRateLimitService limits how many pending QR logins one IP address creates
+func GetQrLoginRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
+	rateLimiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	return ratelimit.NewOTPRateLimitService(rateLimiter, ratelimit.OTPRateLimitConfig{
+		Policy:      "qr_login",
+		KeyPrefix:   "qr_login_create",
+		MaxAttempts: cfg.QrLogin.MaxCreates,
+		Window:      cfg.QrLogin.Window * time.Second,
+	})
+}
+
+func GetClientRepository(cfg *config.Config) contractOAuthRepo.ClientRepository {
+	return infraOAuthRepo.NewClientPgRepo()
+}
+
+var (
+	idTokenSignerOnce sync.Once
+	idTokenSigner     contractOAuth.IdTokenSigner
+)
+
+// GetIdTokenSigner returns the process-wide signer, so a key generated at startup signs every
+// ID token and matches the published JWKS
+func GetIdTokenSigner(cfg *config.Config) contractOAuth.IdTokenSigner {
+	idTokenSignerOnce.Do(func() {
+		idTokenSigner = infraOAuth.NewRsaSigner(cfg)
+	})
+	return idTokenSigner
+}
+
+// GetDeviceAuthorizationRateLimitService limits how many device codes one IP address requests
+func GetDeviceAuthorizationRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
+	rateLimiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	return ratelimit.NewOTPRateLimitService(rateLimiter, ratelimit.OTPRateLimitConfig{
+		Policy:      "device_authorization",
+		KeyPrefix:   "device_authorization",
+		MaxAttempts: cfg.OAuth.MaxDeviceAuthorizations,
+		Window:      cfg.OAuth.DeviceAuthorizationWindow * time.Second,
+	})
+}
+
+// GetUserCodeRateLimitService limits user code guesses per user
+func GetUserCodeRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
+	rateLimiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	return ratelimit.NewOTPRateLimitService(rateLimiter, ratelimit.OTPRateLimitConfig{
+		Policy:      "user_code",
+		KeyPrefix:   "user_code",
+		MaxAttempts: cfg.OAuth.MaxUserCodeAttempts,
+		Window:      cfg.OAuth.UserCodeWindow * time.Second,
+	})
+}
+
 // GetOTPRateLimitService creates and returns OTP rate limiting service
 func GetOTPRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
 	redisClient := cache.GetRedis()
@@ -32,3 +194,36 @@ func GetOTPRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
 	config := ratelimit.DefaultOTPConfig()
 	return ratelimit.NewOTPRateLimitService(rateLimiter, config)
 }
+
+// GetVoiceRateLimitService limits OTP calls per number apart from SMS, since calls cost more
+func GetVoiceRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
+	rateLimiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	return ratelimit.NewOTPRateLimitService(rateLimiter, ratelimit.OTPRateLimitConfig{
+		Policy:      "otp_voice",
+		KeyPrefix:   "otp_voice",
+		MaxAttempts: cfg.Voice.MaxCalls,
+		Window:      cfg.Voice.Window * time.Second,
+	})
+}
+
+// GetPasswordLoginRateLimitService limits password login attempts per mobile number or email
+func GetPasswordLoginRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
+	rateLimiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	return ratelimit.NewOTPRateLimitService(rateLimiter, ratelimit.OTPRateLimitConfig{
+		Policy:      "password_login",
+		KeyPrefix:   "password_login",
+		MaxAttempts: cfg.Password.MaxLogins,
+		Window:      cfg.Password.LoginWindow * time.Second,
+	})
+}
+
+// GetPasswordLoginIpRateLimitService limits password login attempts per IP address, across identifiers
+func GetPasswordLoginIpRateLimitService(cfg *config.Config) *ratelimit.OTPRateLimitService {
+	rateLimiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	return ratelimit.NewOTPRateLimitService(rateLimiter, ratelimit.OTPRateLimitConfig{
+		Policy:      "password_login_ip",
+		KeyPrefix:   "password_login_ip",
+		MaxAttempts: cfg.Password.IpMaxLogins,
+		Window:      cfg.Password.LoginWindow * time.Second,
+	})
+}
@@ -0,0 +1,13 @@
+package dto
+
+type SubjectScore struct {
+	Subject string  `json:"subject"`
+	Score   float64 `json:"score"`
+}
+
+// AbuseScores lists the highest current score per subject for each heuristic
+type AbuseScores struct {
+	Ip     []SubjectScore `json:"ip"`
+	Block  []SubjectScore `json:"block"`
+	Prefix []SubjectScore `json:"prefix"`
+}
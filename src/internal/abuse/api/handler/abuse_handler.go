@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/di"
+	"github.com/alielmi98/golang-otp-auth/internal/abuse/usecase"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/gin-gonic/gin"
+)
+
+type AbuseHandler struct {
+	usecase *usecase.AbuseUsecase
+}
+
+func NewAbuseHandler(cfg *config.Config) *AbuseHandler {
+	return &AbuseHandler{usecase: di.GetAbuseUsecase(cfg)}
+}
+
+// GetScores godoc
+// @Summary Current abuse scores
+// @Description Highest current send-otp abuse scores per heuristic: client IP, block of consecutive numbers and prefix
+// @Tags Admin
+// @Produce  json
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 403 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/admin/abuse/scores [get]
+// @Security AuthBearer
+func (h *AbuseHandler) GetScores(c *gin.Context) {
+	scores, err := h.usecase.GetScores(c.Request.Context())
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(scores, true, helper.Success))
+}
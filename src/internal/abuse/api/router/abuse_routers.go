@@ -0,0 +1,12 @@
+package router
+
+import (
+	"github.com/alielmi98/golang-otp-auth/internal/abuse/api/handler"
+	"github.com/gin-gonic/gin"
+)
+
+func Abuse(router *gin.RouterGroup, handler *handler.AbuseHandler) {
+
+	router.GET("/scores", handler.GetScores)
+
+}
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/abuse/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/policy"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/go-redis/redis/v7"
+)
+
+// Heuristics; each scores 0 to 100 and the assessment takes the highest
+const (
+	SignalIp     = "ip"
+	SignalBlock  = "block"
+	SignalPrefix = "prefix"
+)
+
+// blockSuffixLength groups numbers that differ only in their last two digits, so a run of
+// +98912123450..99 is one block
+const blockSuffixLength = 2
+
+// AbuseUsecase keeps its counters in Redis, so every instance sees the same traffic.
+// Redis failures are logged and the attempt is allowed; rate limits still apply.
+type AbuseUsecase struct {
+	cfg         *config.Config
+	redisClient *redis.Client
+	logger      logging.Logger
+}
+
+func NewAbuseUsecase(cfg *config.Config) *AbuseUsecase {
+	return &AbuseUsecase{
+		cfg:         cfg,
+		redisClient: cache.GetRedis(),
+		logger:      logging.GetLogger(),
+	}
+}
+
+// AssessSend records the attempt and scores it: many recipients from one IP, many numbers from one
+// block of consecutive numbers, and prefixes whose codes are sent but rarely verified. Email
+// recipients are only scored by IP; the block and prefix heuristics target SMS pumping.
+func (u *AbuseUsecase) AssessSend(ctx context.Context, recipient string, channel string, clientIp string) (_ policy.AbuseAssessment, err error) {
+	ctx, span := tracing.Start(ctx, "AbuseUsecase.AssessSend")
+	defer tracing.End(span, &err)
+
+	assessment := policy.AbuseAssessment{Verdict: policy.VerdictAllow}
+	if !u.cfg.Abuse.Enabled {
+		return assessment, nil
+	}
+	window := u.window(time.Now())
+	phone := isPhone(channel)
+	block := blockOf(recipient)
+	prefix := u.prefixOf(recipient)
+
+	pipe := u.redisClient.WithContext(ctx).TxPipeline()
+	ipNumbers := countDistinct(pipe, window, func(bucket int64) string { return ipKey(clientIp, bucket) }, recipient)
+	var blockNumbers *redis.IntCmd
+	var prefixSent, prefixVerified *redis.SliceCmd
+	if phone {
+		blockNumbers = countDistinct(pipe, window, func(bucket int64) string { return blockKey(block, bucket) }, recipient)
+		prefixSent = pipe.MGet(prefixKey(prefix, "sent", window.current), prefixKey(prefix, "sent", window.previous))
+		prefixVerified = pipe.MGet(prefixKey(prefix, "verified", window.current), prefixKey(prefix, "verified", window.previous))
+	}
+	if _, err = pipe.Exec(); err != nil && err != redis.Nil {
+		u.logger.WithContext(ctx).Warn(constants.Redis, constants.Get, "abuse assessment skipped",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return assessment, nil
+	}
+
+	scores := map[string]float64{
+		SignalIp: thresholdScore(ipNumbers.Val(), u.cfg.Abuse.IpNumbersThreshold),
+	}
+	subjects := map[string]string{SignalIp: clientIp}
+	if phone {
+		sent, verified := sumCounters(prefixSent), sumCounters(prefixVerified)
+		scores[SignalBlock] = thresholdScore(blockNumbers.Val(), u.cfg.Abuse.BlockNumbersThreshold)
+		scores[SignalPrefix] = u.prefixScore(sent, verified)
+		subjects[SignalBlock], subjects[SignalPrefix] = block, prefix
+	}
+	u.publishScores(ctx, scores, subjects, window)
+
+	for signal, score := range scores {
+		if score > assessment.Score {
+			assessment.Score, assessment.Signal = score, signal
+		}
+	}
+	switch {
+	case assessment.Score >= u.cfg.Abuse.ThrottleScore:
+		assessment.Verdict = policy.VerdictThrottle
+	case assessment.Score >= u.cfg.Abuse.ChallengeScore:
+		assessment.Verdict = policy.VerdictChallenge
+	}
+	metrics.AbuseVerdicts.WithLabelValues(string(assessment.Verdict)).Inc()
+	return assessment, nil
+}
+
+// RecordSent counts a delivered code against the number's prefix
+func (u *AbuseUsecase) RecordSent(ctx context.Context, recipient string, channel string) {
+	u.incrementPrefix(ctx, recipient, channel, "sent")
+}
+
+// RecordVerified counts a successful verification against the number's prefix
+func (u *AbuseUsecase) RecordVerified(ctx context.Context, recipient string, channel string) {
+	u.incrementPrefix(ctx, recipient, channel, "verified")
+}
+
+// GetScores returns the highest current scores per heuristic for the admin API. A subject
+// scored in both the current and the previous window is listed once, with its higher score.
+func (u *AbuseUsecase) GetScores(ctx context.Context) (_ dto.AbuseScores, err error) {
+	ctx, span := tracing.Start(ctx, "AbuseUsecase.GetScores")
+	defer tracing.End(span, &err)
+
+	client := u.redisClient.WithContext(ctx)
+	window := u.window(time.Now())
+	top := u.cfg.Abuse.TopScores
+	result := dto.AbuseScores{}
+	for signal, target := range map[string]*[]dto.SubjectScore{
+		SignalIp: &result.Ip, SignalBlock: &result.Block, SignalPrefix: &result.Prefix,
+	} {
+		best := map[string]float64{}
+		for _, bucket := range []int64{window.current, window.previous} {
+			entries, err := client.ZRevRangeWithScores(scoresKey(signal, bucket), 0, top-1).Result()
+			if err != nil {
+				return dto.AbuseScores{}, err
+			}
+			for _, entry := range entries {
+				subject, _ := entry.Member.(string)
+				if score, ok := best[subject]; !ok || entry.Score > score {
+					best[subject] = entry.Score
+				}
+			}
+		}
+		*target = make([]dto.SubjectScore, 0, len(best))
+		for subject, score := range best {
+			*target = append(*target, dto.SubjectScore{Subject: subject, Score: score})
+		}
+		sort.Slice(*target, func(i, j int) bool {
+			a, b := (*target)[i], (*target)[j]
+			return a.Score > b.Score || a.Score == b.Score && a.Subject < b.Subject
+		})
+		if int64(len(*target)) > top {
+			*target = (*target)[:top]
+		}
+	}
+	return result, nil
+}
+
+func (u *AbuseUsecase) incrementPrefix(ctx context.Context, mobileNumber string, channel string, event string) {
+	if !u.cfg.Abuse.Enabled || !isPhone(channel) {
+		return
+	}
+	window := u.window(time.Now())
+	key := prefixKey(u.prefixOf(mobileNumber), event, window.current)
+	pipe := u.redisClient.WithContext(ctx).TxPipeline()
+	pipe.Incr(key)
+	pipe.ExpireAt(key, window.expiresAt)
+	if _, err := pipe.Exec(); err != nil {
+		u.logger.WithContext(ctx).Warn(constants.Redis, constants.Set, "abuse counter not updated",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+}
+
+// publishScores keeps one sorted set per heuristic and window for admins; subjects that score
+// zero are removed
+func (u *AbuseUsecase) publishScores(ctx context.Context, scores map[string]float64, subjects map[string]string, window abuseWindow) {
+	pipe := u.redisClient.WithContext(ctx).Pipeline()
+	for signal, score := range scores {
+		key := scoresKey(signal, window.current)
+		if score > 0 {
+			pipe.ZAdd(key, &redis.Z{Score: score, Member: subjects[signal]})
+			pipe.ExpireAt(key, window.expiresAt)
+		} else {
+			pipe.ZRem(key, subjects[signal])
+			pipe.ZRem(scoresKey(signal, window.previous), subjects[signal])
+		}
+	}
+	if _, err := pipe.Exec(); err != nil {
+		u.logger.WithContext(ctx).Warn(constants.Redis, constants.Set, "abuse scores not published",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+}
+
+// prefixScore grows from 0 at the minimum verify ratio to 100 when nothing is verified
+func (u *AbuseUsecase) prefixScore(sent, verified int64) float64 {
+	minRatio := u.cfg.Abuse.PrefixMinVerifyRatio
+	if sent < int64(u.cfg.Abuse.PrefixMinSends) || minRatio <= 0 {
+		return 0
+	}
+	ratio := float64(verified) / float64(sent)
+	if ratio >= minRatio {
+		return 0
+	}
+	return math.Round(100 * (1 - ratio/minRatio))
+}
+
+func (u *AbuseUsecase) prefixOf(mobileNumber string) string {
+	if len(mobileNumber) <= u.cfg.Abuse.PrefixLength {
+		return mobileNumber
+	}
+	return mobileNumber[:u.cfg.Abuse.PrefixLength]
+}
+
+// abuseWindow numbers fixed windows of abuse.window seconds. Signals read the current and the
+// previous window, so an attempt ages out between one and two windows after it was recorded.
+type abuseWindow struct {
+	current   int64
+	previous  int64
+	expiresAt time.Time
+}
+
+func (u *AbuseUsecase) window(now time.Time) abuseWindow {
+	length := int64(u.cfg.Abuse.Window)
+	if length <= 0 {
+		length = 1
+	}
+	current := now.Unix() / length
+	return abuseWindow{
+		current:  current,
+		previous: current - 1,
+		// Kept while it is still the previous window
+		expiresAt: time.Unix((current+2)*length, 0),
+	}
+}
+
+// countDistinct adds member to the current window and counts distinct members of both windows
+func countDistinct(pipe redis.Pipeliner, window abuseWindow, key func(bucket int64) string, member string) *redis.IntCmd {
+	current := key(window.current)
+	pipe.PFAdd(current, member)
+	pipe.ExpireAt(current, window.expiresAt)
+	return pipe.PFCount(current, key(window.previous))
+}
+
+// sumCounters adds up the counters an MGET returned; missing ones count as zero
+func sumCounters(cmd *redis.SliceCmd) int64 {
+	var total int64
+	for _, value := range cmd.Val() {
+		if s, ok := value.(string); ok {
+			n, _ := strconv.ParseInt(s, 10, 64)
+			total += n
+		}
+	}
+	return total
+}
+
+// thresholdScore is linear in count and reaches 100 at threshold
+func thresholdScore(count int64, threshold int) float64 {
+	if threshold <= 0 {
+		return 0
+	}
+	return math.Min(100, math.Round(100*float64(count)/float64(threshold)))
+}
+
+// isPhone reports whether codes on channel go to a phone number, by SMS or by voice call
+func isPhone(channel string) bool {
+	return channel != models.OtpChannelEmail
+}
+
+func blockOf(mobileNumber string) string {
+	if len(mobileNumber) <= blockSuffixLength {
+		return mobileNumber
+	}
+	return mobileNumber[:len(mobileNumber)-blockSuffixLength]
+}
+
+func ipKey(ip string, bucket int64) string {
+	return fmt.Sprintf("abuse:ip:%s:%d", ip, bucket)
+}
+
+func blockKey(block string, bucket int64) string {
+	return fmt.Sprintf("abuse:block:%s:%d", block, bucket)
+}
+
+func prefixKey(prefix string, event string, bucket int64) string {
+	return fmt.Sprintf("abuse:prefix:%s:%s:%d", prefix, event, bucket)
+}
+
+func scoresKey(signal string, bucket int64) string {
+	return fmt.Sprintf("abuse:scores:%s:%d", signal, bucket)
+}
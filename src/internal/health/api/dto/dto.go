@@ -0,0 +1,14 @@
+package dto
+
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Healthy   bool   `json:"healthy"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status       string             `json:"status"`
+	ShuttingDown bool               `json:"shutting_down"`
+	Dependencies []DependencyStatus `json:"dependencies,omitempty"`
+}
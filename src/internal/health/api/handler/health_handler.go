@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/internal/health/usecase"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	usecase *usecase.HealthUsecase
+}
+
+func NewHealthHandler(cfg *config.Config) *HealthHandler {
+	return &HealthHandler{usecase: usecase.NewHealthUsecase(cfg)}
+}
+
+// SetShuttingDown makes readiness fail so the load balancer stops routing new traffic
+func (h *HealthHandler) SetShuttingDown() {
+	h.usecase.SetShuttingDown()
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Reports whether the process is alive
+// @Tags Health
+// @Produce  json
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(h.usecase.Liveness(), true, helper.Success))
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Pings Postgres and Redis and reports per-dependency status and latency
+// @Tags Health
+// @Produce  json
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 503 {object} helper.BaseHttpResponse "Not ready"
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report, ready := h.usecase.Readiness(c.Request.Context())
+	if !ready {
+		helper.AbortWithResponse(c, http.StatusServiceUnavailable,
+			helper.GenerateBaseResponse(report, false, helper.ServiceUnavailableError))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(report, true, helper.Success))
+}
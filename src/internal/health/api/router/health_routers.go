@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/alielmi98/golang-otp-auth/internal/health/api/handler"
+	"github.com/gin-gonic/gin"
+)
+
+func Health(router gin.IRoutes, handler *handler.HealthHandler) {
+
+	router.GET("/healthz", handler.Liveness)
+	router.GET("/readyz", handler.Readiness)
+
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/health/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+)
+
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusShuttingDown = "shutting_down"
+
+	unavailable = "unavailable"
+)
+
+// DependencyCheck pings a single backing service and returns an error if it is unreachable
+type DependencyCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthUsecase struct {
+	cfg          *config.Config
+	logger       logging.Logger
+	checks       []DependencyCheck
+	shuttingDown atomic.Bool
+}
+
+func NewHealthUsecase(cfg *config.Config) *HealthUsecase {
+	return &HealthUsecase{
+		cfg:    cfg,
+		logger: logging.GetLogger(),
+		checks: []DependencyCheck{
+			{Name: "postgres", Check: pingPostgres},
+			{Name: "redis", Check: pingRedis},
+		},
+	}
+}
+
+// SetShuttingDown marks the process as draining so readiness starts failing
+func (u *HealthUsecase) SetShuttingDown() {
+	u.shuttingDown.Store(true)
+}
+
+func (u *HealthUsecase) IsShuttingDown() bool {
+	return u.shuttingDown.Load()
+}
+
+// Liveness reports whether the process is running; it never touches dependencies
+func (u *HealthUsecase) Liveness() dto.HealthReport {
+	return dto.HealthReport{Status: StatusUp, ShuttingDown: u.IsShuttingDown()}
+}
+
+// Readiness pings every dependency concurrently and reports whether traffic should be routed here
+func (u *HealthUsecase) Readiness(ctx context.Context) (dto.HealthReport, bool) {
+	results := make([]dto.DependencyStatus, len(u.checks))
+	var wg sync.WaitGroup
+	for i, check := range u.checks {
+		wg.Add(1)
+		go func(i int, check DependencyCheck) {
+			defer wg.Done()
+			results[i] = u.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, r := range results {
+		if !r.Healthy {
+			ready = false
+		}
+	}
+
+	report := dto.HealthReport{Status: StatusUp, Dependencies: results}
+	if !ready {
+		report.Status = StatusDown
+	}
+	if u.IsShuttingDown() {
+		ready = false
+		report.Status = StatusShuttingDown
+		report.ShuttingDown = true
+	}
+	return report, ready
+}
+
+func (u *HealthUsecase) runCheck(ctx context.Context, check DependencyCheck) dto.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, u.cfg.Health.CheckTimeout*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	status := dto.DependencyStatus{
+		Name:      check.Name,
+		Healthy:   err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		// The probe is unauthenticated, so the cause stays in the log
+		u.logger.Error(constants.General, constants.HealthCheck, check.Name+": "+err.Error(), nil)
+		status.Error = unavailable
+	}
+	return status
+}
+
+func pingPostgres(ctx context.Context) error {
+	database := db.GetDb()
+	if database == nil {
+		return errors.New("postgres not initialized")
+	}
+	sqlDb, err := database.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDb.PingContext(ctx)
+}
+
+func pingRedis(ctx context.Context) error {
+	client := cache.GetRedis()
+	if client == nil {
+		return errors.New("redis not initialized")
+	}
+	return client.WithContext(ctx).Ping().Err()
+}
@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// bodyLogWriter tees the response body into a bounded buffer
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body  *bytes.Buffer
+	limit int
+}
+
+func (w bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString is used by gin's string and JSON renderers, so it must be captured too
+func (w bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w bodyLogWriter) capture(b []byte) {
+	if remaining := w.limit - w.body.Len(); remaining > 0 {
+		if len(b) > remaining {
+			w.body.Write(b[:remaining])
+		} else {
+			w.body.Write(b)
+		}
+	}
+}
+
+// readCloser reads from Reader and closes the original request body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// AccessLog writes one entry per request. Paths are logged as route templates and bodies,
+// when captured at all, go through the configured redaction rules so OTP codes, tokens and
+// phone numbers never reach the log pipeline.
+func AccessLog(cfg *config.Config) gin.HandlerFunc {
+	logger := logging.GetLogger()
+	redactor := logging.NewRedactor(cfg.AccessLog.Redaction)
+	skip := make(map[string]bool, len(cfg.AccessLog.SkipPaths))
+	for _, p := range cfg.AccessLog.SkipPaths {
+		skip[p] = true
+	}
+
+	return func(c *gin.Context) {
+		if !cfg.AccessLog.Enabled || skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var reqBody []byte
+		var blw *bodyLogWriter
+		if cfg.AccessLog.CaptureBody {
+			if c.Request.Body != nil {
+				// Only the logged prefix is buffered; the handler reads it back followed by the rest
+				reqBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(cfg.AccessLog.MaxBodySize)+1))
+				c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(reqBody), c.Request.Body), Closer: c.Request.Body}
+			}
+			blw = &bodyLogWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, limit: cfg.AccessLog.MaxBodySize}
+			c.Writer = blw
+		}
+
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		keys := map[constants.ExtraKey]interface{}{
+			constants.ClientIp:   c.ClientIP(),
+			constants.Method:     c.Request.Method,
+			constants.StatusCode: c.Writer.Status(),
+			constants.Path:       path,
+			constants.Latency:    time.Since(start).String(),
+			constants.BodySize:   c.Writer.Size(),
+		}
+		if query := redactor.RedactQuery(c.Request.URL.Query()); query != "" {
+			keys[constants.Query] = query
+		}
+		if len(c.Errors) > 0 {
+			keys[constants.ErrorMessage] = c.Errors.ByType(gin.ErrorTypePrivate).String()
+		}
+		if blw != nil {
+			if len(reqBody) > cfg.AccessLog.MaxBodySize {
+				reqBody = reqBody[:cfg.AccessLog.MaxBodySize]
+			}
+			keys[constants.RequestBody] = redactor.RedactJSON(reqBody)
+			keys[constants.ResponseBody] = redactor.RedactJSON(blw.body.Bytes())
+		}
+
+		logger.WithContext(c.Request.Context()).Info(constants.RequestResponse, constants.Api, "request handled", keys)
+	}
+}
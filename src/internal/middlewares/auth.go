@@ -1,7 +1,7 @@
 package middlewares
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -21,27 +21,31 @@ func Authentication(cfg *config.Config, tokenProvider auth.TokenProvider) gin.Ha
 		auth := c.GetHeader(constants.AuthorizationHeaderKey)
 		token := strings.Split(auth, " ")
 		if auth == "" || len(token) < 2 {
-			err = &service_errors.ServiceError{EndUserMessage: service_errors.TokenRequired}
+			err = service_errors.New(service_errors.CodeTokenRequired)
 		} else {
-			claimMap, err = tokenProvider.GetClaims(token[1])
+			claimMap, err = tokenProvider.GetClaims(c.Request.Context(), token[1])
 			if err != nil {
-				switch err.(*jwt.ValidationError).Errors {
-				case jwt.ValidationErrorExpired:
-					err = &service_errors.ServiceError{EndUserMessage: service_errors.TokenExpired}
-				default:
-					err = &service_errors.ServiceError{EndUserMessage: service_errors.TokenInvalid}
+				var validationErr *jwt.ValidationError
+				if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+					err = service_errors.New(service_errors.CodeTokenExpired)
+				} else {
+					err = service_errors.New(service_errors.CodeTokenInvalid)
 				}
+			} else if clientId, _ := claimMap[constants.ClientIdKey].(string); clientId != "" {
+				// Tokens issued to an OAuth client are for that client's own use, not the first-party API
+				err = service_errors.New(service_errors.CodeTokenInvalid)
 			}
 		}
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, helper.GenerateBaseResponseWithError(
-				nil, false, helper.AuthError, err,
+			helper.AbortWithResponse(c, http.StatusUnauthorized, helper.GenerateBaseResponseWithError(
+				nil, false, helper.TranslateErrorToResultCode(err), err,
 			))
 			return
 		}
 
 		c.Set(constants.UserIdKey, claimMap[constants.UserIdKey])
 		c.Set(constants.MobileNumberKey, claimMap[constants.MobileNumberKey])
+		c.Set(constants.EmailKey, claimMap[constants.EmailKey])
 		c.Set(constants.RolesKey, claimMap[constants.RolesKey])
 		c.Set(constants.ExpireTimeKey, claimMap[constants.ExpireTimeKey])
 
@@ -51,19 +55,24 @@ func Authentication(cfg *config.Config, tokenProvider auth.TokenProvider) gin.Ha
 func Authorization(validRoles []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if len(c.Keys) == 0 {
-			c.AbortWithStatusJSON(http.StatusForbidden, helper.GenerateBaseResponse(nil, false, helper.ForbiddenError))
+			helper.AbortWithResponse(c, http.StatusForbidden, helper.GenerateBaseResponse(nil, false, helper.ForbiddenError))
 			return
 		}
 		rolesVal := c.Keys[constants.RolesKey]
-		fmt.Println(rolesVal)
 		if rolesVal == nil {
-			c.AbortWithStatusJSON(http.StatusForbidden, helper.GenerateBaseResponse(nil, false, helper.ForbiddenError))
+			helper.AbortWithResponse(c, http.StatusForbidden, helper.GenerateBaseResponse(nil, false, helper.ForbiddenError))
+			return
+		}
+		roles, ok := rolesVal.([]interface{})
+		if !ok {
+			helper.AbortWithResponse(c, http.StatusForbidden, helper.GenerateBaseResponse(nil, false, helper.ForbiddenError))
 			return
 		}
-		roles := rolesVal.([]interface{})
 		val := map[string]int{}
 		for _, item := range roles {
-			val[item.(string)] = 0
+			if role, ok := item.(string); ok {
+				val[role] = 0
+			}
 		}
 
 		for _, item := range validRoles {
@@ -72,6 +81,6 @@ func Authorization(validRoles []string) gin.HandlerFunc {
 				return
 			}
 		}
-		c.AbortWithStatusJSON(http.StatusForbidden, helper.GenerateBaseResponse(nil, false, helper.ForbiddenError))
+		helper.AbortWithResponse(c, http.StatusForbidden, helper.GenerateBaseResponse(nil, false, helper.ForbiddenError))
 	}
 }
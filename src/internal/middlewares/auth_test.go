@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/entity"
+	"github.com/alielmi98/golang-otp-auth/internal/user/infra/auth"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Access tokens issued to an OAuth client must not open the first-party API
+func TestAuthenticationRejectsClientTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "testSecret"
+	cfg.JWT.RefreshSecret = "testRefreshSecret"
+	cfg.JWT.AccessTokenExpireDuration = 15
+	cfg.JWT.RefreshTokenExpireDuration = 60
+	provider := auth.NewJwtProvider(cfg)
+
+	r := gin.New()
+	r.GET("/me", Authentication(cfg, provider), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name     string
+		clientId string
+		status   int
+	}{
+		{"first-party token", "", http.StatusOK},
+		{"client token", "cli", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := provider.GenerateToken(context.Background(), &entity.TokenPayload{
+				UserId: 7, MobileNumber: "+989121234567", Roles: []string{"default"}, ClientId: tt.clientId,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("got status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale stores the request's localizer in its context: the language comes from Accept-Language
+// and the timezone from X-Timezone, each falling back to the configured default
+func Locale(cfg *config.Config) gin.HandlerFunc {
+	defaultLocale := cfg.I18n.DefaultLocale
+	if defaultLocale == "" {
+		defaultLocale = i18n.LocaleEn
+	}
+	defaultLocation := i18n.LoadLocation(cfg.I18n.DefaultTimezone, time.UTC)
+
+	return func(c *gin.Context) {
+		localizer := i18n.Localizer{
+			Locale:   i18n.MatchLocale(c.GetHeader("Accept-Language"), defaultLocale),
+			Location: i18n.LoadLocation(c.GetHeader(i18n.TimezoneHeader), defaultLocation),
+		}
+		c.Header("Content-Language", localizer.Locale)
+		c.Header("Vary", "Accept-Language")
+		c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), localizer))
+		c.Next()
+	}
+}
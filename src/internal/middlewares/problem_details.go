@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/gin-gonic/gin"
+)
+
+const problemFormat = "problem"
+
+// ProblemDetails selects the error format for the request: problem+json when the server is
+// configured for it or the client lists application/problem+json in Accept, the envelope otherwise
+func ProblemDetails(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wants := cfg.Server.ErrorFormat == problemFormat ||
+			strings.Contains(c.GetHeader("Accept"), helper.ProblemJsonContentType)
+		c.Set(constants.ProblemDetailsKey, wants)
+		c.Set(constants.ProblemTypeBaseUrlKey, cfg.Server.ProblemTypeBaseUrl)
+		c.Next()
+	}
+}
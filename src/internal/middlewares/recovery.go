@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+var errRecovered = errors.New("internal server error")
+
+// Recovery turns a panic into a 500 with the standard envelope. The panic value and stack are
+// only logged, never returned, since they can contain internal state.
+func Recovery() gin.HandlerFunc {
+	logger := logging.GetLogger()
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			metrics.PanicsRecovered.Inc()
+			logger.WithContext(c.Request.Context()).Error(constants.General, constants.Recovery, fmt.Sprint(rec),
+				map[constants.ExtraKey]interface{}{
+					constants.Method: c.Request.Method,
+					constants.Path:   c.FullPath(),
+					constants.Stack:  string(debug.Stack()),
+				})
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			helper.AbortWithResponse(c, http.StatusInternalServerError,
+				helper.GenerateBaseResponseWithError(nil, false, helper.CustomRecovery, errRecovered))
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestId accepts the caller's X-Request-ID or generates one, echoes it in the response
+// and stores it in the request context so every layer below can log and forward it.
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestid.Resolve(c.GetHeader(requestid.HeaderKey))
+
+		c.Set(constants.RequestIdKey, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.HeaderKey, id)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,157 @@
+package dto
+
+import "time"
+
+// DeviceAuthorizationRequest is sent form-encoded (RFC 8628 section 3.1). Confidential clients
+// send ClientSecret here or by HTTP Basic authentication.
+type DeviceAuthorizationRequest struct {
+	ClientId     string `form:"client_id" json:"client_id" binding:"max=64"`
+	ClientSecret string `form:"client_secret" json:"client_secret" binding:"max=128"`
+	Scope        string `form:"scope" json:"scope" binding:"max=256"`
+}
+
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// TokenRequest is sent form-encoded. Code, RedirectUri and CodeVerifier go with the
+// authorization_code grant, DeviceCode with the device_code grant and RefreshToken with the
+// refresh_token grant. Confidential clients send ClientSecret here or by HTTP Basic authentication.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" binding:"required,max=128"`
+	ClientId     string `form:"client_id" json:"client_id" binding:"max=64"`
+	ClientSecret string `form:"client_secret" json:"client_secret" binding:"max=128"`
+	Code         string `form:"code" json:"code" binding:"max=128"`
+	RedirectUri  string `form:"redirect_uri" json:"redirect_uri" binding:"max=2048"`
+	CodeVerifier string `form:"code_verifier" json:"code_verifier" binding:"max=128"`
+	DeviceCode   string `form:"device_code" json:"device_code" binding:"max=128"`
+	RefreshToken string `form:"refresh_token" json:"refresh_token" binding:"max=4096"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// IdToken is set when the authorization asked for the openid scope
+	IdToken string `json:"id_token,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+}
+
+// Error is the OAuth 2.0 error response (RFC 6749 section 5.2)
+type Error struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// DeviceInfo names the client a user code belongs to, for the user to check before approving
+type DeviceInfo struct {
+	ClientId   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Scope      string    `json:"scope,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type UserCodeRequest struct {
+	UserCode string `json:"user_code" binding:"required,max=16"`
+}
+
+// AuthorizationRequest is the query of the authorization endpoint (OpenID Connect Core section
+// 3.1.2.1). Only the code flow with S256 PKCE is supported.
+type AuthorizationRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientId            string `form:"client_id"`
+	RedirectUri         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+	Prompt              string `form:"prompt"`
+}
+
+// AuthorizationPage is what the hosted login page needs: the pending request to complete after
+// logging in, and where Cancel leads
+type AuthorizationPage struct {
+	RequestId  string
+	ClientName string
+	CancelUri  string
+}
+
+// CompleteAuthorizationRequest names the pending request the logged-in user completes
+type CompleteAuthorizationRequest struct {
+	RequestId string `json:"request_id" binding:"required,max=64"`
+}
+
+// AuthorizationRedirect is where the hosted page sends the browser, carrying the code and state
+type AuthorizationRedirect struct {
+	RedirectUri string `json:"redirect_uri"`
+}
+
+// UserInfo holds the standard claims (OpenID Connect Core section 5.1) the service knows
+type UserInfo struct {
+	Sub                 string `json:"sub"`
+	PhoneNumber         string `json:"phone_number,omitempty"`
+	PhoneNumberVerified *bool  `json:"phone_number_verified,omitempty"`
+	Email               string `json:"email,omitempty"`
+	EmailVerified       *bool  `json:"email_verified,omitempty"`
+}
+
+// ProviderMetadata is the discovery document (OpenID Connect Discovery section 3)
+type ProviderMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksUri                           string   `json:"jwks_uri"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	ResponseModesSupported            []string `json:"response_modes_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	AuthorizationResponseIssParameter bool     `json:"authorization_response_iss_parameter_supported"`
+}
+
+// Jwk is an RSA public key (RFC 7517)
+type Jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type Jwks struct {
+	Keys []Jwk `json:"keys"`
+}
+
+// CreateClientRequest registers a client. A confidential client gets a secret, shown only in
+// the response to this request.
+type CreateClientRequest struct {
+	ClientId     string   `json:"client_id" binding:"required,min=3,max=64"`
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectUris []string `json:"redirect_uris" binding:"max=10,dive,required,max=2048"`
+	Confidential bool     `json:"confidential"`
+}
+
+type Client struct {
+	Id           int       `json:"id"`
+	ClientId     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectUris []string  `json:"redirect_uris"`
+	Confidential bool      `json:"confidential"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	CreatedBy    int       `json:"created_by"`
+}
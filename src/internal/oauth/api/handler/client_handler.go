@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alielmi98/golang-otp-auth/di"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/usecase"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+type ClientHandler struct {
+	usecase *usecase.ClientUsecase
+}
+
+func NewClientHandler(cfg *config.Config) *ClientHandler {
+	return &ClientHandler{usecase: usecase.NewClientUsecase(di.GetClientRepository(cfg))}
+}
+
+// CreateClient godoc
+// @Summary Register an OAuth client
+// @Description Register an application for OpenID Connect sign-in or the device grant. A confidential client's secret is only returned here.
+// @Tags Admin
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.CreateClientRequest true "CreateClientRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.Client} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 403 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/admin/oauth-clients [post]
+// @Security AuthBearer
+func (h *ClientHandler) CreateClient(c *gin.Context) {
+	req := new(dto.CreateClientRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	userId, _ := c.Value(constants.UserIdKey).(float64)
+	client, err := h.usecase.CreateClient(c.Request.Context(), req, int(userId))
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(client, true, helper.Success))
+}
+
+// GetClients godoc
+// @Summary List OAuth clients
+// @Description List registered clients; secrets are never shown again
+// @Tags Admin
+// @Produce  json
+// @Success 200 {object} helper.BaseHttpResponse{result=[]dto.Client} "Success"
+// @Failure 403 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/admin/oauth-clients [get]
+// @Security AuthBearer
+func (h *ClientHandler) GetClients(c *gin.Context) {
+	clients, err := h.usecase.GetClients(c.Request.Context())
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(clients, true, helper.Success))
+}
+
+// DeleteClient godoc
+// @Summary Delete an OAuth client
+// @Description Delete a client; its pending codes stop working, tokens already issued stay valid until they expire
+// @Tags Admin
+// @Produce  json
+// @Param id path int true "Client record id"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 404 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/admin/oauth-clients/{id} [delete]
+// @Security AuthBearer
+func (h *ClientHandler) DeleteClient(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.usecase.DeleteClient(c.Request.Context(), id)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/alielmi98/golang-otp-auth/di"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/usecase"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	deviceUsecase        *usecase.DeviceUsecase
+	authorizationUsecase *usecase.AuthorizationUsecase
+	tokenUsecase         *usecase.TokenUsecase
+}
+
+func NewOAuthHandler(cfg *config.Config) *OAuthHandler {
+	clientRepo := di.GetClientRepository(cfg)
+	userRepo := di.GetUserRepository(cfg)
+	tokenProvider := di.GetTokenProvider(cfg)
+	deviceUsecase := usecase.NewDeviceUsecase(cfg, clientRepo, userRepo, tokenProvider,
+		di.GetDeviceAuthorizationRateLimitService(cfg), di.GetUserCodeRateLimitService(cfg))
+	authorizationUsecase := usecase.NewAuthorizationUsecase(cfg, clientRepo, userRepo, tokenProvider, di.GetIdTokenSigner(cfg))
+	return &OAuthHandler{
+		deviceUsecase:        deviceUsecase,
+		authorizationUsecase: authorizationUsecase,
+		tokenUsecase:         usecase.NewTokenUsecase(clientRepo, tokenProvider, deviceUsecase, authorizationUsecase),
+	}
+}
+
+// DeviceAuthorization godoc
+// @Summary Start a device authorization
+// @Description RFC 8628 device authorization request. Show user_code and verification_uri, then poll the token endpoint with device_code every interval seconds.
+// @Tags OAuth
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param client_id formData string false "Registered client id, unless sent by HTTP Basic authentication"
+// @Param client_secret formData string false "Secret of a confidential client, unless sent by HTTP Basic authentication"
+// @Param scope formData string false "Requested scope"
+// @Success 200 {object} dto.DeviceAuthorizationResponse "Success"
+// @Failure 400 {object} dto.Error "Failed"
+// @Failure 401 {object} dto.Error "Failed"
+// @Router /v1/oauth/device_authorization [post]
+func (h *OAuthHandler) DeviceAuthorization(c *gin.Context) {
+	req := new(dto.DeviceAuthorizationRequest)
+	err := c.ShouldBind(req)
+	if err != nil {
+		writeError(c, usecase.ErrorInvalidRequest, http.StatusBadRequest, err.Error())
+		return
+	}
+	clientCredentials(c, &req.ClientId, &req.ClientSecret)
+	authorization, err := h.deviceUsecase.Authorize(c.Request.Context(), req.ClientId, req.ClientSecret, req.Scope, c.ClientIP())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, authorization)
+}
+
+// Token godoc
+// @Summary Get tokens
+// @Description OAuth 2.0 token endpoint. authorization_code exchanges a code from the authorization endpoint, with its PKCE code_verifier, for tokens and an ID token. urn:ietf:params:oauth:grant-type:device_code polls a device authorization and answers authorization_pending, slow_down, access_denied or expired_token until it ends. refresh_token renews tokens.
+// @Tags OAuth
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param grant_type formData string true "authorization_code, urn:ietf:params:oauth:grant-type:device_code or refresh_token"
+// @Param client_id formData string false "Registered client id, unless sent by HTTP Basic authentication"
+// @Param client_secret formData string false "Secret of a confidential client, unless sent by HTTP Basic authentication"
+// @Param code formData string false "Authorization code, for the authorization_code grant"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request, for the authorization_code grant"
+// @Param code_verifier formData string false "PKCE code verifier, for the authorization_code grant"
+// @Param device_code formData string false "Device code, for the device_code grant"
+// @Param refresh_token formData string false "Refresh token, for the refresh_token grant"
+// @Success 200 {object} dto.TokenResponse "Success"
+// @Failure 400 {object} dto.Error "Failed"
+// @Failure 401 {object} dto.Error "Failed"
+// @Router /v1/oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	req := new(dto.TokenRequest)
+	err := c.ShouldBind(req)
+	if err != nil {
+		writeError(c, usecase.ErrorInvalidRequest, http.StatusBadRequest, err.Error())
+		return
+	}
+	clientCredentials(c, &req.ClientId, &req.ClientSecret)
+	token, err := h.tokenUsecase.Token(c.Request.Context(), *req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, token)
+}
+
+// GetDevice godoc
+// @Summary Describe a device by its user code
+// @Description Return the client a user code belongs to, to show before approving
+// @Tags OAuth
+// @Produce  json
+// @Security AuthBearer
+// @Param user_code query string true "User code shown on the device"
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.DeviceInfo} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Failure 429 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/oauth/device [get]
+func (h *OAuthHandler) GetDevice(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	info, err := h.deviceUsecase.Get(c.Request.Context(), userId, c.Query("user_code"))
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(info, true, helper.Success))
+}
+
+// ApproveDevice godoc
+// @Summary Approve a device
+// @Description Let the device showing the user code log in as the current user
+// @Tags OAuth
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.UserCodeRequest true "UserCodeRequest"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Failure 429 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/oauth/device/approve [post]
+func (h *OAuthHandler) ApproveDevice(c *gin.Context) {
+	h.answerDevice(c, true)
+}
+
+// DenyDevice godoc
+// @Summary Deny a device
+// @Description Refuse the device showing the user code
+// @Tags OAuth
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.UserCodeRequest true "UserCodeRequest"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Failure 429 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/oauth/device/deny [post]
+func (h *OAuthHandler) DenyDevice(c *gin.Context) {
+	h.answerDevice(c, false)
+}
+
+func (h *OAuthHandler) answerDevice(c *gin.Context, approve bool) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.UserCodeRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.deviceUsecase.Answer(c.Request.Context(), userId, req.UserCode, approve)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
+
+// abortWithError answers in the OAuth error format. Rate limits and other service errors keep
+// their status and carry their message as error_description.
+func abortWithError(c *gin.Context, err error) {
+	var oauthErr *usecase.Error
+	if errors.As(err, &oauthErr) {
+		if _, _, basic := c.Request.BasicAuth(); basic && oauthErr.Code == usecase.ErrorInvalidClient {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		writeError(c, oauthErr.Code, oauthErr.StatusCode(), oauthErr.Description)
+		return
+	}
+	status := helper.TranslateErrorToStatusCode(err)
+	_, message := helper.TranslateErrorToCodeAndMessage(err)
+	code := usecase.ErrorInvalidRequest
+	if status >= http.StatusInternalServerError {
+		code = usecase.ErrorServerError
+	}
+	writeError(c, code, status, message)
+}
+
+// clientCredentials takes the client id and secret from HTTP Basic authentication
+// (client_secret_basic) when the client sent them that way, rather than in the form
+func clientCredentials(c *gin.Context, clientId *string, clientSecret *string) {
+	id, secret, ok := c.Request.BasicAuth()
+	if !ok {
+		return
+	}
+	// Both are form-encoded before they are put in the header (RFC 6749 section 2.3.1)
+	if unescaped, err := url.QueryUnescape(id); err == nil {
+		*clientId = unescaped
+	}
+	if unescaped, err := url.QueryUnescape(secret); err == nil {
+		*clientSecret = unescaped
+	}
+}
+
+func writeError(c *gin.Context, code string, status int, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.AbortWithStatusJSON(status, dto.Error{Error: code, ErrorDescription: description})
+}
+
+func currentUserId(c *gin.Context) (int, bool) {
+	userId, ok := c.Value(constants.UserIdKey).(float64)
+	if !ok {
+		err := service_errors.New(service_errors.CodeUserIdNotFound)
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err), helper.GenerateBaseResponseFromError(err))
+		return 0, false
+	}
+	return int(userId), true
+}
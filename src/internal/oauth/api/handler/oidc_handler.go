@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/usecase"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var pages = template.Must(template.ParseFS(templateFiles, "templates/*.html"))
+
+// Authorize godoc
+// @Summary Start an OpenID Connect sign-in
+// @Description Authorization endpoint of the code flow with PKCE. A valid request gets the hosted OTP login page, which sends the browser back to redirect_uri with code, state and iss. Request errors go back to redirect_uri once it is known to be registered for the client; before that they are shown on an error page.
+// @Tags OAuth
+// @Produce  html
+// @Param response_type query string true "code"
+// @Param client_id query string true "Registered client id"
+// @Param redirect_uri query string true "One of the client's registered redirect URIs"
+// @Param scope query string false "Space-separated: openid, phone, email"
+// @Param state query string false "Returned unchanged to the client"
+// @Param nonce query string false "Copied into the ID token"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "S256"
+// @Param prompt query string false "none is answered with login_required"
+// @Success 200 {string} string "Login page"
+// @Success 302 {string} string "Back to redirect_uri with an error"
+// @Failure 400 {string} string "Error page"
+// @Router /v1/oauth/authorize [get]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	req := new(dto.AuthorizationRequest)
+	err := c.ShouldBind(req)
+	if err != nil {
+		renderPage(c, http.StatusBadRequest, "error", gin.H{"Code": usecase.ErrorInvalidRequest, "Description": err.Error()})
+		return
+	}
+	page, err := h.authorizationUsecase.Begin(c.Request.Context(), *req)
+	if err != nil {
+		renderAuthorizationError(c, err)
+		return
+	}
+	renderPage(c, http.StatusOK, "authorize", gin.H{
+		"RequestId":  page.RequestId,
+		"ClientName": page.ClientName,
+		// The redirect URI was registered and validated, so custom app schemes may be linked to
+		"CancelUri": template.URL(page.CancelUri),
+	})
+}
+
+// CompleteAuthorization godoc
+// @Summary Complete an OpenID Connect sign-in
+// @Description Called by the hosted login page once the user logged in. Issues the authorization code and returns the URI to send the browser to.
+// @Tags OAuth
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.CompleteAuthorizationRequest true "CompleteAuthorizationRequest"
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.AuthorizationRedirect} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/oauth/authorize/complete [post]
+func (h *OAuthHandler) CompleteAuthorization(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.CompleteAuthorizationRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	redirect, err := h.authorizationUsecase.Complete(c.Request.Context(), userId, req.RequestId)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(redirect, true, helper.Success))
+}
+
+// UserInfo godoc
+// @Summary Get the signed-in user's claims
+// @Description OpenID Connect userinfo endpoint. Returns the claims of the user the access token was issued to.
+// @Tags OAuth
+// @Produce  json
+// @Security AuthBearer
+// @Success 200 {object} dto.UserInfo "Success"
+// @Failure 401 {object} dto.Error "Failed"
+// @Router /v1/oauth/userinfo [get]
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	accessToken, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || accessToken == "" {
+		c.Header("WWW-Authenticate", `Bearer realm="oauth"`)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	info, err := h.authorizationUsecase.UserInfo(c.Request.Context(), accessToken)
+	if err != nil {
+		var oauthErr *usecase.Error
+		if errors.As(err, &oauthErr) && oauthErr.Code == usecase.ErrorInvalidToken {
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer realm="oauth", error="%s"`, usecase.ErrorInvalidToken))
+		}
+		abortWithError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, info)
+}
+
+// OpenIdConfiguration godoc
+// @Summary OpenID Connect discovery
+// @Description Provider metadata; endpoints are built from oauth.issuer
+// @Tags OAuth
+// @Produce  json
+// @Success 200 {object} dto.ProviderMetadata "Success"
+// @Router /.well-known/openid-configuration [get]
+func (h *OAuthHandler) OpenIdConfiguration(c *gin.Context) {
+	c.JSON(http.StatusOK, h.authorizationUsecase.Metadata())
+}
+
+// Jwks godoc
+// @Summary ID token signing keys
+// @Description Public keys that verify ID tokens, matched by the kid header
+// @Tags OAuth
+// @Produce  json
+// @Success 200 {object} dto.Jwks "Success"
+// @Router /.well-known/jwks.json [get]
+func (h *OAuthHandler) Jwks(c *gin.Context) {
+	c.JSON(http.StatusOK, h.authorizationUsecase.Jwks())
+}
+
+// renderAuthorizationError sends errors the client may see back to its redirect URI and shows
+// the others to the user
+func renderAuthorizationError(c *gin.Context, err error) {
+	var redirectErr *usecase.RedirectError
+	if errors.As(err, &redirectErr) {
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, redirectErr.RedirectUri)
+		return
+	}
+	status, code, description := http.StatusBadRequest, usecase.ErrorInvalidRequest, ""
+	var oauthErr *usecase.Error
+	if errors.As(err, &oauthErr) {
+		code, description = oauthErr.Code, oauthErr.Description
+	} else {
+		status = helper.TranslateErrorToStatusCode(err)
+		_, description = helper.TranslateErrorToCodeAndMessage(err)
+		if status >= http.StatusInternalServerError {
+			code = usecase.ErrorServerError
+		}
+	}
+	renderPage(c, status, "error", gin.H{"Code": code, "Description": description})
+}
+
+// renderPage serves a hosted page under a nonce-based Content-Security-Policy, so only its own
+// script and style run and no other site can frame it
+func renderPage(c *gin.Context, status int, name string, data gin.H) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	nonce := base64.StdEncoding.EncodeToString(raw)
+	data["Nonce"] = nonce
+	if _, ok := data["Title"]; !ok {
+		data["Title"] = "Sign in"
+	}
+	var page bytes.Buffer
+	if err := pages.ExecuteTemplate(&page, name, data); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Security-Policy", fmt.Sprintf("default-src 'none'; script-src 'nonce-%s'; style-src 'nonce-%s'; "+
+		"connect-src 'self'; form-action 'none'; frame-ancestors 'none'; base-uri 'none'", nonce, nonce))
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
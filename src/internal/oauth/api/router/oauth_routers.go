@@ -0,0 +1,42 @@
+package router
+
+import (
+	"github.com/alielmi98/golang-otp-auth/di"
+	"github.com/alielmi98/golang-otp-auth/internal/middlewares"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/handler"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+func OAuth(router *gin.RouterGroup, cfg *config.Config, handler *handler.OAuthHandler) {
+
+	router.GET("/authorize", handler.Authorize)
+	router.POST("/authorize", handler.Authorize)
+	router.POST("/authorize/complete", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)), handler.CompleteAuthorization)
+	router.POST("/device_authorization", handler.DeviceAuthorization)
+	router.POST("/token", handler.Token)
+	router.GET("/userinfo", handler.UserInfo)
+	router.POST("/userinfo", handler.UserInfo)
+
+	device := router.Group("/device", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)))
+	device.GET("", handler.GetDevice)
+	device.POST("/approve", handler.ApproveDevice)
+	device.POST("/deny", handler.DenyDevice)
+
+}
+
+// WellKnown serves discovery at the root, where OpenID Connect clients look for it under the issuer
+func WellKnown(router gin.IRoutes, handler *handler.OAuthHandler) {
+
+	router.GET("/.well-known/openid-configuration", handler.OpenIdConfiguration)
+	router.GET("/.well-known/jwks.json", handler.Jwks)
+
+}
+
+func Clients(router *gin.RouterGroup, handler *handler.ClientHandler) {
+
+	router.GET("", handler.GetClients)
+	router.POST("", handler.CreateClient)
+	router.DELETE("/:id", handler.DeleteClient)
+
+}
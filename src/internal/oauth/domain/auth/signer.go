@@ -0,0 +1,13 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+)
+
+// IdTokenSigner signs ID tokens and publishes the public keys that verify them
+type IdTokenSigner interface {
+	Sign(ctx context.Context, claims map[string]interface{}) (string, error)
+	Keys() []dto.Jwk
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Client is an application registered to get tokens. Confidential clients hold a secret, stored
+// as its SHA-256 since it is random; public clients, such as CLIs and single-page apps, have none
+// and must use PKCE.
+type Client struct {
+	Id       int    `gorm:"primarykey"`
+	ClientId string `gorm:"type:string;size:64;not null;uniqueIndex"`
+	// Name is shown to the user signing in or approving a device
+	Name       string         `gorm:"type:string;size:100;not null"`
+	SecretHash sql.NullString `gorm:"type:string;size:64;null"`
+	// RedirectUris is the space-separated list of URIs authorization responses may go to; clients
+	// without one can only use the device grant
+	RedirectUris string `gorm:"type:text;not null;default:''"`
+
+	CreatedAt time.Time `gorm:"type:TIMESTAMP with time zone;not null"`
+	CreatedBy int       `gorm:"not null"`
+}
+
+// TableName keeps the table apart from other kinds of clients
+func (Client) TableName() string {
+	return "oauth_clients"
+}
+
+func (c Client) Confidential() bool {
+	return c.SecretHash.Valid
+}
+
+func (c Client) RedirectUriList() []string {
+	return strings.Fields(c.RedirectUris)
+}
+
+// AllowsRedirectUri compares uri with the registered ones exactly, as OpenID Connect requires
+func (c Client) AllowsRedirectUri(uri string) bool {
+	for _, registered := range c.RedirectUriList() {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
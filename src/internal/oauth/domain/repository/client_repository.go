@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/models"
+)
+
+type ClientRepository interface {
+	CreateClient(ctx context.Context, client models.Client) (models.Client, error)
+	DeleteClient(ctx context.Context, id int) error
+	// GetClientByClientId answers RECORD_NOT_FOUND for an unknown client_id
+	GetClientByClientId(ctx context.Context, clientId string) (models.Client, error)
+	GetClients(ctx context.Context) ([]models.Client, error)
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/golang-jwt/jwt"
+)
+
+// RsaSigner signs ID tokens with RS256, which every OpenID Connect client supports
+type RsaSigner struct {
+	key   *rsa.PrivateKey
+	keyId string
+}
+
+func NewRsaSigner(cfg *config.Config) *RsaSigner {
+	logger := logging.GetLogger()
+	key, err := loadSigningKey(cfg.OAuth.SigningKeyFile)
+	if err != nil {
+		logger.Fatal(constants.General, constants.Startup, "invalid oauth signing key",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+	if cfg.OAuth.SigningKeyFile == "" {
+		logger.Warn(constants.General, constants.Startup,
+			"oauth.signingKeyFile is not set; ID tokens are signed with a key generated at startup", nil)
+	}
+	return &RsaSigner{key: key, keyId: thumbprint(&key.PublicKey)}
+}
+
+func loadSigningKey(path string) (*rsa.PrivateKey, error) {
+	if path == "" {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPrivateKeyFromPEM(raw)
+}
+
+func (s *RsaSigner) Sign(ctx context.Context, claims map[string]interface{}) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims(claims))
+	token.Header["kid"] = s.keyId
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return signed, nil
+}
+
+func (s *RsaSigner) Keys() []dto.Jwk {
+	return []dto.Jwk{toJwk(&s.key.PublicKey, s.keyId)}
+}
+
+func toJwk(key *rsa.PublicKey, keyId string) dto.Jwk {
+	return dto.Jwk{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: keyId,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// thumbprint is the RFC 7638 JWK thumbprint, so the key id changes only with the key
+func thumbprint(key *rsa.PublicKey) string {
+	jwk := toJwk(key, "")
+	// Members in lexicographic order, as the RFC requires
+	raw, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{jwk.E, jwk.Kty, jwk.N})
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
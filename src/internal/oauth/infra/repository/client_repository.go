@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"gorm.io/gorm"
+)
+
+type ClientPgRepo struct {
+	db     *gorm.DB
+	logger logging.Logger
+}
+
+func NewClientPgRepo() *ClientPgRepo {
+	return &ClientPgRepo{db: db.GetDb(), logger: logging.GetLogger()}
+}
+
+func (r *ClientPgRepo) CreateClient(ctx context.Context, client models.Client) (models.Client, error) {
+	defer metrics.ObservePostgres("create_oauth_client", time.Now())
+	if err := r.db.WithContext(ctx).Create(&client).Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Insert, "create oauth client failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return client, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return client, nil
+}
+
+func (r *ClientPgRepo) DeleteClient(ctx context.Context, id int) error {
+	defer metrics.ObservePostgres("delete_oauth_client", time.Now())
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Client{})
+	if result.Error != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Delete, "delete oauth client failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: result.Error.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return service_errors.New(service_errors.CodeRecordNotFound)
+	}
+	return nil
+}
+
+func (r *ClientPgRepo) GetClientByClientId(ctx context.Context, clientId string) (models.Client, error) {
+	defer metrics.ObservePostgres("get_oauth_client", time.Now())
+	var client models.Client
+	err := r.db.WithContext(ctx).Where("client_id = ?", clientId).First(&client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return client, service_errors.Wrap(service_errors.CodeRecordNotFound, err)
+	} else if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "get oauth client failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return client, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return client, nil
+}
+
+func (r *ClientPgRepo) GetClients(ctx context.Context) ([]models.Client, error) {
+	defer metrics.ObservePostgres("get_oauth_clients", time.Now())
+	var clients []models.Client
+	if err := r.db.WithContext(ctx).Order("id DESC").Find(&clients).Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "get oauth clients failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return nil, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return clients, nil
+}
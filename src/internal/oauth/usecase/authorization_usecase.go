@@ -0,0 +1,363 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	oauthAuth "github.com/alielmi98/golang-otp-auth/internal/oauth/domain/auth"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	userModels "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	userRepository "github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/go-redis/redis/v7"
+)
+
+// Scopes; phone and email add the matching claims to the ID token
+const (
+	ScopeOpenId = "openid"
+	ScopePhone  = "phone"
+	ScopeEmail  = "email"
+)
+
+const (
+	authorizationKeyPrefix     = "oauth_authorization"
+	authorizationCodeKeyPrefix = "oauth_code"
+	codeChallengeMethodS256    = "S256"
+)
+
+var supportedScopes = []string{ScopeOpenId, ScopePhone, ScopeEmail}
+
+// AuthorizationUsecase runs the OpenID Connect authorization code flow with PKCE. A validated
+// request waits in Redis while the user logs in on the hosted page; completing it issues a
+// one-time code the client exchanges at the token endpoint.
+type AuthorizationUsecase struct {
+	cfg         *config.Config
+	redisClient *redis.Client
+	clientRepo  repository.ClientRepository
+	userRepo    userRepository.UserRepository
+	token       auth.TokenProvider
+	signer      oauthAuth.IdTokenSigner
+}
+
+// authorization is a pending request, and once the user completed it, the code's grant
+type authorization struct {
+	ClientId      string
+	RedirectUri   string
+	Scope         string
+	State         string
+	Nonce         string
+	CodeChallenge string
+	UserId        int
+	AuthTime      time.Time
+}
+
+func NewAuthorizationUsecase(cfg *config.Config, clientRepo repository.ClientRepository, userRepo userRepository.UserRepository, token auth.TokenProvider, signer oauthAuth.IdTokenSigner) *AuthorizationUsecase {
+	return &AuthorizationUsecase{
+		cfg:         cfg,
+		redisClient: cache.GetRedis(),
+		clientRepo:  clientRepo,
+		userRepo:    userRepo,
+		token:       token,
+		signer:      signer,
+	}
+}
+
+// Begin validates an authorization request and keeps it for the hosted login page. Until the
+// client and redirect URI check out, errors are for the user; after that they are RedirectErrors.
+func (u *AuthorizationUsecase) Begin(ctx context.Context, req dto.AuthorizationRequest) (_ dto.AuthorizationPage, err error) {
+	ctx, span := tracing.Start(ctx, "AuthorizationUsecase.Begin")
+	defer tracing.End(span, &err)
+
+	if req.ClientId == "" {
+		return dto.AuthorizationPage{}, newError(ErrorInvalidRequest, "client_id is required")
+	}
+	client, err := u.clientRepo.GetClientByClientId(ctx, req.ClientId)
+	if errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return dto.AuthorizationPage{}, newError(ErrorInvalidClient, "unknown client_id")
+	} else if err != nil {
+		return dto.AuthorizationPage{}, err
+	}
+	if !client.AllowsRedirectUri(req.RedirectUri) {
+		return dto.AuthorizationPage{}, newError(ErrorInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	if requestErr := checkRequest(req); requestErr != nil {
+		return dto.AuthorizationPage{}, u.redirectError(req.RedirectUri, req.State, requestErr)
+	}
+	requestId, err := randomToken()
+	if err != nil {
+		return dto.AuthorizationPage{}, err
+	}
+	pending := authorization{
+		ClientId:      client.ClientId,
+		RedirectUri:   req.RedirectUri,
+		Scope:         strings.Join(strings.Fields(req.Scope), " "),
+		State:         req.State,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+	}
+	err = cache.Set(ctx, u.redisClient, authorizationKey(requestId), pending, u.cfg.OAuth.AuthorizationRequestTtl*time.Second)
+	if err != nil {
+		return dto.AuthorizationPage{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	cancelUri, err := u.errorRedirectUri(req.RedirectUri, req.State, newError(ErrorAccessDenied, "the user cancelled the sign-in"))
+	if err != nil {
+		return dto.AuthorizationPage{}, err
+	}
+	return dto.AuthorizationPage{RequestId: requestId, ClientName: client.Name, CancelUri: cancelUri}, nil
+}
+
+func checkRequest(req dto.AuthorizationRequest) *Error {
+	if req.ResponseType != "code" {
+		return newError(ErrorUnsupportedResponseType, "only response_type=code is supported")
+	}
+	for _, scope := range strings.Fields(req.Scope) {
+		if !slices.Contains(supportedScopes, scope) {
+			return newError(ErrorInvalidScope, "unsupported scope "+scope)
+		}
+	}
+	if req.CodeChallengeMethod != codeChallengeMethodS256 || len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128 {
+		return newError(ErrorInvalidRequest, "a code_challenge with code_challenge_method=S256 is required")
+	}
+	// The service keeps no browser session, so a login can never be skipped
+	if slices.Contains(strings.Fields(req.Prompt), "none") {
+		return newError(ErrorLoginRequired, "")
+	}
+	return nil
+}
+
+// Complete issues the code of a pending request for the user who logged in on the hosted page
+// and returns where to send the browser. Each request is completed once.
+func (u *AuthorizationUsecase) Complete(ctx context.Context, userId int, requestId string) (_ dto.AuthorizationRedirect, err error) {
+	ctx, span := tracing.Start(ctx, "AuthorizationUsecase.Complete")
+	defer tracing.End(span, &err)
+
+	grant, err := u.take(ctx, authorizationKey(requestId))
+	if err == redis.Nil {
+		return dto.AuthorizationRedirect{}, service_errors.New(service_errors.CodeAuthorizationExpired)
+	} else if err != nil {
+		return dto.AuthorizationRedirect{}, err
+	}
+	code, err := randomToken()
+	if err != nil {
+		return dto.AuthorizationRedirect{}, err
+	}
+	grant.UserId = userId
+	grant.AuthTime = time.Now()
+	err = cache.Set(ctx, u.redisClient, authorizationCodeKey(hashCode(code)), grant, u.cfg.OAuth.AuthorizationCodeTtl*time.Second)
+	if err != nil {
+		return dto.AuthorizationRedirect{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	redirectUri, err := redirectWith(grant.RedirectUri, map[string]string{"code": code, "state": grant.State, "iss": u.issuer()})
+	if err != nil {
+		return dto.AuthorizationRedirect{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return dto.AuthorizationRedirect{RedirectUri: redirectUri}, nil
+}
+
+// exchange spends an authorization code for the client's tokens, with an ID token when the
+// openid scope was asked for
+func (u *AuthorizationUsecase) exchange(ctx context.Context, client models.Client, code string, redirectUri string, codeVerifier string) (_ dto.TokenResponse, err error) {
+	defer func() {
+		result := metrics.ResultSuccess
+		if err != nil {
+			result = metrics.ResultFailure
+		}
+		metrics.AuthorizationCodes.WithLabelValues(result).Inc()
+	}()
+
+	grant, err := u.take(ctx, authorizationCodeKey(hashCode(code)))
+	if err == redis.Nil {
+		return dto.TokenResponse{}, newError(ErrorInvalidGrant, "code is invalid, expired or already used")
+	} else if err != nil {
+		return dto.TokenResponse{}, err
+	}
+	if grant.ClientId != client.ClientId {
+		return dto.TokenResponse{}, newError(ErrorInvalidGrant, "code was issued to another client")
+	}
+	if grant.RedirectUri != redirectUri {
+		return dto.TokenResponse{}, newError(ErrorInvalidGrant, "redirect_uri does not match the authorization request")
+	}
+	if subtle.ConstantTimeCompare([]byte(grant.CodeChallenge), []byte(codeChallenge(codeVerifier))) != 1 {
+		return dto.TokenResponse{}, newError(ErrorInvalidGrant, "code_verifier does not match code_challenge")
+	}
+
+	tokens, user, err := issueTokens(ctx, u.userRepo, u.token, client.ClientId, grant.UserId)
+	if err != nil {
+		return dto.TokenResponse{}, err
+	}
+	response := toTokenResponse(tokens)
+	response.Scope = grant.Scope
+	if slices.Contains(strings.Fields(grant.Scope), ScopeOpenId) {
+		response.IdToken, err = u.idToken(ctx, client, user, grant)
+		if err != nil {
+			return dto.TokenResponse{}, err
+		}
+	}
+	return response, nil
+}
+
+func (u *AuthorizationUsecase) idToken(ctx context.Context, client models.Client, user userModels.User, grant authorization) (string, error) {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":       u.issuer(),
+		"sub":       strconv.Itoa(user.Id),
+		"aud":       client.ClientId,
+		"iat":       now.Unix(),
+		"exp":       now.Add(u.cfg.OAuth.IdTokenExpireTime * time.Second).Unix(),
+		"auth_time": grant.AuthTime.Unix(),
+	}
+	if grant.Nonce != "" {
+		claims["nonce"] = grant.Nonce
+	}
+	scopes := strings.Fields(grant.Scope)
+	info := toUserInfo(user)
+	if slices.Contains(scopes, ScopePhone) && info.PhoneNumber != "" {
+		claims["phone_number"] = info.PhoneNumber
+		claims["phone_number_verified"] = true
+	}
+	if slices.Contains(scopes, ScopeEmail) && info.Email != "" {
+		claims["email"] = info.Email
+		claims["email_verified"] = true
+	}
+	return u.signer.Sign(ctx, claims)
+}
+
+// UserInfo returns the claims of the user an access token was issued to
+func (u *AuthorizationUsecase) UserInfo(ctx context.Context, accessToken string) (_ dto.UserInfo, err error) {
+	ctx, span := tracing.Start(ctx, "AuthorizationUsecase.UserInfo")
+	defer tracing.End(span, &err)
+
+	claims, err := u.token.GetClaims(ctx, accessToken)
+	if err != nil {
+		return dto.UserInfo{}, newError(ErrorInvalidToken, "the access token is invalid or expired")
+	}
+	userId, ok := claims[constants.UserIdKey].(float64)
+	if !ok {
+		return dto.UserInfo{}, newError(ErrorInvalidToken, "the access token names no user")
+	}
+	user, err := u.userRepo.FetchUserInfoById(ctx, int(userId))
+	if errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return dto.UserInfo{}, newError(ErrorInvalidToken, "the user no longer exists")
+	} else if err != nil {
+		return dto.UserInfo{}, err
+	}
+	return toUserInfo(user), nil
+}
+
+// Both identifiers were proven with a code before they were stored, so both are verified
+func toUserInfo(user userModels.User) dto.UserInfo {
+	verified := true
+	info := dto.UserInfo{Sub: strconv.Itoa(user.Id)}
+	if user.MobileNumber != "" {
+		info.PhoneNumber = user.MobileNumber
+		info.PhoneNumberVerified = &verified
+	}
+	if user.Email != "" {
+		info.Email = user.Email
+		info.EmailVerified = &verified
+	}
+	return info
+}
+
+// Metadata is the discovery document; every endpoint lives under oauth.issuer
+func (u *AuthorizationUsecase) Metadata() dto.ProviderMetadata {
+	issuer := u.issuer()
+	return dto.ProviderMetadata{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/api/v1/oauth/authorize",
+		TokenEndpoint:                     issuer + "/api/v1/oauth/token",
+		UserinfoEndpoint:                  issuer + "/api/v1/oauth/userinfo",
+		JwksUri:                           issuer + "/.well-known/jwks.json",
+		DeviceAuthorizationEndpoint:       issuer + "/api/v1/oauth/device_authorization",
+		ScopesSupported:                   supportedScopes,
+		ResponseTypesSupported:            []string{"code"},
+		ResponseModesSupported:            []string{"query"},
+		GrantTypesSupported:               []string{AuthorizationCodeGrantType, RefreshTokenGrantType, DeviceCodeGrantType},
+		SubjectTypesSupported:             []string{"public"},
+		IdTokenSigningAlgValuesSupported:  []string{"RS256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{codeChallengeMethodS256},
+		ClaimsSupported: []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce",
+			"phone_number", "phone_number_verified", "email", "email_verified"},
+		AuthorizationResponseIssParameter: true,
+	}
+}
+
+func (u *AuthorizationUsecase) Jwks() dto.Jwks {
+	return dto.Jwks{Keys: u.signer.Keys()}
+}
+
+// take reads and removes a pending request or code in one step, so it is used once
+func (u *AuthorizationUsecase) take(ctx context.Context, key string) (authorization, error) {
+	pipe := u.redisClient.WithContext(ctx).TxPipeline()
+	get := pipe.Get(key)
+	pipe.Del(key)
+	_, err := pipe.Exec()
+	if err == redis.Nil {
+		return authorization{}, err
+	} else if err != nil {
+		return authorization{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	var grant authorization
+	if err = json.Unmarshal([]byte(get.Val()), &grant); err != nil {
+		return authorization{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return grant, nil
+}
+
+func (u *AuthorizationUsecase) redirectError(redirectUri string, state string, oauthErr *Error) error {
+	uri, err := u.errorRedirectUri(redirectUri, state, oauthErr)
+	if err != nil {
+		return err
+	}
+	return &RedirectError{RedirectUri: uri, Err: oauthErr}
+}
+
+func (u *AuthorizationUsecase) errorRedirectUri(redirectUri string, state string, oauthErr *Error) (string, error) {
+	uri, err := redirectWith(redirectUri, map[string]string{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+		"state":             state,
+		"iss":               u.issuer(),
+	})
+	if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return uri, nil
+}
+
+func (u *AuthorizationUsecase) issuer() string {
+	return strings.TrimSuffix(u.cfg.OAuth.Issuer, "/")
+}
+
+// codeChallenge is the S256 transformation of a PKCE code verifier (RFC 7636 section 4.2)
+func codeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func authorizationKey(requestId string) string {
+	return fmt.Sprintf("%s:%s", authorizationKeyPrefix, requestId)
+}
+
+func authorizationCodeKey(codeHash string) string {
+	return fmt.Sprintf("%s:%s", authorizationCodeKeyPrefix, codeHash)
+}
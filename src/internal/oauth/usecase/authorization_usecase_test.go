@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/models"
+	userModels "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	userRepository "github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/internal/user/infra/auth"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+const (
+	testRedirectUri  = "https://app.example.com/callback"
+	testClientSecret = "testClientSecret"
+	testUserId       = 7
+	// testCodeChallenge is BASE64URL(SHA256(testCodeVerifier)), worked out outside of Go
+	testCodeVerifier  = "dBjftJeZ4CVP-mJ92K9ZxNw0cD7kNvN6yJNyTXFZCFQ"
+	testCodeChallenge = "GF4vwWEkuAeCjYNKVfTLcaloVJyv27ae1CJ09qC-Kls"
+)
+
+// memoryClients holds a public client "cli", a confidential one "web" and a second public
+// client "other"
+type memoryClients map[string]models.Client
+
+func newMemoryClients() memoryClients {
+	return memoryClients{
+		"cli":   {Id: 1, ClientId: "cli", Name: "CLI", RedirectUris: testRedirectUri},
+		"other": {Id: 2, ClientId: "other", Name: "Other", RedirectUris: testRedirectUri},
+		"web": {Id: 3, ClientId: "web", Name: "Web", RedirectUris: testRedirectUri,
+			SecretHash: sql.NullString{String: hashCode(testClientSecret), Valid: true}},
+	}
+}
+
+func (m memoryClients) CreateClient(ctx context.Context, client models.Client) (models.Client, error) {
+	m[client.ClientId] = client
+	return client, nil
+}
+func (m memoryClients) DeleteClient(ctx context.Context, id int) error { return nil }
+func (m memoryClients) GetClientByClientId(ctx context.Context, clientId string) (models.Client, error) {
+	client, ok := m[clientId]
+	if !ok {
+		return models.Client{}, service_errors.New(service_errors.CodeRecordNotFound)
+	}
+	return client, nil
+}
+func (m memoryClients) GetClients(ctx context.Context) ([]models.Client, error) { return nil, nil }
+
+// knownUser finds testUserId only; the other UserRepository methods are not used by OAuth
+type knownUser struct {
+	userRepository.UserRepository
+}
+
+func (knownUser) FetchUserInfoById(ctx context.Context, id int) (userModels.User, error) {
+	if id != testUserId {
+		return userModels.User{}, service_errors.New(service_errors.CodeRecordNotFound)
+	}
+	return userModels.User{Id: testUserId, MobileNumber: "+989121234567"}, nil
+}
+
+type stubSigner struct{}
+
+func (stubSigner) Sign(ctx context.Context, claims map[string]interface{}) (string, error) {
+	return "signed-id-token", nil
+}
+func (stubSigner) Keys() []dto.Jwk { return nil }
+
+type testOAuth struct {
+	cfg           *config.Config
+	authorization *AuthorizationUsecase
+	device        *DeviceUsecase
+	token         *TokenUsecase
+}
+
+func newTestOAuth(t *testing.T) testOAuth {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	host, port, err := net.SplitHostPort(mr.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Logger.Level = "error"
+	logging.InitLogger(cfg)
+	cfg.Redis.Host, cfg.Redis.Port = host, port
+	cfg.Redis.PoolSize = 5
+	cfg.Redis.DialTimeout, cfg.Redis.ReadTimeout, cfg.Redis.WriteTimeout = 5, 5, 5
+	cfg.JWT.Secret, cfg.JWT.RefreshSecret = "testSecret", "testRefreshSecret"
+	cfg.JWT.AccessTokenExpireDuration, cfg.JWT.RefreshTokenExpireDuration = 15, 60
+	cfg.OAuth.Issuer = "https://auth.example.com/"
+	cfg.OAuth.IdTokenExpireTime = 300
+	cfg.OAuth.AuthorizationRequestTtl = 600
+	cfg.OAuth.AuthorizationCodeTtl = 60
+	cfg.OAuth.DeviceVerificationUri = "https://auth.example.com/device"
+	cfg.OAuth.DeviceCodeTtl = 600
+	cfg.OAuth.DevicePollInterval = 5
+	if err := cache.InitRedis(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cache.CloseRedis)
+
+	clients := newMemoryClients()
+	token := auth.NewJwtProvider(cfg)
+	limiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	unlimited := func(prefix string) *ratelimit.OTPRateLimitService {
+		return ratelimit.NewOTPRateLimitService(limiter, ratelimit.OTPRateLimitConfig{
+			Policy: prefix, KeyPrefix: prefix, MaxAttempts: 100, Window: time.Minute,
+		})
+	}
+	authorization := NewAuthorizationUsecase(cfg, clients, knownUser{}, token, stubSigner{})
+	device := NewDeviceUsecase(cfg, clients, knownUser{}, token, unlimited("device_authorization"), unlimited("user_code"))
+	return testOAuth{
+		cfg:           cfg,
+		authorization: authorization,
+		device:        device,
+		token:         NewTokenUsecase(clients, token, device, authorization),
+	}
+}
+
+// oauthErrorCode is the OAuth error code of err, or "" when err is not an OAuth error
+func oauthErrorCode(err error) string {
+	var oauthErr *Error
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	var redirectErr *RedirectError
+	if errors.As(err, &redirectErr) {
+		return redirectErr.Err.Code
+	}
+	return ""
+}
+
+func validAuthorizationRequest() dto.AuthorizationRequest {
+	return dto.AuthorizationRequest{
+		ResponseType:        "code",
+		ClientId:            "cli",
+		RedirectUri:         testRedirectUri,
+		Scope:               "openid phone",
+		State:               "xyz",
+		CodeChallenge:       testCodeChallenge,
+		CodeChallengeMethod: codeChallengeMethodS256,
+	}
+}
+
+// authorize runs the authorization request for clientId and the hosted login of testUserId and
+// returns the code the client receives
+func (o testOAuth) authorize(t *testing.T, clientId string) string {
+	t.Helper()
+	ctx := context.Background()
+	req := validAuthorizationRequest()
+	req.ClientId = clientId
+	page, err := o.authorization.Begin(ctx, req)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	redirect, err := o.authorization.Complete(ctx, testUserId, page.RequestId)
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	uri, err := url.Parse(redirect.RedirectUri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Query().Get("state") != req.State || uri.Query().Get("iss") != "https://auth.example.com" {
+		t.Errorf("redirect %s lacks the state or issuer", redirect.RedirectUri)
+	}
+	return uri.Query().Get("code")
+}
+
+func TestCodeChallengeS256(t *testing.T) {
+	if got := codeChallenge(testCodeVerifier); got != testCodeChallenge {
+		t.Errorf("got %s, want %s", got, testCodeChallenge)
+	}
+}
+
+func TestCheckRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(req *dto.AuthorizationRequest)
+		code   string
+	}{
+		{"valid", func(req *dto.AuthorizationRequest) {}, ""},
+		{"implicit flow", func(req *dto.AuthorizationRequest) { req.ResponseType = "token" }, ErrorUnsupportedResponseType},
+		{"unknown scope", func(req *dto.AuthorizationRequest) { req.Scope = "openid admin" }, ErrorInvalidScope},
+		{"no code challenge", func(req *dto.AuthorizationRequest) { req.CodeChallenge, req.CodeChallengeMethod = "", "" }, ErrorInvalidRequest},
+		{"plain method", func(req *dto.AuthorizationRequest) { req.CodeChallengeMethod = "plain" }, ErrorInvalidRequest},
+		{"short challenge", func(req *dto.AuthorizationRequest) { req.CodeChallenge = testCodeChallenge[:42] }, ErrorInvalidRequest},
+		{"prompt none", func(req *dto.AuthorizationRequest) { req.Prompt = "none" }, ErrorLoginRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validAuthorizationRequest()
+			tt.change(&req)
+			err := checkRequest(req)
+			if tt.code == "" {
+				if err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Code != tt.code {
+				t.Errorf("got %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+// A redirect URI that is not registered is never redirected to, so the error is shown to the user
+func TestBeginRedirectUriExactMatch(t *testing.T) {
+	o := newTestOAuth(t)
+	tests := []struct {
+		name        string
+		redirectUri string
+		valid       bool
+	}{
+		{"registered", testRedirectUri, true},
+		{"trailing slash", testRedirectUri + "/", false},
+		{"extra query", testRedirectUri + "?next=/admin", false},
+		{"upper-case host", "https://APP.example.com/callback", false},
+		{"sub path", testRedirectUri + "/evil", false},
+		{"http", "http://app.example.com/callback", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validAuthorizationRequest()
+			req.RedirectUri = tt.redirectUri
+			_, err := o.authorization.Begin(context.Background(), req)
+			if tt.valid {
+				if err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+				return
+			}
+			var redirectErr *RedirectError
+			if errors.As(err, &redirectErr) || oauthErrorCode(err) != ErrorInvalidRequest {
+				t.Errorf("got %v, want invalid_request without a redirect", err)
+			}
+		})
+	}
+}
+
+func TestExchangeAuthorizationCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(req *dto.TokenRequest)
+		code   string
+	}{
+		{"valid", func(req *dto.TokenRequest) {}, ""},
+		{"wrong verifier", func(req *dto.TokenRequest) { req.CodeVerifier = testCodeVerifier[:42] + "x" }, ErrorInvalidGrant},
+		{"challenge as verifier", func(req *dto.TokenRequest) { req.CodeVerifier = testCodeChallenge }, ErrorInvalidGrant},
+		{"other redirect uri", func(req *dto.TokenRequest) { req.RedirectUri = testRedirectUri + "/" }, ErrorInvalidGrant},
+		{"other client", func(req *dto.TokenRequest) { req.ClientId = "other" }, ErrorInvalidGrant},
+		{"unknown code", func(req *dto.TokenRequest) { req.Code = "unknown" }, ErrorInvalidGrant},
+		{"no verifier", func(req *dto.TokenRequest) { req.CodeVerifier = "" }, ErrorInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOAuth(t)
+			req := dto.TokenRequest{
+				GrantType:    AuthorizationCodeGrantType,
+				ClientId:     "cli",
+				Code:         o.authorize(t, "cli"),
+				RedirectUri:  testRedirectUri,
+				CodeVerifier: testCodeVerifier,
+			}
+			tt.change(&req)
+			response, err := o.token.Token(context.Background(), req)
+			if tt.code != "" {
+				if oauthErrorCode(err) != tt.code {
+					t.Errorf("got %v, want %s", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.AccessToken == "" || response.RefreshToken == "" || response.IdToken != "signed-id-token" {
+				t.Errorf("got %+v, want access, refresh and ID tokens", response)
+			}
+		})
+	}
+}
+
+// A code is spent by its first exchange, whether that succeeded or not
+func TestAuthorizationCodeSingleUse(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOAuth(t)
+	req := dto.TokenRequest{
+		GrantType:    AuthorizationCodeGrantType,
+		ClientId:     "cli",
+		Code:         o.authorize(t, "cli"),
+		RedirectUri:  testRedirectUri,
+		CodeVerifier: testCodeVerifier,
+	}
+	if _, err := o.token.Token(ctx, req); err != nil {
+		t.Fatalf("first exchange: %v", err)
+	}
+	if _, err := o.token.Token(ctx, req); oauthErrorCode(err) != ErrorInvalidGrant {
+		t.Errorf("second exchange: got %v, want invalid_grant", err)
+	}
+
+	wrong := req
+	wrong.Code = o.authorize(t, "cli")
+	wrong.CodeVerifier = testCodeVerifier[:42] + "x"
+	if _, err := o.token.Token(ctx, wrong); oauthErrorCode(err) != ErrorInvalidGrant {
+		t.Fatalf("wrong verifier: got %v, want invalid_grant", err)
+	}
+	wrong.CodeVerifier = testCodeVerifier
+	if _, err := o.token.Token(ctx, wrong); oauthErrorCode(err) != ErrorInvalidGrant {
+		t.Errorf("retry after a failed exchange: got %v, want invalid_grant", err)
+	}
+}
+
+// Tokens from the code flow are bound to the client: they renew only for it
+func TestRefreshTokenBoundToClient(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOAuth(t)
+	response, err := o.token.Token(ctx, dto.TokenRequest{
+		GrantType:    AuthorizationCodeGrantType,
+		ClientId:     "cli",
+		Code:         o.authorize(t, "cli"),
+		RedirectUri:  testRedirectUri,
+		CodeVerifier: testCodeVerifier,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh := dto.TokenRequest{GrantType: RefreshTokenGrantType, ClientId: "other", RefreshToken: response.RefreshToken}
+	if _, err = o.token.Token(ctx, refresh); oauthErrorCode(err) != ErrorInvalidGrant {
+		t.Errorf("other client: got %v, want invalid_grant", err)
+	}
+	refresh.ClientId = "cli"
+	if _, err = o.token.Token(ctx, refresh); err != nil {
+		t.Errorf("own client: %v", err)
+	}
+}
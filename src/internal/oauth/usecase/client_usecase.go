@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+)
+
+var clientIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ClientUsecase registers the applications allowed to ask for tokens
+type ClientUsecase struct {
+	repo repository.ClientRepository
+}
+
+func NewClientUsecase(repo repository.ClientRepository) *ClientUsecase {
+	return &ClientUsecase{repo: repo}
+}
+
+func (u *ClientUsecase) CreateClient(ctx context.Context, req *dto.CreateClientRequest, createdBy int) (_ dto.Client, err error) {
+	ctx, span := tracing.Start(ctx, "ClientUsecase.CreateClient")
+	defer tracing.End(span, &err)
+
+	if !clientIdPattern.MatchString(req.ClientId) {
+		return dto.Client{}, invalidClient("client_id may only contain letters, digits, '.', '_' and '-'")
+	}
+	for _, redirectUri := range req.RedirectUris {
+		if err = validateRedirectUri(redirectUri); err != nil {
+			return dto.Client{}, err
+		}
+	}
+	_, err = u.repo.GetClientByClientId(ctx, req.ClientId)
+	if err == nil {
+		return dto.Client{}, service_errors.New(service_errors.CodeOAuthClientExists)
+	} else if !errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return dto.Client{}, err
+	}
+
+	client := models.Client{
+		ClientId:     req.ClientId,
+		Name:         req.Name,
+		RedirectUris: strings.Join(req.RedirectUris, " "),
+		CreatedBy:    createdBy,
+	}
+	var secret string
+	if req.Confidential {
+		secret, err = randomToken()
+		if err != nil {
+			return dto.Client{}, err
+		}
+		client.SecretHash = sql.NullString{String: hashCode(secret), Valid: true}
+	}
+	client, err = u.repo.CreateClient(ctx, client)
+	if err != nil {
+		return dto.Client{}, err
+	}
+	result := toClientDto(client)
+	result.ClientSecret = secret
+	return result, nil
+}
+
+func (u *ClientUsecase) DeleteClient(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "ClientUsecase.DeleteClient")
+	defer tracing.End(span, &err)
+
+	return u.repo.DeleteClient(ctx, id)
+}
+
+func (u *ClientUsecase) GetClients(ctx context.Context) (_ []dto.Client, err error) {
+	ctx, span := tracing.Start(ctx, "ClientUsecase.GetClients")
+	defer tracing.End(span, &err)
+
+	clients, err := u.repo.GetClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.Client, 0, len(clients))
+	for _, client := range clients {
+		result = append(result, toClientDto(client))
+	}
+	return result, nil
+}
+
+func toClientDto(client models.Client) dto.Client {
+	return dto.Client{
+		Id:           client.Id,
+		ClientId:     client.ClientId,
+		Name:         client.Name,
+		RedirectUris: client.RedirectUriList(),
+		Confidential: client.Confidential(),
+		CreatedAt:    client.CreatedAt,
+		CreatedBy:    client.CreatedBy,
+	}
+}
+
+// validateRedirectUri accepts https URIs, http ones on the loopback interface and the
+// reverse-domain private schemes of native apps (RFC 8252 section 7). Schemes such as
+// javascript: have no dot, so the hosted login page can never be sent to one.
+func validateRedirectUri(redirectUri string) error {
+	uri, err := url.Parse(redirectUri)
+	if err != nil || !uri.IsAbs() || strings.ContainsAny(redirectUri, " \t\r\n") {
+		return invalidClient("redirect_uri must be an absolute URI: " + redirectUri)
+	}
+	if uri.Fragment != "" {
+		return invalidClient("redirect_uri must not have a fragment: " + redirectUri)
+	}
+	switch uri.Scheme {
+	case "https":
+		if uri.Host == "" {
+			return invalidClient("redirect_uri has no host: " + redirectUri)
+		}
+	case "http":
+		if !isLoopback(uri.Hostname()) {
+			return invalidClient("http redirect_uri is only allowed on the loopback interface: " + redirectUri)
+		}
+	default:
+		if !strings.Contains(uri.Scheme, ".") {
+			return invalidClient("custom redirect_uri schemes must be reverse domain names: " + redirectUri)
+		}
+	}
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func invalidClient(message string) error {
+	serviceErr := service_errors.New(service_errors.CodeInvalidOAuthClient)
+	serviceErr.TechnicalMessage = message
+	return serviceErr
+}
+
+// authenticateClient looks clientId up and checks the secret of confidential clients
+func authenticateClient(ctx context.Context, repo repository.ClientRepository, clientId string, secret string) (models.Client, error) {
+	if clientId == "" {
+		return models.Client{}, newError(ErrorInvalidClient, "client_id is required")
+	}
+	client, err := repo.GetClientByClientId(ctx, clientId)
+	if errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return models.Client{}, newError(ErrorInvalidClient, "unknown client_id")
+	} else if err != nil {
+		return models.Client{}, err
+	}
+	if client.Confidential() &&
+		subtle.ConstantTimeCompare([]byte(client.SecretHash.String), []byte(hashCode(secret))) != 1 {
+		return models.Client{}, newError(ErrorInvalidClient, "client authentication failed")
+	}
+	return client, nil
+}
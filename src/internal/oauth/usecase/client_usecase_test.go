@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+func TestValidateRedirectUri(t *testing.T) {
+	tests := []struct {
+		uri   string
+		valid bool
+	}{
+		{"https://app.example.com/callback", true},
+		{"https://app.example.com/callback?tenant=1", true},
+		{"http://127.0.0.1:8400/callback", true},
+		{"http://[::1]/callback", true},
+		{"http://localhost:3000/callback", true},
+		{"com.example.app:/oauth", true},
+		{"http://app.example.com/callback", false},
+		{"https:///callback", false},
+		{"https://app.example.com/callback#fragment", false},
+		{"/callback", false},
+		{"javascript:alert(1)", false},
+		{"myapp:/oauth", false},
+		{"https://app.example.com/call back", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			err := validateRedirectUri(tt.uri)
+			if tt.valid && err != nil {
+				t.Errorf("got %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, service_errors.New(service_errors.CodeInvalidOAuthClient)) {
+				t.Errorf("got %v, want INVALID_OAUTH_CLIENT", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientId string
+		secret   string
+		valid    bool
+	}{
+		{"public client", "cli", "", true},
+		{"public client ignores a secret", "cli", "anything", true},
+		{"confidential client", "web", testClientSecret, true},
+		{"confidential client without secret", "web", "", false},
+		{"confidential client with wrong secret", "web", testClientSecret + "x", false},
+		{"unknown client", "unknown", "", false},
+		{"no client", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := authenticateClient(context.Background(), newMemoryClients(), tt.clientId, tt.secret)
+			if !tt.valid {
+				if oauthErrorCode(err) != ErrorInvalidClient {
+					t.Errorf("got %v, want invalid_client", err)
+				}
+				return
+			}
+			if err != nil || client.ClientId != tt.clientId {
+				t.Errorf("got %q, %v; want client %q", client.ClientId, err, tt.clientId)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	userRepository "github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/go-redis/redis/v7"
+)
+
+const (
+	deviceCodeKeyPrefix = "device_code"
+	userCodeKeyPrefix   = "device_user_code"
+	// userCodeAlphabet has no vowels, so codes spell no words, and no look-alike characters
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+	// slowDownStep is how many seconds slow_down adds to the poll interval (RFC 8628 section 3.5)
+	slowDownStep = 5
+)
+
+// Device authorization states; approved and denied are final
+const (
+	devicePending  = "pending"
+	deviceApproved = "approved"
+	deviceDenied   = "denied"
+)
+
+// DeviceUsecase runs the OAuth 2.0 device authorization grant (RFC 8628). A device gets a device
+// code to poll with and a short user code, which a user logged in on their phone approves.
+type DeviceUsecase struct {
+	cfg           *config.Config
+	redisClient   *redis.Client
+	clientRepo    repository.ClientRepository
+	userRepo      userRepository.UserRepository
+	token         auth.TokenProvider
+	createLimiter *ratelimit.OTPRateLimitService
+	codeLimiter   *ratelimit.OTPRateLimitService
+}
+
+type deviceState struct {
+	ClientId string
+	Scope    string
+	UserCode string
+	Status   string
+	UserId   int
+	// Interval is the current minimum number of seconds between polls
+	Interval   int
+	LastPollAt time.Time
+	ExpiresAt  time.Time
+}
+
+func NewDeviceUsecase(cfg *config.Config, clientRepo repository.ClientRepository, userRepo userRepository.UserRepository, token auth.TokenProvider, createLimiter *ratelimit.OTPRateLimitService, codeLimiter *ratelimit.OTPRateLimitService) *DeviceUsecase {
+	return &DeviceUsecase{
+		cfg:           cfg,
+		redisClient:   cache.GetRedis(),
+		clientRepo:    clientRepo,
+		userRepo:      userRepo,
+		token:         token,
+		createLimiter: createLimiter,
+		codeLimiter:   codeLimiter,
+	}
+}
+
+// Authorize issues the device and user codes for the client
+func (u *DeviceUsecase) Authorize(ctx context.Context, clientId string, clientSecret string, scope string, clientIp string) (_ dto.DeviceAuthorizationResponse, err error) {
+	ctx, span := tracing.Start(ctx, "DeviceUsecase.Authorize")
+	defer tracing.End(span, &err)
+
+	_, err = authenticateClient(ctx, u.clientRepo, clientId, clientSecret)
+	if err != nil {
+		return dto.DeviceAuthorizationResponse{}, err
+	}
+	err = u.createLimiter.CheckOTPRateLimit(ctx, clientIp)
+	if err != nil {
+		return dto.DeviceAuthorizationResponse{}, err
+	}
+
+	deviceCode, err := randomToken()
+	if err != nil {
+		return dto.DeviceAuthorizationResponse{}, err
+	}
+	ttl := u.cfg.OAuth.DeviceCodeTtl * time.Second
+	// Entries outlive the code so a late poll is told expired_token rather than invalid_grant
+	retention := 2 * ttl
+	userCode, err := u.reserveUserCode(ctx, hashCode(deviceCode), retention)
+	if err != nil {
+		return dto.DeviceAuthorizationResponse{}, err
+	}
+	state := deviceState{
+		ClientId:  clientId,
+		Scope:     scope,
+		UserCode:  userCode,
+		Status:    devicePending,
+		Interval:  u.cfg.OAuth.DevicePollInterval,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	err = cache.Set(ctx, u.redisClient, deviceCodeKey(hashCode(deviceCode)), state, retention)
+	if err != nil {
+		return dto.DeviceAuthorizationResponse{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+
+	complete, err := url.Parse(u.cfg.OAuth.DeviceVerificationUri)
+	if err != nil {
+		return dto.DeviceAuthorizationResponse{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	query := complete.Query()
+	query.Set("user_code", formatUserCode(userCode))
+	complete.RawQuery = query.Encode()
+	return dto.DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                formatUserCode(userCode),
+		VerificationUri:         u.cfg.OAuth.DeviceVerificationUri,
+		VerificationUriComplete: complete.String(),
+		ExpiresIn:               int(u.cfg.OAuth.DeviceCodeTtl),
+		Interval:                state.Interval,
+	}, nil
+}
+
+// reserveUserCode picks a user code no pending device uses and points it at the device
+func (u *DeviceUsecase) reserveUserCode(ctx context.Context, deviceHash string, ttl time.Duration) (string, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		code, err := generateUserCode()
+		if err != nil {
+			return "", err
+		}
+		ok, err := u.redisClient.WithContext(ctx).SetNX(userCodeKey(code), deviceHash, ttl).Result()
+		if err != nil {
+			return "", service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		if ok {
+			return code, nil
+		}
+	}
+	return "", service_errors.New(service_errors.CodeInternal)
+}
+
+// poll reports the device's state, slowing down clients that poll faster than the interval.
+// An approved device gets its tokens once; the codes are gone afterwards.
+func (u *DeviceUsecase) poll(ctx context.Context, clientId string, deviceCode string) (dto.TokenResponse, error) {
+	key := deviceCodeKey(hashCode(deviceCode))
+	var state deviceState
+	err := u.redisClient.WithContext(ctx).Watch(func(tx *redis.Tx) error {
+		raw, err := tx.Get(key).Bytes()
+		if err == redis.Nil {
+			return newError(ErrorInvalidGrant, "unknown device_code")
+		} else if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		if err = json.Unmarshal(raw, &state); err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		if state.ClientId != clientId {
+			return newError(ErrorInvalidGrant, "device_code was issued to another client")
+		}
+
+		now := time.Now()
+		if !now.Before(state.ExpiresAt) || state.Status != devicePending {
+			// Final answers are collected once, by this poll
+			_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+				pipe.Del(key, userCodeKey(state.UserCode))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			switch {
+			case state.Status == deviceApproved:
+				return nil
+			case state.Status == deviceDenied:
+				return newError(ErrorAccessDenied, "")
+			default:
+				return newError(ErrorExpiredToken, "")
+			}
+		}
+
+		tooSoon := !state.LastPollAt.IsZero() && now.Sub(state.LastPollAt) < time.Duration(state.Interval)*time.Second
+		if tooSoon {
+			state.Interval += slowDownStep
+		}
+		state.LastPollAt = now
+		raw, err = json.Marshal(state)
+		if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		ttl, err := tx.TTL(key).Result()
+		if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+			pipe.Set(key, raw, ttl)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if tooSoon {
+			return newError(ErrorSlowDown, "")
+		}
+		return newError(ErrorAuthorizationPending, "")
+	}, key)
+	if err == redis.TxFailedErr {
+		// Another poll for the same code ran at the same time
+		return dto.TokenResponse{}, newError(ErrorSlowDown, "")
+	} else if err != nil {
+		return dto.TokenResponse{}, err
+	}
+
+	token, _, err := issueTokens(ctx, u.userRepo, u.token, clientId, state.UserId)
+	if err != nil {
+		return dto.TokenResponse{}, err
+	}
+	return toTokenResponse(token), nil
+}
+
+// Get describes the device behind userCode so the user can check the client before answering
+func (u *DeviceUsecase) Get(ctx context.Context, userId int, userCode string) (_ dto.DeviceInfo, err error) {
+	ctx, span := tracing.Start(ctx, "DeviceUsecase.Get")
+	defer tracing.End(span, &err)
+
+	key, err := u.find(ctx, userId, userCode)
+	if err != nil {
+		return dto.DeviceInfo{}, err
+	}
+	state, err := cache.Get[deviceState](ctx, u.redisClient, key)
+	if err == redis.Nil {
+		return dto.DeviceInfo{}, service_errors.New(service_errors.CodeUserCodeInvalid)
+	} else if err != nil {
+		return dto.DeviceInfo{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	if !time.Now().Before(state.ExpiresAt) {
+		return dto.DeviceInfo{}, service_errors.New(service_errors.CodeUserCodeInvalid)
+	}
+	if state.Status != devicePending {
+		return dto.DeviceInfo{}, service_errors.New(service_errors.CodeDeviceAnswered)
+	}
+	client, err := u.clientRepo.GetClientByClientId(ctx, state.ClientId)
+	if err != nil {
+		return dto.DeviceInfo{}, err
+	}
+	return dto.DeviceInfo{
+		ClientId:   client.ClientId,
+		ClientName: client.Name,
+		Scope:      state.Scope,
+		ExpiresAt:  state.ExpiresAt,
+	}, nil
+}
+
+// Answer approves the device for userId, or denies it. Only the first answer counts.
+func (u *DeviceUsecase) Answer(ctx context.Context, userId int, userCode string, approve bool) (err error) {
+	ctx, span := tracing.Start(ctx, "DeviceUsecase.Answer")
+	defer tracing.End(span, &err)
+
+	key, err := u.find(ctx, userId, userCode)
+	if err != nil {
+		return err
+	}
+	err = u.redisClient.WithContext(ctx).Watch(func(tx *redis.Tx) error {
+		raw, err := tx.Get(key).Bytes()
+		if err == redis.Nil {
+			return service_errors.New(service_errors.CodeUserCodeInvalid)
+		} else if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		var state deviceState
+		if err = json.Unmarshal(raw, &state); err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		if !time.Now().Before(state.ExpiresAt) {
+			return service_errors.New(service_errors.CodeUserCodeInvalid)
+		}
+		if state.Status != devicePending {
+			return service_errors.New(service_errors.CodeDeviceAnswered)
+		}
+		state.Status = deviceDenied
+		if approve {
+			state.Status = deviceApproved
+			state.UserId = userId
+		}
+		raw, err = json.Marshal(state)
+		if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		ttl, err := tx.TTL(key).Result()
+		if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+			pipe.Set(key, raw, ttl)
+			return nil
+		})
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		return service_errors.New(service_errors.CodeDeviceAnswered)
+	} else if err != nil {
+		return err
+	}
+
+	result := metrics.ResultDenied
+	if approve {
+		result = metrics.ResultApproved
+	}
+	metrics.DeviceAuthorizations.WithLabelValues(result).Inc()
+	return nil
+}
+
+// find counts a user code attempt against the user's limit and returns the device's key
+func (u *DeviceUsecase) find(ctx context.Context, userId int, userCode string) (string, error) {
+	err := u.codeLimiter.CheckOTPRateLimit(ctx, strconv.Itoa(userId))
+	if err != nil {
+		return "", err
+	}
+	deviceHash, err := u.redisClient.WithContext(ctx).Get(userCodeKey(normalizeUserCode(userCode))).Result()
+	if err == redis.Nil {
+		return "", service_errors.New(service_errors.CodeUserCodeInvalid)
+	} else if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return deviceCodeKey(deviceHash), nil
+}
+
+func generateUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	alphabetSize := big.NewInt(int64(len(userCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		code[i] = userCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// formatUserCode splits the code in two halves, BCDF-GHJK, for reading aloud and typing
+func formatUserCode(code string) string {
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+// normalizeUserCode accepts the code typed in lower case or with the dash and spaces
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashCode keeps device codes, which are bearer secrets, out of Redis keys
+func hashCode(deviceCode string) string {
+	sum := sha256.Sum256([]byte(deviceCode))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func deviceCodeKey(deviceHash string) string {
+	return fmt.Sprintf("%s:%s", deviceCodeKeyPrefix, deviceHash)
+}
+
+func userCodeKey(userCode string) string {
+	return fmt.Sprintf("%s:%s", userCodeKeyPrefix, userCode)
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+)
+
+func pollRequest(clientId string, deviceCode string) dto.TokenRequest {
+	return dto.TokenRequest{GrantType: DeviceCodeGrantType, ClientId: clientId, DeviceCode: deviceCode}
+}
+
+func TestDevicePoll(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOAuth(t)
+	device, err := o.device.Authorize(ctx, "cli", "", "openid", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	poll := pollRequest("cli", device.DeviceCode)
+
+	if _, err = o.token.Token(ctx, poll); oauthErrorCode(err) != ErrorAuthorizationPending {
+		t.Fatalf("first poll: got %v, want authorization_pending", err)
+	}
+	// Polling again within the interval is told to slow down
+	if _, err = o.token.Token(ctx, poll); oauthErrorCode(err) != ErrorSlowDown {
+		t.Fatalf("early poll: got %v, want slow_down", err)
+	}
+	if _, err = o.token.Token(ctx, pollRequest("other", device.DeviceCode)); oauthErrorCode(err) != ErrorInvalidGrant {
+		t.Errorf("other client: got %v, want invalid_grant", err)
+	}
+
+	// The user types the code in lower case without the dash
+	typed := strings.ToLower(strings.ReplaceAll(device.UserCode, "-", ""))
+	if err = o.device.Answer(ctx, testUserId, typed, true); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	response, err := o.token.Token(ctx, poll)
+	if err != nil || response.AccessToken == "" {
+		t.Fatalf("poll after approval: got %+v, %v; want tokens", response, err)
+	}
+	if _, err = o.token.Token(ctx, poll); oauthErrorCode(err) != ErrorInvalidGrant {
+		t.Errorf("poll after the tokens were collected: got %v, want invalid_grant", err)
+	}
+}
+
+func TestDevicePollSlowDownRaisesInterval(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOAuth(t)
+	device, err := o.device.Authorize(ctx, "cli", "", "", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	poll := pollRequest("cli", device.DeviceCode)
+	for i := 0; i < 3; i++ {
+		o.token.Token(ctx, poll)
+	}
+	state, err := o.device.redisClient.Get(deviceCodeKey(hashCode(device.DeviceCode))).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two early polls add slowDownStep twice
+	if want := `"Interval":15`; !strings.Contains(state, want) {
+		t.Errorf("got state %s, want %s", state, want)
+	}
+}
+
+func TestDevicePollDenied(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOAuth(t)
+	device, err := o.device.Authorize(ctx, "cli", "", "", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = o.device.Answer(ctx, testUserId, device.UserCode, false); err != nil {
+		t.Fatalf("deny: %v", err)
+	}
+	if err = o.device.Answer(ctx, testUserId, device.UserCode, true); err == nil {
+		t.Error("second answer: got nil, want an error")
+	}
+	if _, err = o.token.Token(ctx, pollRequest("cli", device.DeviceCode)); oauthErrorCode(err) != ErrorAccessDenied {
+		t.Errorf("poll: got %v, want access_denied", err)
+	}
+}
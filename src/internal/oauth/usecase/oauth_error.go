@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Error codes of the token endpoint (RFC 6749 section 5.2 and RFC 8628 section 3.5), the
+// authorization endpoint (RFC 6749 section 4.1.2.1 and OpenID Connect Core section 3.1.2.6) and
+// the userinfo endpoint (RFC 6750 section 3.1)
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorInvalidToken            = "invalid_token"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+	ErrorAuthorizationPending    = "authorization_pending"
+	ErrorSlowDown                = "slow_down"
+	ErrorAccessDenied            = "access_denied"
+	ErrorExpiredToken            = "expired_token"
+	ErrorLoginRequired           = "login_required"
+	ErrorServerError             = "server_error"
+)
+
+// Error is an OAuth error that clients branch on by Code, so handlers answer it in the OAuth
+// format rather than the response envelope
+type Error struct {
+	Code        string
+	Description string
+}
+
+func newError(code string, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// StatusCode is 401 for an unknown client or access token and 400 otherwise, as RFC 6749 and
+// RFC 6750 prescribe
+func (e *Error) StatusCode() int {
+	if e.Code == ErrorInvalidClient || e.Code == ErrorInvalidToken {
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
+
+// RedirectError is an authorization error the client learns about through its redirect URI.
+// Errors found before the redirect URI is trusted are shown to the user instead.
+type RedirectError struct {
+	RedirectUri string
+	Err         *Error
+}
+
+func (e *RedirectError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RedirectError) Unwrap() error {
+	return e.Err
+}
+
+// redirectWith adds params to redirectUri, keeping any query it was registered with
+func redirectWith(redirectUri string, params map[string]string) (string, error) {
+	uri, err := url.Parse(redirectUri)
+	if err != nil {
+		return "", err
+	}
+	query := uri.Query()
+	for name, value := range params {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String(), nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/repository"
+	userDto "github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	userModels "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	userRepository "github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/internal/user/entity"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+)
+
+const (
+	AuthorizationCodeGrantType = "authorization_code"
+	DeviceCodeGrantType        = "urn:ietf:params:oauth:grant-type:device_code"
+	RefreshTokenGrantType      = "refresh_token"
+)
+
+// TokenUsecase answers the token endpoint, handing each grant to the flow it belongs to
+type TokenUsecase struct {
+	clientRepo    repository.ClientRepository
+	token         auth.TokenProvider
+	device        *DeviceUsecase
+	authorization *AuthorizationUsecase
+}
+
+func NewTokenUsecase(clientRepo repository.ClientRepository, token auth.TokenProvider, device *DeviceUsecase, authorization *AuthorizationUsecase) *TokenUsecase {
+	return &TokenUsecase{clientRepo: clientRepo, token: token, device: device, authorization: authorization}
+}
+
+// Token authenticates the client and answers its grant
+func (u *TokenUsecase) Token(ctx context.Context, req dto.TokenRequest) (_ dto.TokenResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TokenUsecase.Token")
+	defer tracing.End(span, &err)
+
+	client, err := authenticateClient(ctx, u.clientRepo, req.ClientId, req.ClientSecret)
+	if err != nil {
+		return dto.TokenResponse{}, err
+	}
+	switch req.GrantType {
+	case AuthorizationCodeGrantType:
+		if req.Code == "" || req.RedirectUri == "" || req.CodeVerifier == "" {
+			return dto.TokenResponse{}, newError(ErrorInvalidRequest, "code, redirect_uri and code_verifier are required")
+		}
+		return u.authorization.exchange(ctx, client, req.Code, req.RedirectUri, req.CodeVerifier)
+	case DeviceCodeGrantType:
+		if req.DeviceCode == "" {
+			return dto.TokenResponse{}, newError(ErrorInvalidRequest, "device_code is required")
+		}
+		return u.device.poll(ctx, client.ClientId, req.DeviceCode)
+	case RefreshTokenGrantType:
+		if req.RefreshToken == "" {
+			return dto.TokenResponse{}, newError(ErrorInvalidRequest, "refresh_token is required")
+		}
+		token, err := u.token.RefreshToken(ctx, req.RefreshToken, client.ClientId)
+		if err != nil {
+			return dto.TokenResponse{}, newError(ErrorInvalidGrant, "refresh_token is invalid or expired")
+		}
+		return toTokenResponse(token), nil
+	default:
+		return dto.TokenResponse{}, newError(ErrorUnsupportedGrantType, "")
+	}
+}
+
+// issueTokens returns the tokens of the user who approved a device or signed in for a client.
+// The user passed any second factor when logging in, so none is asked for. The tokens are bound
+// to clientId and carry no roles, so the first-party API refuses them and a client can only renew
+// its own.
+func issueTokens(ctx context.Context, userRepo userRepository.UserRepository, token auth.TokenProvider, clientId string, userId int) (*userDto.TokenDetail, userModels.User, error) {
+	user, err := userRepo.FetchUserInfoById(ctx, userId)
+	if err != nil {
+		return nil, userModels.User{}, err
+	}
+	payload := entity.TokenPayload{UserId: user.Id, MobileNumber: user.MobileNumber, Email: user.Email, ClientId: clientId}
+	tokens, err := token.GenerateToken(ctx, &payload)
+	if err != nil {
+		return nil, userModels.User{}, err
+	}
+	metrics.TokensIssued.Inc()
+	return tokens, user, nil
+}
+
+func toTokenResponse(token *userDto.TokenDetail) dto.TokenResponse {
+	return dto.TokenResponse{
+		AccessToken:  token.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    token.AccessTokenExpireTime - time.Now().Unix(),
+		RefreshToken: token.RefreshToken,
+	}
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type CreatePhonePolicyRequest struct {
+	Kind   string `json:"kind" binding:"required,oneof=allow_country allow_number block_prefix block_number"`
+	Value  string `json:"value" binding:"required,max=32"`
+	Reason string `json:"reason" binding:"max=255"`
+	// ExpiresAt makes the rule temporary; omit it for a permanent rule
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type PhonePolicy struct {
+	Id        int        `json:"id"`
+	Kind      string     `json:"kind"`
+	Value     string     `json:"value"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	CreatedBy int        `json:"created_by"`
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alielmi98/golang-otp-auth/di"
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/usecase"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+type PhonePolicyHandler struct {
+	usecase *usecase.PhonePolicyUsecase
+}
+
+func NewPhonePolicyHandler(cfg *config.Config) *PhonePolicyHandler {
+	return &PhonePolicyHandler{usecase: di.GetPhonePolicyUsecase(cfg)}
+}
+
+// CreatePhonePolicy godoc
+// @Summary Create a phone policy rule
+// @Description Allow a country or number, or block a prefix or number, permanently or until expires_at
+// @Tags Admin
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.CreatePhonePolicyRequest true "CreatePhonePolicyRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.PhonePolicy} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 403 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/admin/phone-policies [post]
+// @Security AuthBearer
+func (h *PhonePolicyHandler) CreatePhonePolicy(c *gin.Context) {
+	req := new(dto.CreatePhonePolicyRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	userId, _ := c.Value(constants.UserIdKey).(float64)
+	policy, err := h.usecase.CreatePhonePolicy(c.Request.Context(), req, int(userId))
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(policy, true, helper.Success))
+}
+
+// GetPhonePolicies godoc
+// @Summary List phone policy rules
+// @Description List rules, including expired ones, optionally filtered by kind
+// @Tags Admin
+// @Produce  json
+// @Param kind query string false "allow_country, allow_number, block_prefix or block_number"
+// @Success 200 {object} helper.BaseHttpResponse{result=[]dto.PhonePolicy} "Success"
+// @Failure 403 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/admin/phone-policies [get]
+// @Security AuthBearer
+func (h *PhonePolicyHandler) GetPhonePolicies(c *gin.Context) {
+	policies, err := h.usecase.GetPhonePolicies(c.Request.Context(), c.Query("kind"))
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(policies, true, helper.Success))
+}
+
+// DeletePhonePolicy godoc
+// @Summary Delete a phone policy rule
+// @Description Delete a rule; every instance reloads its cached rules
+// @Tags Admin
+// @Produce  json
+// @Param id path int true "Rule id"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 404 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/admin/phone-policies/{id} [delete]
+// @Security AuthBearer
+func (h *PhonePolicyHandler) DeletePhonePolicy(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.usecase.DeletePhonePolicy(c.Request.Context(), id)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/api/handler"
+	"github.com/gin-gonic/gin"
+)
+
+func PhonePolicies(router *gin.RouterGroup, handler *handler.PhonePolicyHandler) {
+
+	router.GET("", handler.GetPhonePolicies)
+	router.POST("", handler.CreatePhonePolicy)
+	router.DELETE("/:id", handler.DeletePhonePolicy)
+
+}
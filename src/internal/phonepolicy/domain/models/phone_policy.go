@@ -0,0 +1,33 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Rule kinds; allow_number exempts a number from every block rule
+const (
+	KindAllowCountry = "allow_country"
+	KindAllowNumber  = "allow_number"
+	KindBlockPrefix  = "block_prefix"
+	KindBlockNumber  = "block_number"
+)
+
+// PhonePolicy is one admin-managed rule. Value is an ISO 3166 region for allow_country, an
+// E.164 prefix such as +99 for block_prefix and an E.164 number otherwise.
+type PhonePolicy struct {
+	Id     int    `gorm:"primarykey"`
+	Kind   string `gorm:"type:string;size:20;not null;index"`
+	Value  string `gorm:"type:string;size:16;not null"`
+	Reason string `gorm:"type:string;size:255;null"`
+	// ExpiresAt ends a temporary rule; permanent rules leave it null
+	ExpiresAt sql.NullTime `gorm:"type:TIMESTAMP with time zone;null;index"`
+
+	CreatedAt time.Time `gorm:"type:TIMESTAMP with time zone;not null"`
+	CreatedBy int       `gorm:"not null"`
+}
+
+// Active reports whether the rule applies at now
+func (p PhonePolicy) Active(now time.Time) bool {
+	return !p.ExpiresAt.Valid || p.ExpiresAt.Time.After(now)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/domain/models"
+)
+
+type PhonePolicyRepository interface {
+	CreatePhonePolicy(ctx context.Context, policy models.PhonePolicy) (models.PhonePolicy, error)
+	DeletePhonePolicy(ctx context.Context, id int) error
+	// GetActivePhonePolicies returns the rules that are permanent or not yet expired
+	GetActivePhonePolicies(ctx context.Context) ([]models.PhonePolicy, error)
+	GetPhonePolicies(ctx context.Context, kind string) ([]models.PhonePolicy, error)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"gorm.io/gorm"
+)
+
+const activePolicyFilterExp string = "expires_at IS NULL OR expires_at > ?"
+
+type PhonePolicyPgRepo struct {
+	db     *gorm.DB
+	logger logging.Logger
+}
+
+func NewPhonePolicyPgRepo() *PhonePolicyPgRepo {
+	return &PhonePolicyPgRepo{db: db.GetDb(), logger: logging.GetLogger()}
+}
+
+func (r *PhonePolicyPgRepo) CreatePhonePolicy(ctx context.Context, policy models.PhonePolicy) (models.PhonePolicy, error) {
+	defer metrics.ObservePostgres("create_phone_policy", time.Now())
+	if err := r.db.WithContext(ctx).Create(&policy).Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Insert, "create phone policy failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return policy, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return policy, nil
+}
+
+func (r *PhonePolicyPgRepo) DeletePhonePolicy(ctx context.Context, id int) error {
+	defer metrics.ObservePostgres("delete_phone_policy", time.Now())
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.PhonePolicy{})
+	if result.Error != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Delete, "delete phone policy failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: result.Error.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return service_errors.New(service_errors.CodeRecordNotFound)
+	}
+	return nil
+}
+
+func (r *PhonePolicyPgRepo) GetActivePhonePolicies(ctx context.Context) ([]models.PhonePolicy, error) {
+	defer metrics.ObservePostgres("get_active_phone_policies", time.Now())
+	var policies []models.PhonePolicy
+	err := r.db.WithContext(ctx).Where(activePolicyFilterExp, time.Now()).Find(&policies).Error
+	if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "get active phone policies failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return nil, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return policies, nil
+}
+
+func (r *PhonePolicyPgRepo) GetPhonePolicies(ctx context.Context, kind string) ([]models.PhonePolicy, error) {
+	defer metrics.ObservePostgres("get_phone_policies", time.Now())
+	var policies []models.PhonePolicy
+	query := r.db.WithContext(ctx).Order("id DESC")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if err := query.Find(&policies).Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "get phone policies failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return nil, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return policies, nil
+}
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/go-redis/redis/v7"
+)
+
+// invalidationChannel tells every instance to drop its cached rules after an admin change
+const invalidationChannel = "phone_policy:invalidate"
+
+var prefixPattern = regexp.MustCompile(`^\+[0-9]{1,15}$`)
+
+// PhonePolicyUsecase manages the rules and checks numbers against an in-memory copy of them.
+// The copy is reloaded after cfg.PhonePolicy.CacheTtl or as soon as any instance changes a rule.
+type PhonePolicyUsecase struct {
+	cfg         *config.Config
+	repo        repository.PhonePolicyRepository
+	redisClient *redis.Client
+	logger      logging.Logger
+
+	mu       sync.RWMutex
+	rules    map[string][]models.PhonePolicy
+	loadedAt time.Time
+	loadMu   sync.Mutex
+}
+
+func NewPhonePolicyUsecase(cfg *config.Config, repo repository.PhonePolicyRepository) *PhonePolicyUsecase {
+	u := &PhonePolicyUsecase{
+		cfg:         cfg,
+		repo:        repo,
+		redisClient: cache.GetRedis(),
+		logger:      logging.GetLogger(),
+	}
+	go u.listenInvalidations()
+	return u
+}
+
+// CheckPhoneNumber applies, in order: allowed numbers, blocked numbers, blocked prefixes and
+// allowed countries. With no allow_country rule every country is allowed.
+func (u *PhonePolicyUsecase) CheckPhoneNumber(ctx context.Context, mobileNumber string) (err error) {
+	ctx, span := tracing.Start(ctx, "PhonePolicyUsecase.CheckPhoneNumber")
+	defer tracing.End(span, &err)
+
+	rules, err := u.activeRules(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	matches := func(kind string, match func(value string) bool) bool {
+		for _, rule := range rules[kind] {
+			if rule.Active(now) && match(rule.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	isNumber := func(value string) bool { return value == mobileNumber }
+
+	if matches(models.KindAllowNumber, isNumber) {
+		return nil
+	}
+	if matches(models.KindBlockNumber, isNumber) {
+		return u.reject(models.KindBlockNumber, service_errors.CodePhoneBlocked)
+	}
+	if matches(models.KindBlockPrefix, func(value string) bool { return strings.HasPrefix(mobileNumber, value) }) {
+		return u.reject(models.KindBlockPrefix, service_errors.CodePhoneBlocked)
+	}
+	if matches(models.KindAllowCountry, func(string) bool { return true }) {
+		phone, err := common.ParsePhoneNumber(mobileNumber, u.cfg.Phone.DefaultRegion)
+		if err != nil || !matches(models.KindAllowCountry, func(value string) bool { return value == phone.Region }) {
+			return u.reject(models.KindAllowCountry, service_errors.CodeCountryNotAllowed)
+		}
+	}
+	return nil
+}
+
+func (u *PhonePolicyUsecase) reject(kind string, code service_errors.ErrorCode) error {
+	metrics.PhonePolicyRejections.WithLabelValues(kind).Inc()
+	return service_errors.New(code)
+}
+
+func (u *PhonePolicyUsecase) CreatePhonePolicy(ctx context.Context, req *dto.CreatePhonePolicyRequest, createdBy int) (_ dto.PhonePolicy, err error) {
+	ctx, span := tracing.Start(ctx, "PhonePolicyUsecase.CreatePhonePolicy")
+	defer tracing.End(span, &err)
+
+	value, err := u.normalizeValue(req.Kind, req.Value)
+	if err != nil {
+		return dto.PhonePolicy{}, err
+	}
+	policy := models.PhonePolicy{Kind: req.Kind, Value: value, Reason: req.Reason, CreatedBy: createdBy}
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			serviceErr := service_errors.New(service_errors.CodeInvalidPhonePolicy)
+			serviceErr.TechnicalMessage = "expires_at is in the past"
+			return dto.PhonePolicy{}, serviceErr
+		}
+		policy.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	policy, err = u.repo.CreatePhonePolicy(ctx, policy)
+	if err != nil {
+		return dto.PhonePolicy{}, err
+	}
+	u.publishInvalidation(ctx)
+	return toDto(policy, time.Now()), nil
+}
+
+func (u *PhonePolicyUsecase) DeletePhonePolicy(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "PhonePolicyUsecase.DeletePhonePolicy")
+	defer tracing.End(span, &err)
+
+	if err = u.repo.DeletePhonePolicy(ctx, id); err != nil {
+		return err
+	}
+	u.publishInvalidation(ctx)
+	return nil
+}
+
+// GetPhonePolicies lists every rule of kind, or of all kinds, including expired ones
+func (u *PhonePolicyUsecase) GetPhonePolicies(ctx context.Context, kind string) (_ []dto.PhonePolicy, err error) {
+	ctx, span := tracing.Start(ctx, "PhonePolicyUsecase.GetPhonePolicies")
+	defer tracing.End(span, &err)
+
+	policies, err := u.repo.GetPhonePolicies(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := make([]dto.PhonePolicy, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, toDto(policy, now))
+	}
+	return result, nil
+}
+
+// normalizeValue stores numbers and prefixes in E.164 and countries as upper-case ISO 3166 codes
+func (u *PhonePolicyUsecase) normalizeValue(kind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case models.KindAllowCountry:
+		region := strings.ToUpper(value)
+		if common.IsPhoneRegion(region) {
+			return region, nil
+		}
+	case models.KindBlockPrefix:
+		prefix := strings.ReplaceAll(value, " ", "")
+		if strings.HasPrefix(prefix, "00") {
+			prefix = "+" + strings.TrimPrefix(prefix, "00")
+		}
+		if prefixPattern.MatchString(prefix) {
+			return prefix, nil
+		}
+	case models.KindAllowNumber, models.KindBlockNumber:
+		phone, err := common.ParsePhoneNumber(value, u.cfg.Phone.DefaultRegion)
+		if err == nil {
+			return phone.E164, nil
+		}
+	}
+	serviceErr := service_errors.New(service_errors.CodeInvalidPhonePolicy)
+	serviceErr.TechnicalMessage = "invalid value for " + kind
+	return "", serviceErr
+}
+
+// activeRules returns the cached rules, reloading them when stale. If the reload fails the
+// previous rules stay in use, so a database hiccup does not disable the blocklist.
+func (u *PhonePolicyUsecase) activeRules(ctx context.Context) (map[string][]models.PhonePolicy, error) {
+	if rules, fresh := u.cached(); fresh {
+		return rules, nil
+	}
+
+	u.loadMu.Lock()
+	defer u.loadMu.Unlock()
+	rules, fresh := u.cached()
+	if fresh {
+		return rules, nil
+	}
+
+	policies, err := u.repo.GetActivePhonePolicies(ctx)
+	if err != nil {
+		if rules != nil {
+			u.logger.WithContext(ctx).Warn(constants.Postgres, constants.Select, "using stale phone policies",
+				map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+			return rules, nil
+		}
+		return nil, err
+	}
+	rules = make(map[string][]models.PhonePolicy)
+	for _, policy := range policies {
+		rules[policy.Kind] = append(rules[policy.Kind], policy)
+	}
+
+	u.mu.Lock()
+	u.rules, u.loadedAt = rules, time.Now()
+	u.mu.Unlock()
+	return rules, nil
+}
+
+func (u *PhonePolicyUsecase) cached() (map[string][]models.PhonePolicy, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	fresh := u.rules != nil && time.Since(u.loadedAt) < u.cfg.PhonePolicy.CacheTtl*time.Second
+	return u.rules, fresh
+}
+
+// invalidate marks the cache stale; the rules are kept as the fallback for a failed reload
+func (u *PhonePolicyUsecase) invalidate() {
+	u.mu.Lock()
+	u.loadedAt = time.Time{}
+	u.mu.Unlock()
+}
+
+func (u *PhonePolicyUsecase) publishInvalidation(ctx context.Context) {
+	u.invalidate()
+	if err := u.redisClient.WithContext(ctx).Publish(invalidationChannel, time.Now().Unix()).Err(); err != nil {
+		u.logger.WithContext(ctx).Warn(constants.Redis, constants.Publish, "publish phone policy invalidation failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+}
+
+// listenInvalidations runs for the life of the process; the subscription reconnects on its own
+func (u *PhonePolicyUsecase) listenInvalidations() {
+	pubsub := u.redisClient.Subscribe(invalidationChannel)
+	for range pubsub.Channel() {
+		u.invalidate()
+	}
+}
+
+func toDto(policy models.PhonePolicy, now time.Time) dto.PhonePolicy {
+	result := dto.PhonePolicy{
+		Id:        policy.Id,
+		Kind:      policy.Kind,
+		Value:     policy.Value,
+		Reason:    policy.Reason,
+		Active:    policy.Active(now),
+		CreatedAt: policy.CreatedAt,
+		CreatedBy: policy.CreatedBy,
+	}
+	if policy.ExpiresAt.Valid {
+		expiresAt := policy.ExpiresAt.Time
+		result.ExpiresAt = &expiresAt
+	}
+	return result
+}
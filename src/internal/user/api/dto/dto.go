@@ -1,28 +1,196 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type GetOtpRequest struct {
-	MobileNumber string `json:"mobile_number" binding:"required,mobile,min=11,max=11"`
+	MobileNumber string `json:"mobile_number" binding:"required,max=32,mobile"`
 }
 type RegisterLoginByMobileRequest struct {
-	MobileNumber string `json:"mobileNumber" binding:"required,mobile,min=11,max=11"`
+	MobileNumber string `json:"mobileNumber" binding:"required,max=32,mobile"`
 	Otp          string `json:"otp" binding:"required,min=6,max=6"`
 }
 
+// LoginRequest identifies the user by mobile number or by email, whichever the code was sent to
+type LoginRequest struct {
+	MobileNumber string `json:"mobile_number" binding:"required_without=Email,excluded_with=Email,omitempty,max=32,mobile"`
+	Email        string `json:"email" binding:"required_without=MobileNumber,omitempty,max=254,email"`
+	Otp          string `json:"otp" binding:"required,min=6,max=6"`
+}
+
+// SendOtpRequest sends the code by SMS to mobile_number or by email to email; exactly one is expected
 type SendOtpRequest struct {
-	MobileNumber string `json:"mobile_number" binding:"required,mobile,min=11,max=11"`
+	MobileNumber string `json:"mobile_number" binding:"required_without=Email,excluded_with=Email,omitempty,max=32,mobile"`
+	Email        string `json:"email" binding:"required_without=MobileNumber,omitempty,max=254,email"`
+	// Channel picks "sms" (default) or "voice" for mobile numbers
+	Channel string `json:"channel" binding:"omitempty,excluded_with=Email,oneof=sms voice"`
+	// ChallengeResponse is the solved proof of work or the CAPTCHA token, sent after CHALLENGE_REQUIRED
+	ChallengeResponse string `json:"challenge_response" binding:"max=4096"`
+}
+
+// SendOtpResponse names the channel the code went out on, which differs from the requested
+// one when a failed SMS fell back to a voice call
+type SendOtpResponse struct {
+	Channel string `json:"channel"`
+}
+
+// ChallengeResponse carries a proof-of-work challenge, or the site key for a CAPTCHA provider
+type ChallengeResponse struct {
+	Provider   string     `json:"provider"`
+	Challenge  string     `json:"challenge,omitempty"`
+	Difficulty int        `json:"difficulty,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	SiteKey    string     `json:"site_key,omitempty"`
 }
 type UserInfo struct {
 	ID           int       `json:"id"`
-	MobileNumber string    `json:"mobile_number"`
+	MobileNumber string    `json:"mobile_number,omitempty"`
+	Email        string    `json:"email,omitempty"`
 	RegisteredAt time.Time `json:"registered_at"`
 }
+
+// TokenDetail carries the tokens, or, when MfaRequired is set, only the MfaToken to finish the
+// login with one of MfaMethods
 type TokenDetail struct {
-	AccessToken            string `json:"accessToken"`
-	RefreshToken           string `json:"refreshToken"`
-	AccessTokenExpireTime  int64  `json:"accessTokenExpireTime"`
-	RefreshTokenExpireTime int64  `json:"refreshTokenExpireTime"`
+	AccessToken            string   `json:"accessToken"`
+	RefreshToken           string   `json:"refreshToken"`
+	AccessTokenExpireTime  int64    `json:"accessTokenExpireTime"`
+	RefreshTokenExpireTime int64    `json:"refreshTokenExpireTime"`
+	MfaRequired            bool     `json:"mfaRequired,omitempty"`
+	MfaToken               string   `json:"mfaToken,omitempty"`
+	MfaMethods             []string `json:"mfaMethods,omitempty"`
+}
+
+// TotpEnrollment is shown once; QrPng is a base64 PNG of OtpauthUri for authenticator apps to scan
+type TotpEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+	QrPng      string `json:"qr_png"`
+}
+
+type TotpCodeRequest struct {
+	Code string `json:"code" binding:"required,min=6,max=8,numeric"`
+}
+
+// SecondFactorRequest proves the second factor with an authenticator code or a recovery code
+type SecondFactorRequest struct {
+	Code         string `json:"code" binding:"required_without=RecoveryCode,excluded_with=RecoveryCode,omitempty,min=6,max=8,numeric"`
+	RecoveryCode string `json:"recovery_code" binding:"required_without=Code,omitempty,max=32"`
+}
+
+// SecondFactorLoginRequest finishes a login that answered mfaRequired
+type SecondFactorLoginRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required,max=128"`
+	SecondFactorRequest
+}
+
+// RecoveryCodes lists Codes only right after they were generated
+type RecoveryCodes struct {
+	Codes     []string `json:"codes,omitempty"`
+	Remaining int      `json:"remaining"`
+}
+
+type PasswordLoginRequest struct {
+	MobileNumber string `json:"mobile_number" binding:"required_without=Email,excluded_with=Email,omitempty,max=32,mobile"`
+	Email        string `json:"email" binding:"required_without=MobileNumber,omitempty,max=254,email"`
+	Password     string `json:"password" binding:"required,max=256"`
+}
+
+// SetPasswordRequest needs CurrentPassword only when the user already has a password
+type SetPasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"omitempty,max=256"`
+	Password        string `json:"password" binding:"required,max=256"`
+}
+
+// PasswordResetRequest carries the code send-otp sent to the mobile number or email
+type PasswordResetRequest struct {
+	MobileNumber string `json:"mobile_number" binding:"required_without=Email,excluded_with=Email,omitempty,max=32,mobile"`
+	Email        string `json:"email" binding:"required_without=MobileNumber,omitempty,max=254,email"`
+	Otp          string `json:"otp" binding:"required,min=6,max=6"`
+	Password     string `json:"password" binding:"required,max=256"`
+}
+
+// MagicLinkRequest sends a login link by SMS to mobile_number or by email to email; exactly one is expected
+type MagicLinkRequest struct {
+	MobileNumber string `json:"mobile_number" binding:"required_without=Email,excluded_with=Email,omitempty,max=32,mobile"`
+	Email        string `json:"email" binding:"required_without=MobileNumber,omitempty,max=254,email"`
+	// ChallengeResponse is the solved proof of work or the CAPTCHA token, sent after CHALLENGE_REQUIRED
+	ChallengeResponse string `json:"challenge_response" binding:"max=4096"`
+}
+
+// MagicLinkResponse names the channel the link went out on. DeviceToken is set when links are
+// bound to the requesting device; the client keeps it and sends it back with the link's token.
+type MagicLinkResponse struct {
+	Channel     string    `json:"channel"`
+	DeviceToken string    `json:"device_token,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// MagicLinkLoginRequest carries the token query parameter of the opened link
+type MagicLinkLoginRequest struct {
+	Token       string `json:"token" binding:"required,max=1024"`
+	DeviceToken string `json:"device_token" binding:"max=64"`
+}
+
+// QrLogin is a pending desktop login. The desktop shows QrPng, which encodes QrPayload, and keeps
+// PollToken to itself; only the poll with it receives the tokens.
+type QrLogin struct {
+	Code      string    `json:"code"`
+	PollToken string    `json:"poll_token"`
+	QrPayload string    `json:"qr_payload"`
+	QrPng     string    `json:"qr_png"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type QrLoginPollRequest struct {
+	Code      string `json:"code" binding:"required,max=64"`
+	PollToken string `json:"poll_token" binding:"required,max=64"`
+}
+
+// QrLoginStatus is pending, approved or denied; Token is set once approved
+type QrLoginStatus struct {
+	Status string       `json:"status"`
+	Token  *TokenDetail `json:"token,omitempty"`
+}
+
+// QrLoginInfo describes the desktop that asks to log in, for the phone to show before answering
+type QrLoginInfo struct {
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type QrLoginAnswerRequest struct {
+	Code string `json:"code" binding:"required,max=64"`
+}
+
+// PasskeyOptions is what the browser passes to navigator.credentials.create or get. SessionId
+// identifies a login ceremony and is sent back with its response.
+type PasskeyOptions struct {
+	SessionId string          `json:"session_id,omitempty"`
+	Options   json.RawMessage `json:"options" swaggertype:"object"`
+}
+
+// PasskeyRegistrationRequest carries the PublicKeyCredential navigator.credentials.create returned
+type PasskeyRegistrationRequest struct {
+	Name       string          `json:"name" binding:"required,max=64"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+// PasskeyLoginRequest carries the PublicKeyCredential navigator.credentials.get returned
+type PasskeyLoginRequest struct {
+	SessionId  string          `json:"session_id" binding:"required,max=64"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+type Passkey struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 }
 
 type UserList struct {
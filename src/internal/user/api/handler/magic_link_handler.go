@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// SendMagicLink godoc
+// @Summary Send a login link
+// @Description Send a one-time login link by SMS to mobile_number or by email to email. When links are bound to the device, keep device_token on this device and send it with the link's token.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.MagicLinkRequest true "MagicLinkRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.MagicLinkResponse} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Failure 429 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/magic-link [post]
+func (h *UsersHandler) SendMagicLink(c *gin.Context) {
+	req := new(dto.MagicLinkRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	sent, err := h.otpUsecase.SendMagicLink(c.Request.Context(), req.MobileNumber, req.Email, c.ClientIP(), req.ChallengeResponse)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(sent, true, helper.Success))
+}
+
+// LoginByMagicLink godoc
+// @Summary Login with a login link
+// @Description Spend the token of an opened login link and return tokens, registering the user first when the identifier is new; users with a second factor get an mfaToken instead of tokens
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.MagicLinkLoginRequest true "MagicLinkLoginRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.TokenDetail} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Failure 403 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/login/magic-link [post]
+func (h *UsersHandler) LoginByMagicLink(c *gin.Context) {
+	req := new(dto.MagicLinkLoginRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	token, err := h.usecase.LoginByMagicLink(c.Request.Context(), req.Token, req.DeviceToken)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(token, true, helper.Success))
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// BeginPasskeyRegistration godoc
+// @Summary Start registering a passkey
+// @Description Return the options to pass to navigator.credentials.create
+// @Tags Passkeys
+// @Produce  json
+// @Security AuthBearer
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.PasskeyOptions} "Success"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/passkeys/register/begin [post]
+func (h *UsersHandler) BeginPasskeyRegistration(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	options, err := h.passkeyUsecase.BeginRegistration(c.Request.Context(), userId)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(options, true, helper.Success))
+}
+
+// FinishPasskeyRegistration godoc
+// @Summary Finish registering a passkey
+// @Description Verify the credential navigator.credentials.create returned and store it under a name
+// @Tags Passkeys
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.PasskeyRegistrationRequest true "PasskeyRegistrationRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.Passkey} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/passkeys/register/finish [post]
+func (h *UsersHandler) FinishPasskeyRegistration(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.PasskeyRegistrationRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	passkey, err := h.passkeyUsecase.FinishRegistration(c.Request.Context(), userId, req.Name, req.Credential)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(passkey, true, helper.Success))
+}
+
+// GetPasskeys godoc
+// @Summary List the user's passkeys
+// @Tags Passkeys
+// @Produce  json
+// @Security AuthBearer
+// @Success 200 {object} helper.BaseHttpResponse{result=[]dto.Passkey} "Success"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/passkeys [get]
+func (h *UsersHandler) GetPasskeys(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	passkeys, err := h.passkeyUsecase.GetPasskeys(c.Request.Context(), userId)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(passkeys, true, helper.Success))
+}
+
+// DeletePasskey godoc
+// @Summary Remove a passkey
+// @Tags Passkeys
+// @Produce  json
+// @Security AuthBearer
+// @Param id path int true "Passkey id"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 404 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/passkeys/{id} [delete]
+func (h *UsersHandler) DeletePasskey(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.passkeyUsecase.DeletePasskey(c.Request.Context(), userId, id)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
+
+// BeginPasskeyLogin godoc
+// @Summary Start a passkey login
+// @Description Return the options to pass to navigator.credentials.get and the session_id to send back with its result
+// @Tags Passkeys
+// @Produce  json
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.PasskeyOptions} "Success"
+// @Router /v1/users/passkeys/login/begin [post]
+func (h *UsersHandler) BeginPasskeyLogin(c *gin.Context) {
+	options, err := h.passkeyUsecase.BeginLogin(c.Request.Context())
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(options, true, helper.Success))
+}
+
+// FinishPasskeyLogin godoc
+// @Summary Log in with a passkey
+// @Description Verify the assertion navigator.credentials.get returned and issue tokens
+// @Tags Passkeys
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.PasskeyLoginRequest true "PasskeyLoginRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.TokenDetail} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/passkeys/login/finish [post]
+func (h *UsersHandler) FinishPasskeyLogin(c *gin.Context) {
+	req := new(dto.PasskeyLoginRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	token, err := h.passkeyUsecase.Login(c.Request.Context(), req.SessionId, req.Credential)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(token, true, helper.Success))
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginByPassword godoc
+// @Summary Login with a password
+// @Description Login with the mobile number or email and the password the user set; users with a second factor get an mfaToken instead of tokens
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.PasswordLoginRequest true "PasswordLoginRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.TokenDetail} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Failure 429 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/login-by-password [post]
+func (h *UsersHandler) LoginByPassword(c *gin.Context) {
+	req := new(dto.PasswordLoginRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	token, err := h.usecase.LoginByPassword(c.Request.Context(), req.MobileNumber, req.Email, req.Password, c.ClientIP())
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(token, true, helper.Success))
+}
+
+// SetPassword godoc
+// @Summary Set or change the password
+// @Description Set a password for password login; changing an existing one needs current_password
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.SetPasswordRequest true "SetPasswordRequest"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/password [put]
+func (h *UsersHandler) SetPassword(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.SetPasswordRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.usecase.SetPassword(c.Request.Context(), userId, req.CurrentPassword, req.Password)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
+
+// ResetPassword godoc
+// @Summary Reset a forgotten password
+// @Description Set a new password with the code send-otp sent to the mobile number or email
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.PasswordResetRequest true "PasswordResetRequest"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 404 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/password/reset [post]
+func (h *UsersHandler) ResetPassword(c *gin.Context) {
+	req := new(dto.PasswordResetRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.usecase.ResetPassword(c.Request.Context(), req.MobileNumber, req.Email, req.Otp, req.Password)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
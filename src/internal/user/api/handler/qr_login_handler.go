@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateQrLogin godoc
+// @Summary Start a QR login
+// @Description Create a pending desktop login. Show qr_png, keep poll_token and poll until a logged-in phone answers.
+// @Tags QR Login
+// @Produce  json
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.QrLogin} "Success"
+// @Failure 429 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/qr-login [post]
+func (h *UsersHandler) CreateQrLogin(c *gin.Context) {
+	login, err := h.qrLoginUsecase.Create(c.Request.Context(), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(login, true, helper.Success))
+}
+
+// PollQrLogin godoc
+// @Summary Wait for the answer to a QR login
+// @Description Long-poll until the phone answers or qrLogin.pollTimeout passes. status is pending, approved with the tokens, or denied; poll again while pending.
+// @Tags QR Login
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.QrLoginPollRequest true "QrLoginPollRequest"
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.QrLoginStatus} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/qr-login/poll [post]
+func (h *UsersHandler) PollQrLogin(c *gin.Context) {
+	req := new(dto.QrLoginPollRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	status, err := h.qrLoginUsecase.Poll(c.Request.Context(), req.Code, req.PollToken)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(status, true, helper.Success))
+}
+
+// GetQrLogin godoc
+// @Summary Describe a scanned QR login
+// @Description Return the address and browser of the desktop asking to log in, to show before approving
+// @Tags QR Login
+// @Produce  json
+// @Security AuthBearer
+// @Param code path string true "Code from the QR payload"
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.QrLoginInfo} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/qr-login/{code} [get]
+func (h *UsersHandler) GetQrLogin(c *gin.Context) {
+	if _, ok := currentUserId(c); !ok {
+		return
+	}
+	info, err := h.qrLoginUsecase.Get(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(info, true, helper.Success))
+}
+
+// ApproveQrLogin godoc
+// @Summary Approve a QR login
+// @Description Log the desktop that shows the QR code in as the current user
+// @Tags QR Login
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.QrLoginAnswerRequest true "QrLoginAnswerRequest"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/qr-login/approve [post]
+func (h *UsersHandler) ApproveQrLogin(c *gin.Context) {
+	h.answerQrLogin(c, true)
+}
+
+// DenyQrLogin godoc
+// @Summary Deny a QR login
+// @Description Refuse the desktop that shows the QR code
+// @Tags QR Login
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.QrLoginAnswerRequest true "QrLoginAnswerRequest"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/qr-login/deny [post]
+func (h *UsersHandler) DenyQrLogin(c *gin.Context) {
+	h.answerQrLogin(c, false)
+}
+
+func (h *UsersHandler) answerQrLogin(c *gin.Context, approve bool) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.QrLoginAnswerRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.qrLoginUsecase.Answer(c.Request.Context(), userId, req.Code, approve)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
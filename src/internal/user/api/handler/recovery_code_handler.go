@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRecoveryCodes godoc
+// @Summary Count the remaining recovery codes
+// @Description Return how many unused recovery codes the user has; the codes themselves are never shown again
+// @Tags Users
+// @Produce  json
+// @Security AuthBearer
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.RecoveryCodes} "Success"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/recovery-codes [get]
+func (h *UsersHandler) GetRecoveryCodes(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	codes, err := h.recoveryUsecase.Remaining(c.Request.Context(), userId)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(codes, true, helper.Success))
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate the recovery codes
+// @Description Replace every recovery code with a new set after proving the second factor with a TOTP code or a recovery code
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.SecondFactorRequest true "SecondFactorRequest"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.RecoveryCodes} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 404 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/recovery-codes [post]
+func (h *UsersHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.SecondFactorRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	codes, err := h.recoveryUsecase.Regenerate(c.Request.Context(), userId, req.Code, req.RecoveryCode)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(codes, true, helper.Success))
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/gin-gonic/gin"
+)
+
+// EnrollTotp godoc
+// @Summary Start authenticator app enrollment
+// @Description Create a TOTP secret and return it with its otpauth:// URI and a base64 QR PNG; confirm it with a first code
+// @Tags Users
+// @Produce  json
+// @Security AuthBearer
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.TotpEnrollment} "Success"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/totp [post]
+func (h *UsersHandler) EnrollTotp(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	enrollment, err := h.totpUsecase.Enroll(c.Request.Context(), userId)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(enrollment, true, helper.Success))
+}
+
+// ConfirmTotp godoc
+// @Summary Confirm authenticator app enrollment
+// @Description Activate the enrollment with the first code the app shows; logins then require a code. Returns the recovery codes, shown only this once.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.TotpCodeRequest true "TotpCodeRequest"
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.RecoveryCodes} "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 404 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/totp/confirm [post]
+func (h *UsersHandler) ConfirmTotp(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.TotpCodeRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	codes, err := h.totpUsecase.Confirm(c.Request.Context(), userId, req.Code)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(codes, true, helper.Success))
+}
+
+// DisableTotp godoc
+// @Summary Remove the authenticator app
+// @Description Remove the TOTP enrollment and the recovery codes; a confirmed one needs a current code or a recovery code
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Security AuthBearer
+// @Param Request body dto.SecondFactorRequest true "SecondFactorRequest"
+// @Success 200 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 404 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/totp [delete]
+func (h *UsersHandler) DisableTotp(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		return
+	}
+	req := new(dto.SecondFactorRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	err = h.totpUsecase.Disable(c.Request.Context(), userId, req.Code, req.RecoveryCode)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(nil, true, helper.Success))
+}
+
+// LoginSecondFactor godoc
+// @Summary Finish login with the second factor
+// @Description Exchange the mfaToken of a login that answered mfaRequired, plus a TOTP code or a recovery code, for tokens
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.SecondFactorLoginRequest true "SecondFactorLoginRequest"
+// @Success 201 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 401 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/login/totp [post]
+func (h *UsersHandler) LoginSecondFactor(c *gin.Context) {
+	req := new(dto.SecondFactorLoginRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	token, err := h.usecase.LoginSecondFactor(c.Request.Context(), req.MfaToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(token, true, helper.Success))
+}
+
+// currentUserId reads the user id the Authentication middleware took from the access token
+func currentUserId(c *gin.Context) (int, bool) {
+	userId, ok := c.Value(constants.UserIdKey).(float64)
+	if !ok {
+		err := service_errors.New(service_errors.CodeUserIdNotFound)
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err), helper.GenerateBaseResponseFromError(err))
+		return 0, false
+	}
+	return int(userId), true
+}
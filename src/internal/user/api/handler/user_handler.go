@@ -9,21 +9,67 @@ import (
 	"github.com/alielmi98/golang-otp-auth/internal/user/usecase"
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
 	"github.com/gin-gonic/gin"
 )
 
 type UsersHandler struct {
-	usecase    *usecase.UserUsecase
-	otpUsecase *usecase.OtpUsecase
+	usecase         *usecase.UserUsecase
+	otpUsecase      *usecase.OtpUsecase
+	totpUsecase     *usecase.TotpUsecase
+	recoveryUsecase *usecase.RecoveryCodeUsecase
+	passkeyUsecase  *usecase.PasskeyUsecase
+	qrLoginUsecase  *usecase.QrLoginUsecase
 }
 
 func NewUserHandler(cfg *config.Config) *UsersHandler {
 	otpProvider := di.GetOtpProvider(cfg)
+	magicLinkProvider := di.GetMagicLinkProvider(cfg)
 	rateLimitService := di.GetOTPRateLimitService(cfg)
-	userUsecase := usecase.NewUserUsecase(cfg, di.GetUserRepository(cfg), di.GetTokenProvider(cfg), otpProvider)
-	otpUsecase := usecase.NewOtpUsecase(cfg, otpProvider, rateLimitService)
+	auditRepo := di.GetOtpAuditRepository(cfg)
+	abuseDetector := di.GetAbuseUsecase(cfg)
+	userRepo := di.GetUserRepository(cfg)
+	totpRepo := di.GetTotpRepository(cfg)
+	totpProvider := di.GetTotpProvider(cfg)
+	totpLimiter := di.GetTotpRateLimitService(cfg)
+	recoveryRepo := di.GetRecoveryCodeRepository(cfg)
+	userUsecase := usecase.NewUserUsecase(cfg, usecase.UserDependencies{
+		Repo:              userRepo,
+		Token:             di.GetTokenProvider(cfg),
+		OtpProvider:       otpProvider,
+		MagicLinkProvider: magicLinkProvider,
+		AbuseDetector:     abuseDetector,
+		AuditRepo:         auditRepo,
+		TotpRepo:          totpRepo,
+		RecoveryRepo:      recoveryRepo,
+		TotpProvider:      totpProvider,
+		TotpLimiter:       totpLimiter,
+		PasswordLimiter:   di.GetPasswordLoginRateLimitService(cfg),
+		PasswordIpLimiter: di.GetPasswordLoginIpRateLimitService(cfg),
+	})
+	otpUsecase := usecase.NewOtpUsecase(cfg, usecase.OtpDependencies{
+		OtpProvider:           otpProvider,
+		MagicLinkProvider:     magicLinkProvider,
+		RateLimitService:      rateLimitService,
+		VoiceRateLimitService: di.GetVoiceRateLimitService(cfg),
+		SmsSender:             di.GetSmsSender(cfg),
+		VoiceCaller:           di.GetVoiceCaller(cfg),
+		EmailSender:           di.GetEmailSender(cfg),
+		PhonePolicy:           di.GetPhonePolicyUsecase(cfg),
+		AbuseDetector:         abuseDetector,
+		ChallengeVerifier:     di.GetChallengeVerifier(cfg),
+		AuditRepo:             auditRepo,
+	})
+	totpUsecase := usecase.NewTotpUsecase(cfg, userRepo, totpRepo, recoveryRepo, totpProvider, totpLimiter)
+	recoveryUsecase := usecase.NewRecoveryCodeUsecase(cfg, totpRepo, recoveryRepo, totpProvider, totpLimiter)
+	passkeyUsecase := usecase.NewPasskeyUsecase(cfg, userRepo, di.GetPasskeyRepository(cfg), di.GetPasskeyProvider(cfg), di.GetTokenProvider(cfg))
+	qrLoginUsecase := usecase.NewQrLoginUsecase(cfg, userRepo, di.GetTokenProvider(cfg), di.GetQrLoginRateLimitService(cfg))
 	return &UsersHandler{usecase: userUsecase,
-		otpUsecase: otpUsecase}
+		otpUsecase:      otpUsecase,
+		totpUsecase:     totpUsecase,
+		recoveryUsecase: recoveryUsecase,
+		passkeyUsecase:  passkeyUsecase,
+		qrLoginUsecase:  qrLoginUsecase}
 }
 
 // RegisterLoginByMobileNumber godoc
@@ -41,28 +87,57 @@ func (h *UsersHandler) RegisterLoginByMobileNumber(c *gin.Context) {
 	req := new(dto.RegisterLoginByMobileRequest)
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest,
-			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, err))
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
 		return
 	}
-	token, err := h.usecase.RegisterAndLoginByMobileNumber(c, req.MobileNumber, req.Otp)
+	token, err := h.usecase.RegisterAndLoginByMobileNumber(c.Request.Context(), req.MobileNumber, req.Otp)
 	if err != nil {
-		c.AbortWithStatusJSON(helper.TranslateErrorToStatusCode(err),
-			helper.GenerateBaseResponseWithError(nil, false, helper.InternalError, err))
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
 		return
 	}
 
-	c.JSON(http.StatusCreated, helper.GenerateBaseResponse(token, true, helper.Success))
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(token, true, helper.Success))
+}
+
+// Login godoc
+// @Summary Register or login by mobile number or email
+// @Description Check the code sent to mobile_number or email and return tokens, registering the user first when the identifier is new
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param Request body dto.LoginRequest true "LoginRequest"
+// @Success 201 {object} helper.BaseHttpResponse "Success"
+// @Failure 400 {object} helper.BaseHttpResponse "Failed"
+// @Failure 409 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/login [post]
+func (h *UsersHandler) Login(c *gin.Context) {
+	req := new(dto.LoginRequest)
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+	token, err := h.usecase.RegisterAndLogin(c.Request.Context(), req.MobileNumber, req.Email, req.Otp)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
+		return
+	}
+
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(token, true, helper.Success))
 }
 
 // SendOtp godoc
 // @Summary Send otp to user
-// @Description Send otp to user
+// @Description Send otp to user by SMS or voice call to mobile_number, or by email to email
 // @Tags Users
 // @Accept  json
 // @Produce  json
 // @Param Request body dto.SendOtpRequest true "SendOtpRequest"
-// @Success 201 {object} helper.BaseHttpResponse "Success"
+// @Success 201 {object} helper.BaseHttpResponse{result=dto.SendOtpResponse} "Success"
 // @Failure 400 {object} helper.BaseHttpResponse "Failed"
 // @Failure 409 {object} helper.BaseHttpResponse "Failed"
 // @Router /v1/users/send-otp [post]
@@ -70,19 +145,36 @@ func (h *UsersHandler) SendOtp(c *gin.Context) {
 	req := new(dto.SendOtpRequest)
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest,
-			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, err))
+		helper.AbortWithResponse(c, http.StatusBadRequest,
+			helper.GenerateBaseResponseWithValidationError(nil, false, helper.ValidationError, service_errors.Wrap(service_errors.CodeValidation, err)))
+		return
+	}
+
+	sent, err := h.otpUsecase.SendOtp(c.Request.Context(), req.MobileNumber, req.Email, req.Channel, c.ClientIP(), req.ChallengeResponse)
+	if err != nil {
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
 		return
 	}
+	helper.WriteResponse(c, http.StatusCreated, helper.GenerateBaseResponse(sent, true, helper.Success))
+}
 
-	err = h.otpUsecase.SendOtp(req.MobileNumber)
+// GetChallenge godoc
+// @Summary Get a send-otp challenge
+// @Description Issue a proof-of-work challenge, or the CAPTCHA site key, to solve after send-otp answers CHALLENGE_REQUIRED
+// @Tags Users
+// @Produce  json
+// @Success 200 {object} helper.BaseHttpResponse{result=dto.ChallengeResponse} "Success"
+// @Failure 500 {object} helper.BaseHttpResponse "Failed"
+// @Router /v1/users/challenge [get]
+func (h *UsersHandler) GetChallenge(c *gin.Context) {
+	challenge, err := h.otpUsecase.IssueChallenge(c.Request.Context())
 	if err != nil {
-		c.AbortWithStatusJSON(helper.TranslateErrorToStatusCode(err),
-			helper.GenerateBaseResponseWithError(nil, false, helper.InternalError, err))
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
 		return
 	}
-	// TODO: Call internal SMS service
-	c.JSON(http.StatusCreated, helper.GenerateBaseResponse(nil, true, helper.Success))
+	helper.WriteResponse(c, http.StatusOK, helper.GenerateBaseResponse(challenge, true, helper.Success))
 }
 
 // GetUserByMobileNumber godoc
@@ -98,10 +190,10 @@ func (h *UsersHandler) SendOtp(c *gin.Context) {
 // @Router /v1/users/{mobile_number} [get]
 func (h *UsersHandler) GetUserByMobileNumber(c *gin.Context) {
 	mobileNumber := c.Param("mobile_number")
-	user, err := h.usecase.GetUserByMobileNumber(c, mobileNumber)
+	user, err := h.usecase.GetUserByMobileNumber(c.Request.Context(), mobileNumber)
 	if err != nil {
-		c.AbortWithStatusJSON(helper.TranslateErrorToStatusCode(err),
-			helper.GenerateBaseResponseWithError(nil, false, helper.InternalError, err))
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
 		return
 	}
 	c.JSON(http.StatusOK, user)
@@ -125,10 +217,10 @@ func (h *UsersHandler) GetUsers(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 	mobileNumber := c.Query("mobile_number")
 
-	users, err := h.usecase.GetAllUsers(c, page, pageSize, mobileNumber)
+	users, err := h.usecase.GetAllUsers(c.Request.Context(), page, pageSize, mobileNumber)
 	if err != nil {
-		c.AbortWithStatusJSON(helper.TranslateErrorToStatusCode(err),
-			helper.GenerateBaseResponseWithError(nil, false, helper.InternalError, err))
+		helper.AbortWithResponse(c, helper.TranslateErrorToStatusCode(err),
+			helper.GenerateBaseResponseFromError(err))
 		return
 	}
 	c.JSON(http.StatusOK, users)
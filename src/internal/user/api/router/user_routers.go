@@ -1,6 +1,8 @@
 package router
 
 import (
+	"github.com/alielmi98/golang-otp-auth/di"
+	"github.com/alielmi98/golang-otp-auth/internal/middlewares"
 	"github.com/alielmi98/golang-otp-auth/internal/user/api/handler"
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/gin-gonic/gin"
@@ -9,8 +11,40 @@ import (
 func Users(router *gin.RouterGroup, cfg *config.Config, handler *handler.UsersHandler) {
 
 	router.POST("/send-otp", handler.SendOtp)
+	router.GET("/challenge", handler.GetChallenge)
+	router.POST("/login", handler.Login)
+	router.POST("/login/totp", handler.LoginSecondFactor)
+	router.POST("/magic-link", handler.SendMagicLink)
+	router.POST("/login/magic-link", handler.LoginByMagicLink)
 	router.POST("/login-by-mobile", handler.RegisterLoginByMobileNumber)
+	router.POST("/login-by-password", handler.LoginByPassword)
+	router.POST("/password/reset", handler.ResetPassword)
+	router.PUT("/password", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)), handler.SetPassword)
 	router.GET("/:mobile_number", handler.GetUserByMobileNumber)
 	router.GET("/", handler.GetUsers)
 
+	totp := router.Group("/totp", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)))
+	totp.POST("", handler.EnrollTotp)
+	totp.POST("/confirm", handler.ConfirmTotp)
+	totp.DELETE("", handler.DisableTotp)
+
+	recoveryCodes := router.Group("/recovery-codes", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)))
+	recoveryCodes.GET("", handler.GetRecoveryCodes)
+	recoveryCodes.POST("", handler.RegenerateRecoveryCodes)
+
+	router.POST("/passkeys/login/begin", handler.BeginPasskeyLogin)
+	router.POST("/passkeys/login/finish", handler.FinishPasskeyLogin)
+	passkeys := router.Group("/passkeys", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)))
+	passkeys.GET("", handler.GetPasskeys)
+	passkeys.POST("/register/begin", handler.BeginPasskeyRegistration)
+	passkeys.POST("/register/finish", handler.FinishPasskeyRegistration)
+	passkeys.DELETE("/:id", handler.DeletePasskey)
+
+	router.POST("/qr-login", handler.CreateQrLogin)
+	router.POST("/qr-login/poll", handler.PollQrLogin)
+	qrLogin := router.Group("/qr-login", middlewares.Authentication(cfg, di.GetTokenProvider(cfg)))
+	qrLogin.GET("/:code", handler.GetQrLogin)
+	qrLogin.POST("/approve", handler.ApproveQrLogin)
+	qrLogin.POST("/deny", handler.DenyQrLogin)
+
 }
@@ -0,0 +1,16 @@
+package validation
+
+import (
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/go-playground/validator/v10"
+)
+
+// EmailValidator accepts the addresses the email OTP channel can deliver to, e.g. ali@example.com
+func EmailValidator(fld validator.FieldLevel) bool {
+	value, ok := fld.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	_, err := common.NormalizeEmail(value)
+	return err == nil
+}
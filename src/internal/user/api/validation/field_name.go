@@ -0,0 +1,18 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JsonFieldName reports validation errors under the JSON name clients send, e.g. mobile_number
+func JsonFieldName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return fld.Name
+	}
+	return name
+}
@@ -2,15 +2,19 @@ package validation
 
 import (
 	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/go-playground/validator/v10"
 )
 
-func IranianMobileNumberValidator(fld validator.FieldLevel) bool {
-
-	value, ok := fld.Field().Interface().(string)
-	if !ok {
-		return false
+// MobileNumberValidator accepts mobile numbers from the allowed regions in international
+// form or in the national form of the default region, e.g. 09121234567
+func MobileNumberValidator(cfg *config.Config) validator.Func {
+	return func(fld validator.FieldLevel) bool {
+		value, ok := fld.Field().Interface().(string)
+		if !ok {
+			return false
+		}
+		_, err := common.NormalizeMobileNumber(value, &cfg.Phone)
+		return err == nil
 	}
-
-	return common.IranianMobileNumberValidate(value)
 }
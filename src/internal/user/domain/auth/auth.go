@@ -1,19 +1,46 @@
 package auth
 
 import (
+	"context"
+
 	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
 	"github.com/alielmi98/golang-otp-auth/internal/user/entity"
 	"github.com/golang-jwt/jwt"
 )
 
 type TokenProvider interface {
-	GenerateToken(token *entity.TokenPayload) (*dto.TokenDetail, error)
-	VerifyToken(token string) (*jwt.Token, error)
-	GetClaims(token string) (map[string]interface{}, error)
-	RefreshToken(refreshToken string) (*dto.TokenDetail, error)
+	GenerateToken(ctx context.Context, token *entity.TokenPayload) (*dto.TokenDetail, error)
+	VerifyToken(ctx context.Context, token string) (*jwt.Token, error)
+	GetClaims(ctx context.Context, token string) (map[string]interface{}, error)
+	RefreshToken(ctx context.Context, refreshToken string, clientId string) (*dto.TokenDetail, error)
+}
+
+// PasskeyProvider runs the WebAuthn ceremonies. Options is the JSON for
+// navigator.credentials.create or get, session the ceremony state to keep until the browser
+// answers, and response the PublicKeyCredential JSON it answered with.
+type PasskeyProvider interface {
+	BeginRegistration(ctx context.Context, userId int) (options []byte, session []byte, err error)
+	FinishRegistration(ctx context.Context, userId int, name string, session []byte, response []byte) (model.Passkey, error)
+	BeginLogin(ctx context.Context) (options []byte, session []byte, err error)
+	FinishLogin(ctx context.Context, session []byte, response []byte) (userId int, err error)
 }
 
 type OtpProvider interface {
-	SetOtp(mobileNumber string, otp string) error
-	ValidateOtp(mobileNumber string, otp string) error
+	SetOtp(ctx context.Context, mobileNumber string, otp string) error
+	ValidateOtp(ctx context.Context, mobileNumber string, otp string) error
+}
+
+// RevocableOtpProvider can also drop a code that was stored but could not be delivered, so the
+// recipient can ask for a new one right away
+type RevocableOtpProvider interface {
+	OtpProvider
+	RevokeOtp(ctx context.Context, mobileNumber string, otp string) error
+}
+
+// ResendableOtpProvider also returns the unused code stored for an address, so the same code can
+// be delivered again over another channel
+type ResendableOtpProvider interface {
+	RevocableOtpProvider
+	PendingOtp(ctx context.Context, mobileNumber string) (string, error)
 }
@@ -0,0 +1,22 @@
+package challenge
+
+import (
+	"context"
+	"time"
+)
+
+// Challenge tells the client what to solve. Proof-of-work fills Challenge and Difficulty,
+// CAPTCHA providers fill SiteKey for their widget.
+type Challenge struct {
+	Provider   string
+	Challenge  string
+	Difficulty int
+	ExpiresAt  time.Time
+	SiteKey    string
+}
+
+// Verifier issues challenges and checks the client's response before an OTP is sent
+type Verifier interface {
+	Issue(ctx context.Context) (Challenge, error)
+	Verify(ctx context.Context, response string, clientIp string) error
+}
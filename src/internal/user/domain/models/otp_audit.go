@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Channels a code is delivered through
+const (
+	OtpChannelSms   = "sms"
+	OtpChannelEmail = "email"
+	OtpChannelVoice = "voice"
+)
+
+const (
+	OtpEventSent            = "sent"
+	OtpEventVerified        = "verified"
+	OtpEventFailed          = "failed"
+	OtpEventExpired         = "expired"
+	OtpEventLocked          = "locked"
+	OtpEventRateLimited     = "rate_limited"
+	OtpEventBlocked         = "blocked"
+	OtpEventThrottled       = "throttled"
+	OtpEventChallenged      = "challenge_required"
+	OtpEventChallengeFailed = "challenge_failed"
+)
+
+// OtpAudit is an append-only record of OTP activity, correlated with the request that caused it
+type OtpAudit struct {
+	Id           int       `gorm:"primarykey"`
+	MobileNumber string    `gorm:"type:string;size:20;null;default:null;index"`
+	Email        string    `gorm:"type:string;size:254;null;default:null;index"`
+	Channel      string    `gorm:"type:string;size:10;not null;default:sms"`
+	Purpose      string    `gorm:"type:string;size:20;not null"`
+	Event        string    `gorm:"type:string;size:20;not null"`
+	Policy       string    `gorm:"type:string;size:30;null"`
+	RequestId    string    `gorm:"type:string;size:128;null;index"`
+	CreatedAt    time.Time `gorm:"type:TIMESTAMP with time zone;not null"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Passkey is a WebAuthn credential registered by a user. Flags is the raw authenticator-data
+// flags byte from the last ceremony; SignCount detects cloned authenticators.
+type Passkey struct {
+	Id              int    `gorm:"primarykey"`
+	User            User   `gorm:"foreignKey:UserId;constraint:OnUpdate:NO ACTION;OnDelete:CASCADE"`
+	UserId          int    `gorm:"not null;index"`
+	Name            string `gorm:"type:string;size:64;not null"`
+	CredentialId    []byte `gorm:"type:bytea;not null;uniqueIndex"`
+	PublicKey       []byte `gorm:"type:bytea;not null"`
+	AttestationType string `gorm:"type:string;size:32;not null"`
+	// Transports is the comma-separated list the authenticator reported, e.g. "internal,hybrid"
+	Transports string       `gorm:"type:string;size:255;not null"`
+	Aaguid     []byte       `gorm:"type:bytea;null"`
+	SignCount  int64        `gorm:"not null;default:0"`
+	Flags      int16        `gorm:"not null;default:0"`
+	LastUsedAt sql.NullTime `gorm:"type:TIMESTAMP with time zone;null"`
+
+	CreatedAt  time.Time    `gorm:"type:TIMESTAMP with time zone;not null"`
+	ModifiedAt sql.NullTime `gorm:"type:TIMESTAMP with time zone;null"`
+}
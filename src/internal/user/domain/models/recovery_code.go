@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RecoveryCode is one of a user's one-time codes that stand in for the second factor.
+// Only the keyed hash of the code is stored.
+type RecoveryCode struct {
+	Id       int          `gorm:"primarykey"`
+	User     User         `gorm:"foreignKey:UserId;constraint:OnUpdate:NO ACTION;OnDelete:CASCADE"`
+	UserId   int          `gorm:"not null;index"`
+	CodeHash string       `gorm:"type:string;size:64;not null"`
+	UsedAt   sql.NullTime `gorm:"type:TIMESTAMP with time zone;null"`
+
+	CreatedAt time.Time `gorm:"type:TIMESTAMP with time zone;not null"`
+}
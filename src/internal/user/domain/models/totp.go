@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// TotpCredential is a user's authenticator-app secret. It only guards logins once Confirmed;
+// LastUsedStep is the newest accepted time step, so a code cannot be replayed.
+type TotpCredential struct {
+	Id     int  `gorm:"primarykey"`
+	User   User `gorm:"foreignKey:UserId;constraint:OnUpdate:NO ACTION;OnDelete:CASCADE"`
+	UserId int  `gorm:"not null;uniqueIndex"`
+	// Secret is the base32 secret sealed with totp.encryptionKey
+	Secret       string       `gorm:"type:string;size:255;not null"`
+	Confirmed    bool         `gorm:"not null;default:false"`
+	ConfirmedAt  sql.NullTime `gorm:"type:TIMESTAMP with time zone;null"`
+	LastUsedStep int64        `gorm:"not null;default:0"`
+
+	CreatedAt  time.Time    `gorm:"type:TIMESTAMP with time zone;not null"`
+	ModifiedAt sql.NullTime `gorm:"type:TIMESTAMP with time zone;null"`
+}
@@ -8,7 +8,8 @@ import (
 type User struct {
 	Id int `gorm:"primarykey"`
 
-	MobileNumber string    `gorm:"type:string;size:11;null;unique;default:null"`
+	MobileNumber string    `gorm:"type:string;size:16;null;unique;default:null"`
+	Email        string    `gorm:"type:string;size:254;null;unique;default:null"`
 	Enabled      bool      `gorm:"default:true"`
 	RegisteredAt time.Time `gorm:"type:TIMESTAMP with time zone;not null"`
 	Password     string    `gorm:"type:string;size:255;not null"`
@@ -0,0 +1,16 @@
+package notification
+
+import "context"
+
+type SmsSender interface {
+	SendSms(ctx context.Context, mobileNumber string, message string) error
+}
+
+// VoiceCaller places a call that reads message aloud with text-to-speech in language, e.g. "fa"
+type VoiceCaller interface {
+	Call(ctx context.Context, mobileNumber string, message string, language string) error
+}
+
+type EmailSender interface {
+	SendEmail(ctx context.Context, to string, subject string, body string) error
+}
@@ -0,0 +1,32 @@
+package policy
+
+import "context"
+
+// PhoneNumberPolicy rejects numbers that admins have banned or whose country or prefix is not allowed
+type PhoneNumberPolicy interface {
+	CheckPhoneNumber(ctx context.Context, mobileNumber string) error
+}
+
+type AbuseVerdict string
+
+const (
+	VerdictAllow     AbuseVerdict = "allow"
+	VerdictChallenge AbuseVerdict = "challenge"
+	VerdictThrottle  AbuseVerdict = "throttle"
+)
+
+// AbuseAssessment is the outcome for one send attempt; Signal names the heuristic that
+// produced the highest score, e.g. "ip"
+type AbuseAssessment struct {
+	Score   float64
+	Signal  string
+	Verdict AbuseVerdict
+}
+
+// AbuseDetector scores send attempts from the OTP send and verify events it is fed; recipient
+// is an E.164 number or an email address, as told by channel ("sms", "voice" or "email")
+type AbuseDetector interface {
+	AssessSend(ctx context.Context, recipient string, channel string, clientIp string) (AbuseAssessment, error)
+	RecordSent(ctx context.Context, recipient string, channel string)
+	RecordVerified(ctx context.Context, recipient string, channel string)
+}
@@ -15,4 +15,33 @@ type UserRepository interface {
 	GetDefaultRole(ctx context.Context) (roleId int, err error)
 	ExistsMobileNumber(ctx context.Context, mobileNumber string) (bool, error)
 	FetchUserInfo(ctx context.Context, mobileNumber string) (model.User, error)
+	ExistsEmail(ctx context.Context, email string) (bool, error)
+	FetchUserInfoByEmail(ctx context.Context, email string) (model.User, error)
+	FetchUserInfoById(ctx context.Context, id int) (model.User, error)
+}
+
+type TotpRepository interface {
+	SaveTotpSecret(ctx context.Context, userId int, sealedSecret string) error
+	GetTotpCredential(ctx context.Context, userId int) (model.TotpCredential, error)
+	ConfirmTotp(ctx context.Context, userId int) error
+	DeleteTotpCredential(ctx context.Context, userId int) error
+	UseTotpStep(ctx context.Context, userId int, step int64) (bool, error)
+}
+
+type RecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userId int) (int, error)
+	DeleteRecoveryCodes(ctx context.Context, userId int) error
+}
+
+type PasskeyRepository interface {
+	CreatePasskey(ctx context.Context, passkey *model.Passkey) error
+	GetPasskeys(ctx context.Context, userId int) ([]model.Passkey, error)
+	UpdatePasskeyUse(ctx context.Context, id int, signCount int64, flags int16) error
+	DeletePasskey(ctx context.Context, userId int, id int) error
+}
+
+type OtpAuditRepository interface {
+	CreateOtpAudit(ctx context.Context, audit model.OtpAudit) error
 }
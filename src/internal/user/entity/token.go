@@ -3,5 +3,8 @@ package entity
 type TokenPayload struct {
 	UserId       int
 	MobileNumber string
+	Email        string
 	Roles        []string
+	// ClientId names the OAuth client the tokens were issued to; empty for first-party logins
+	ClientId string
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"time"
 
 	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
@@ -22,7 +23,7 @@ func NewJwtProvider(cfg *config.Config) *JwtProvider {
 	}
 }
 
-func (s *JwtProvider) GenerateToken(token *entity.TokenPayload) (*dto.TokenDetail, error) {
+func (s *JwtProvider) GenerateToken(ctx context.Context, token *entity.TokenPayload) (*dto.TokenDetail, error) {
 	td := &dto.TokenDetail{}
 	td.AccessTokenExpireTime = time.Now().Add(s.cfg.JWT.AccessTokenExpireDuration * time.Minute).Unix()
 	td.RefreshTokenExpireTime = time.Now().Add(s.cfg.JWT.RefreshTokenExpireDuration * time.Minute).Unix()
@@ -31,8 +32,12 @@ func (s *JwtProvider) GenerateToken(token *entity.TokenPayload) (*dto.TokenDetai
 
 	atc[constants.UserIdKey] = token.UserId
 	atc[constants.MobileNumberKey] = token.MobileNumber
+	atc[constants.EmailKey] = token.Email
 	atc[constants.ExpireTimeKey] = td.AccessTokenExpireTime
 	atc[constants.RolesKey] = token.Roles
+	if token.ClientId != "" {
+		atc[constants.ClientIdKey] = token.ClientId
+	}
 
 	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atc)
 
@@ -46,8 +51,12 @@ func (s *JwtProvider) GenerateToken(token *entity.TokenPayload) (*dto.TokenDetai
 	rtc := jwt.MapClaims{}
 	rtc[constants.UserIdKey] = token.UserId
 	rtc[constants.MobileNumberKey] = token.MobileNumber
+	rtc[constants.EmailKey] = token.Email
 	rtc[constants.ExpireTimeKey] = td.RefreshTokenExpireTime
 	rtc[constants.RolesKey] = token.Roles
+	if token.ClientId != "" {
+		rtc[constants.ClientIdKey] = token.ClientId
+	}
 
 	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, rtc)
 
@@ -60,13 +69,18 @@ func (s *JwtProvider) GenerateToken(token *entity.TokenPayload) (*dto.TokenDetai
 	return td, nil
 }
 
-func (s *JwtProvider) VerifyToken(token string) (*jwt.Token, error) {
+func (s *JwtProvider) VerifyToken(ctx context.Context, token string) (*jwt.Token, error) {
+	return verify(token, s.cfg.JWT.Secret)
+}
+
+// verify parses token and checks its signature against secret
+func verify(token string, secret string) (*jwt.Token, error) {
 	at, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
-			return nil, &service_errors.ServiceError{EndUserMessage: service_errors.UnExpectedError}
+			return nil, service_errors.New(service_errors.CodeUnexpected)
 		}
-		return []byte(s.cfg.JWT.Secret), nil
+		return []byte(secret), nil
 	})
 	if err != nil {
 		return nil, err
@@ -74,10 +88,14 @@ func (s *JwtProvider) VerifyToken(token string) (*jwt.Token, error) {
 	return at, nil
 }
 
-func (s *JwtProvider) GetClaims(token string) (claimMap map[string]interface{}, err error) {
+func (s *JwtProvider) GetClaims(ctx context.Context, token string) (claimMap map[string]interface{}, err error) {
+	return claims(token, s.cfg.JWT.Secret)
+}
+
+func claims(token string, secret string) (claimMap map[string]interface{}, err error) {
 	claimMap = map[string]interface{}{}
 
-	verifyToken, err := s.VerifyToken(token)
+	verifyToken, err := verify(token, secret)
 	if err != nil {
 		return nil, err
 	}
@@ -88,18 +106,25 @@ func (s *JwtProvider) GetClaims(token string) (claimMap map[string]interface{},
 		}
 		return claimMap, nil
 	}
-	return nil, &service_errors.ServiceError{EndUserMessage: service_errors.ClaimsNotFound}
+	return nil, service_errors.New(service_errors.CodeClaimsNotFound)
 }
-func (s *JwtProvider) RefreshToken(refreshToken string) (*dto.TokenDetail, error) {
-	claims, err := s.GetClaims(refreshToken)
+
+// RefreshToken renews the tokens of refreshToken, which is signed with jwt.refreshSecret and must have
+// been issued to clientId; first-party refresh tokens carry no client and are renewed with an empty clientId
+func (s *JwtProvider) RefreshToken(ctx context.Context, refreshToken string, clientId string) (*dto.TokenDetail, error) {
+	claims, err := claims(refreshToken, s.cfg.JWT.RefreshSecret)
 	if err != nil {
-		return nil, err
+		return nil, service_errors.Wrap(service_errors.CodeInvalidRefreshToken, err)
+	}
+	tokenClientId, _ := claims[constants.ClientIdKey].(string)
+	if tokenClientId != clientId {
+		return nil, service_errors.New(service_errors.CodeInvalidRefreshToken)
 	}
 
-	// Convert roles to []string
+	// Convert roles to []string; tokens issued to OAuth clients carry none
 	rolesInterface, ok := claims[constants.RolesKey].([]interface{})
-	if !ok {
-		return nil, &service_errors.ServiceError{EndUserMessage: service_errors.InvalidRolesFormat}
+	if !ok && (clientId == "" || claims[constants.RolesKey] != nil) {
+		return nil, service_errors.New(service_errors.CodeInvalidRolesFormat)
 	}
 
 	// Convert rolesInterface to roles
@@ -107,16 +132,30 @@ func (s *JwtProvider) RefreshToken(refreshToken string) (*dto.TokenDetail, error
 	for i, role := range rolesInterface {
 		roles[i], ok = role.(string)
 		if !ok {
-			return nil, &service_errors.ServiceError{EndUserMessage: service_errors.InvalidRolesFormat}
+			return nil, service_errors.New(service_errors.CodeInvalidRolesFormat)
 		}
 	}
 
+	userId, ok := claims[constants.UserIdKey].(float64)
+	if !ok {
+		return nil, service_errors.New(service_errors.CodeInvalidRefreshToken)
+	}
+	mobileNumber, ok := claims[constants.MobileNumberKey].(string)
+	if !ok {
+		return nil, service_errors.New(service_errors.CodeInvalidRefreshToken)
+	}
+
+	// Refresh tokens issued before email login carry no email claim
+	email, _ := claims[constants.EmailKey].(string)
+
 	tokenDto := entity.TokenPayload{
-		UserId:       int(claims[constants.UserIdKey].(float64)),
-		MobileNumber: claims[constants.MobileNumberKey].(string),
+		UserId:       int(userId),
+		MobileNumber: mobileNumber,
+		Email:        email,
 		Roles:        roles,
+		ClientId:     clientId,
 	}
-	newTokenDetail, err := s.GenerateToken(&tokenDto)
+	newTokenDetail, err := s.GenerateToken(ctx, &tokenDto)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/entity"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+func newTestJwtProvider() *JwtProvider {
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "testSecret"
+	cfg.JWT.RefreshSecret = "testRefreshSecret"
+	cfg.JWT.AccessTokenExpireDuration = 15
+	cfg.JWT.RefreshTokenExpireDuration = 60
+	return NewJwtProvider(cfg)
+}
+
+// A refresh token is renewed only for the party it was issued to, so an OAuth client's token
+// cannot be turned into a first-party session and the other way around
+func TestRefreshTokenClientBinding(t *testing.T) {
+	tests := []struct {
+		name     string
+		issuedTo string
+		clientId string
+		valid    bool
+	}{
+		{"first-party token", "", "", true},
+		{"client token by its client", "cli", "cli", true},
+		{"client token as first-party", "cli", "", false},
+		{"client token by another client", "cli", "other", false},
+		{"first-party token by a client", "", "cli", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			provider := newTestJwtProvider()
+			payload := &entity.TokenPayload{UserId: 7, MobileNumber: "+989121234567", ClientId: tt.issuedTo}
+			if tt.issuedTo == "" {
+				payload.Roles = []string{"default"}
+			}
+			token, err := provider.GenerateToken(ctx, payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			renewed, err := provider.RefreshToken(ctx, token.RefreshToken, tt.clientId)
+			if !tt.valid {
+				if !errors.Is(err, service_errors.New(service_errors.CodeInvalidRefreshToken)) {
+					t.Errorf("got %v, want INVALID_REFRESH_TOKEN", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := provider.GetClaims(ctx, renewed.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if clientId, _ := claims[constants.ClientIdKey].(string); clientId != tt.issuedTo {
+				t.Errorf("renewed token has client_id %q, want %q", clientId, tt.issuedTo)
+			}
+		})
+	}
+}
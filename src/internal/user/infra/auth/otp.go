@@ -1,12 +1,16 @@
 package auth
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/alielmi98/golang-otp-auth/pkg/cache"
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
 	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
 	"github.com/go-redis/redis/v7"
 )
@@ -14,10 +18,14 @@ import (
 type OtpProvider struct {
 	cfg         *config.Config
 	redisClient *redis.Client
+	keyPrefix   string
+	expireTime  time.Duration
 }
 type otpDto struct {
-	Value string
-	Used  bool
+	Value     string
+	Used      bool
+	Attempts  int
+	RequestId string
 }
 
 func NewOtpProvider(cfg *config.Config) *OtpProvider {
@@ -25,44 +33,126 @@ func NewOtpProvider(cfg *config.Config) *OtpProvider {
 	return &OtpProvider{
 		cfg:         cfg,
 		redisClient: redis,
+		keyPrefix:   constants.RedisOtpDefaultKey,
+		expireTime:  cfg.Otp.ExpireTime * time.Second,
 	}
 }
 
-func (s *OtpProvider) SetOtp(mobileNumber string, otp string) error {
-	key := fmt.Sprintf("%s:%s", constants.RedisOtpDefaultKey, mobileNumber)
+// NewMagicLinkProvider keeps login-link nonces apart from codes, so a pending link and a
+// pending code for the same recipient do not block each other
+func NewMagicLinkProvider(cfg *config.Config) *OtpProvider {
+	return &OtpProvider{
+		cfg:         cfg,
+		redisClient: cache.GetRedis(),
+		keyPrefix:   constants.RedisMagicLinkKey,
+		expireTime:  cfg.MagicLink.ExpireTime * time.Second,
+	}
+}
+
+func (s *OtpProvider) SetOtp(ctx context.Context, mobileNumber string, otp string) error {
+	key := fmt.Sprintf("%s:%s", s.keyPrefix, mobileNumber)
 	val := &otpDto{
-		Value: otp,
-		Used:  false,
+		Value:     otp,
+		Used:      false,
+		RequestId: requestid.FromContext(ctx),
 	}
 
-	res, err := cache.Get[otpDto](s.redisClient, key)
+	res, err := cache.Get[otpDto](ctx, s.redisClient, key)
 	if err == nil && !res.Used {
-		return &service_errors.ServiceError{EndUserMessage: service_errors.OptExists}
+		return service_errors.New(service_errors.CodeOtpExists)
 	} else if err == nil && res.Used {
-		return &service_errors.ServiceError{EndUserMessage: service_errors.OtpUsed}
+		return service_errors.New(service_errors.CodeOtpUsed)
 	}
-	err = cache.Set(s.redisClient, key, val, s.cfg.Otp.ExpireTime*time.Second)
+	err = cache.Set(ctx, s.redisClient, key, val, s.expireTime)
 	if err != nil {
-		return err
+		return service_errors.Wrap(service_errors.CodeInternal, err)
 	}
 	return nil
 }
 
-func (s *OtpProvider) ValidateOtp(mobileNumber string, otp string) error {
-	key := fmt.Sprintf("%s:%s", constants.RedisOtpDefaultKey, mobileNumber)
-	res, err := cache.Get[otpDto](s.redisClient, key)
-	if err != nil {
-		return err
+// RevokeOtp deletes the code stored for mobileNumber while it is still otp and unused
+func (s *OtpProvider) RevokeOtp(ctx context.Context, mobileNumber string, otp string) error {
+	key := fmt.Sprintf("%s:%s", s.keyPrefix, mobileNumber)
+	res, err := cache.Get[otpDto](ctx, s.redisClient, key)
+	if err != nil || res.Used || res.Value != otp {
+		return nil
+	}
+	return s.redisClient.WithContext(ctx).Del(key).Err()
+}
+
+// PendingOtp returns the stored code while it can still be entered
+func (s *OtpProvider) PendingOtp(ctx context.Context, mobileNumber string) (string, error) {
+	key := fmt.Sprintf("%s:%s", s.keyPrefix, mobileNumber)
+	res, err := cache.Get[otpDto](ctx, s.redisClient, key)
+	if errors.Is(err, redis.Nil) {
+		return "", service_errors.Wrap(service_errors.CodeOtpExpired, err)
+	} else if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
 	} else if res.Used {
-		return &service_errors.ServiceError{EndUserMessage: service_errors.OtpUsed}
-	} else if !res.Used && res.Value != otp {
-		return &service_errors.ServiceError{EndUserMessage: service_errors.OtpNotValid}
-	} else if !res.Used && res.Value == otp {
-		res.Used = true
-		err = cache.Set(s.redisClient, key, res, s.cfg.Otp.ExpireTime*time.Second)
-		if err != nil {
+		return "", service_errors.New(service_errors.CodeOtpUsed)
+	} else if res.Attempts >= s.cfg.Otp.MaxVerifyAttempts {
+		return "", service_errors.New(service_errors.CodeOtpLocked)
+	}
+	return res.Value, nil
+}
+
+// maxValidateRetries bounds how often ValidateOtp re-reads an entry another request changed
+const maxValidateRetries = 3
+
+// ValidateOtp checks the code and records the outcome in one WATCH/MULTI transaction, so
+// concurrent guesses each count against MaxVerifyAttempts and a code is accepted at most once
+func (s *OtpProvider) ValidateOtp(ctx context.Context, mobileNumber string, otp string) error {
+	key := fmt.Sprintf("%s:%s", s.keyPrefix, mobileNumber)
+	for i := 0; i < maxValidateRetries; i++ {
+		var result error
+		err := s.redisClient.WithContext(ctx).Watch(func(tx *redis.Tx) error {
+			result = nil
+			raw, err := tx.Get(key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				return service_errors.Wrap(service_errors.CodeOtpExpired, err)
+			} else if err != nil {
+				return service_errors.Wrap(service_errors.CodeInternal, err)
+			}
+			var res otpDto
+			if err = json.Unmarshal(raw, &res); err != nil {
+				return service_errors.Wrap(service_errors.CodeInternal, err)
+			}
+			if res.Used {
+				return service_errors.New(service_errors.CodeOtpUsed)
+			} else if res.Attempts >= s.cfg.Otp.MaxVerifyAttempts {
+				return service_errors.New(service_errors.CodeOtpLocked)
+			}
+			if res.Value != otp {
+				res.Attempts++
+				result = service_errors.New(service_errors.CodeOtpInvalid)
+			} else {
+				res.Used = true
+			}
+
+			// Keep the lifetime the entry was created with
+			ttl, err := tx.PTTL(key).Result()
+			if err != nil {
+				return service_errors.Wrap(service_errors.CodeInternal, err)
+			} else if ttl <= 0 {
+				return service_errors.New(service_errors.CodeOtpExpired)
+			}
+			raw, err = json.Marshal(res)
+			if err != nil {
+				return service_errors.Wrap(service_errors.CodeInternal, err)
+			}
+			_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+				pipe.Set(key, raw, ttl)
+				return nil
+			})
+			return err
+		}, key)
+		if err == redis.TxFailedErr {
+			continue
+		} else if err != nil {
 			return err
 		}
+		return result
 	}
-	return nil
+	// Refuse a guess that could not be recorded rather than let it through uncounted
+	return service_errors.New(service_errors.CodeOtpInvalid)
 }
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+// TotpProvider is the OtpProvider strategy for authenticator apps. The subject is the user id;
+// SetOtp stores the shared secret rather than a code, and ValidateOtp accepts codes from
+// totp.skew periods around now, each time step at most once.
+type TotpProvider struct {
+	cfg  *config.Config
+	repo repository.TotpRepository
+}
+
+func NewTotpProvider(cfg *config.Config, repo repository.TotpRepository) *TotpProvider {
+	return &TotpProvider{cfg: cfg, repo: repo}
+}
+
+// SetOtp seals secret and stores it as an unconfirmed enrollment of the user
+func (p *TotpProvider) SetOtp(ctx context.Context, userId string, secret string) error {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeUnexpected, err)
+	}
+	sealed, err := common.Seal(p.cfg.Totp.EncryptionKey, secret)
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return p.repo.SaveTotpSecret(ctx, id, sealed)
+}
+
+func (p *TotpProvider) ValidateOtp(ctx context.Context, userId string, otp string) error {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeUnexpected, err)
+	}
+	credential, err := p.repo.GetTotpCredential(ctx, id)
+	if errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return service_errors.Wrap(service_errors.CodeTotpNotEnrolled, err)
+	} else if err != nil {
+		return err
+	}
+	secret, err := common.Open(p.cfg.Totp.EncryptionKey, credential.Secret)
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+
+	step, err := p.matchStep(secret, otp, time.Now())
+	if err != nil {
+		return err
+	}
+	fresh, err := p.repo.UseTotpStep(ctx, id, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return service_errors.New(service_errors.CodeOtpUsed)
+	}
+	return nil
+}
+
+// matchStep returns the time step within the drift window whose code is otp
+func (p *TotpProvider) matchStep(secret string, otp string, now time.Time) (int64, error) {
+	current := now.Unix() / int64(p.cfg.Totp.Period)
+	for drift := -p.cfg.Totp.Skew; drift <= p.cfg.Totp.Skew; drift++ {
+		step := current + int64(drift)
+		code, err := common.TotpCode(secret, step, p.cfg.Totp.Digits)
+		if err != nil {
+			return 0, service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(otp)) == 1 {
+			return step, nil
+		}
+	}
+	return 0, service_errors.New(service_errors.CodeOtpInvalid)
+}
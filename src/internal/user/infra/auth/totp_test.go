@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+const testTotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// memoryTotpRepo keeps one credential and applies UseTotpStep's "last_used_step < step" rule
+type memoryTotpRepo struct {
+	credential model.TotpCredential
+}
+
+func (r *memoryTotpRepo) SaveTotpSecret(ctx context.Context, userId int, sealedSecret string) error {
+	r.credential = model.TotpCredential{UserId: userId, Secret: sealedSecret}
+	return nil
+}
+func (r *memoryTotpRepo) GetTotpCredential(ctx context.Context, userId int) (model.TotpCredential, error) {
+	if r.credential.UserId != userId {
+		return model.TotpCredential{}, service_errors.New(service_errors.CodeRecordNotFound)
+	}
+	return r.credential, nil
+}
+func (r *memoryTotpRepo) ConfirmTotp(ctx context.Context, userId int) error {
+	r.credential.Confirmed = true
+	return nil
+}
+func (r *memoryTotpRepo) DeleteTotpCredential(ctx context.Context, userId int) error {
+	r.credential = model.TotpCredential{}
+	return nil
+}
+func (r *memoryTotpRepo) UseTotpStep(ctx context.Context, userId int, step int64) (bool, error) {
+	if r.credential.UserId != userId || r.credential.LastUsedStep >= step {
+		return false, nil
+	}
+	r.credential.LastUsedStep = step
+	return true, nil
+}
+
+func newTestTotpProvider(t *testing.T) (*TotpProvider, *memoryTotpRepo) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Totp.Period = 30
+	cfg.Totp.Digits = 6
+	cfg.Totp.Skew = 1
+	cfg.Totp.EncryptionKey = "testTotpEncryptionKey"
+	repo := &memoryTotpRepo{}
+	provider := NewTotpProvider(cfg, repo)
+	if err := provider.SetOtp(context.Background(), "7", testTotpSecret); err != nil {
+		t.Fatal(err)
+	}
+	return provider, repo
+}
+
+func totpCode(t *testing.T, step int64) string {
+	t.Helper()
+	code, err := common.TotpCode(testTotpSecret, step, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestTotpMatchStepSkew(t *testing.T) {
+	provider, _ := newTestTotpProvider(t)
+	// The last second of step 1000, so the window is steps 999 to 1001
+	now := time.Unix(1000*30+29, 0)
+
+	tests := []struct {
+		name  string
+		step  int64
+		valid bool
+	}{
+		{"two periods early", 998, false},
+		{"one period early", 999, true},
+		{"current", 1000, true},
+		{"one period late", 1001, true},
+		{"two periods late", 1002, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := totpCode(t, tt.step)
+			if code == totpCode(t, 999) && tt.step != 999 || code == totpCode(t, 1001) && tt.step != 1001 {
+				t.Skip("code collides with another step in the window")
+			}
+			step, err := provider.matchStep(testTotpSecret, code, now)
+			if !tt.valid {
+				if !errors.Is(err, service_errors.New(service_errors.CodeOtpInvalid)) {
+					t.Errorf("got step %d, %v; want OTP_INVALID", step, err)
+				}
+				return
+			}
+			if err != nil || step != tt.step {
+				t.Errorf("got step %d, %v; want step %d", step, err, tt.step)
+			}
+		})
+	}
+}
+
+func TestTotpValidateRejectsReplay(t *testing.T) {
+	ctx := context.Background()
+	provider, repo := newTestTotpProvider(t)
+	current := time.Now().Unix() / 30
+
+	if err := provider.ValidateOtp(ctx, "7", totpCode(t, current)); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := provider.ValidateOtp(ctx, "7", totpCode(t, current)); !errors.Is(err, service_errors.New(service_errors.CodeOtpUsed)) {
+		t.Errorf("replay: got %v, want OTP_USED", err)
+	}
+	// A code from before the accepted step is refused too, even inside the skew window
+	if err := provider.ValidateOtp(ctx, "7", totpCode(t, repo.credential.LastUsedStep-1)); !errors.Is(err, service_errors.New(service_errors.CodeOtpUsed)) {
+		t.Errorf("earlier step: got %v, want OTP_USED", err)
+	}
+	if err := provider.ValidateOtp(ctx, strconv.Itoa(8), totpCode(t, current)); !errors.Is(err, service_errors.New(service_errors.CodeTotpNotEnrolled)) {
+		t.Errorf("other user: got %v, want TOTP_NOT_ENROLLED", err)
+	}
+}
+
+func TestTotpSecretSealedAtRest(t *testing.T) {
+	provider, repo := newTestTotpProvider(t)
+	if repo.credential.Secret == testTotpSecret {
+		t.Fatal("secret stored in plain text")
+	}
+	if _, err := common.Open("other key", repo.credential.Secret); err == nil {
+		t.Error("opened with the wrong key")
+	}
+	provider.cfg.Totp.EncryptionKey = "rotated key"
+	err := provider.ValidateOtp(context.Background(), "7", totpCode(t, time.Now().Unix()/30))
+	if !errors.Is(err, service_errors.New(service_errors.CodeInternal)) {
+		t.Errorf("got %v, want INTERNAL after the key changed", err)
+	}
+}
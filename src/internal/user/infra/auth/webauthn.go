@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// WebAuthnProvider is the PasskeyProvider backed by go-webauthn. Passkeys are discoverable, so
+// login needs no identifier: the user handle the authenticator returns is the user id.
+type WebAuthnProvider struct {
+	cfg         *config.Config
+	webAuthn    *webauthn.WebAuthn
+	userRepo    repository.UserRepository
+	passkeyRepo repository.PasskeyRepository
+	logger      logging.Logger
+}
+
+func NewWebAuthnProvider(cfg *config.Config, userRepo repository.UserRepository, passkeyRepo repository.PasskeyRepository) *WebAuthnProvider {
+	logger := logging.GetLogger()
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    cfg.WebAuthn.Timeout * time.Second,
+		TimeoutUVD: cfg.WebAuthn.Timeout * time.Second,
+	}
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RpId,
+		RPDisplayName: cfg.WebAuthn.RpDisplayName,
+		RPOrigins:     cfg.WebAuthn.RpOrigins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.UserVerificationRequirement(cfg.WebAuthn.UserVerification),
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		logger.Fatal(constants.General, constants.Startup, "invalid webauthn configuration",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+	return &WebAuthnProvider{cfg: cfg, webAuthn: w, userRepo: userRepo, passkeyRepo: passkeyRepo, logger: logger}
+}
+
+func (p *WebAuthnProvider) BeginRegistration(ctx context.Context, userId int) ([]byte, []byte, error) {
+	user, err := p.loadUser(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	creation, session, err := p.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()))
+	if err != nil {
+		return nil, nil, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return marshalCeremony(creation, session)
+}
+
+// FinishRegistration verifies the attestation and stores the new passkey under name
+func (p *WebAuthnProvider) FinishRegistration(ctx context.Context, userId int, name string, session []byte, response []byte) (model.Passkey, error) {
+	var sessionData webauthn.SessionData
+	if err := json.Unmarshal(session, &sessionData); err != nil {
+		return model.Passkey{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	user, err := p.loadUser(ctx, userId)
+	if err != nil {
+		return model.Passkey{}, err
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return model.Passkey{}, p.rejected(ctx, err)
+	}
+	credential, err := p.webAuthn.CreateCredential(user, sessionData, parsed)
+	if err != nil {
+		return model.Passkey{}, p.rejected(ctx, err)
+	}
+
+	transports := make([]string, len(credential.Transport))
+	for i, transport := range credential.Transport {
+		transports[i] = string(transport)
+	}
+	passkey := model.Passkey{
+		UserId:          userId,
+		Name:            name,
+		CredentialId:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		Aaguid:          credential.Authenticator.AAGUID,
+		SignCount:       int64(credential.Authenticator.SignCount),
+		Flags:           int16(credential.Flags.ProtocolValue()),
+	}
+	err = p.passkeyRepo.CreatePasskey(ctx, &passkey)
+	if err != nil {
+		return model.Passkey{}, err
+	}
+	return passkey, nil
+}
+
+func (p *WebAuthnProvider) BeginLogin(ctx context.Context) ([]byte, []byte, error) {
+	assertion, session, err := p.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.UserVerificationRequirement(p.cfg.WebAuthn.UserVerification)))
+	if err != nil {
+		return nil, nil, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return marshalCeremony(assertion, session)
+}
+
+// FinishLogin verifies the assertion against the passkeys of the user it names and records the
+// new sign count; a count that went backwards means a cloned authenticator and is refused
+func (p *WebAuthnProvider) FinishLogin(ctx context.Context, session []byte, response []byte) (int, error) {
+	var sessionData webauthn.SessionData
+	if err := json.Unmarshal(session, &sessionData); err != nil {
+		return 0, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return 0, p.rejected(ctx, err)
+	}
+
+	var owner *webAuthnUser
+	_, credential, err := p.webAuthn.ValidatePasskeyLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		userId, err := strconv.Atoi(string(userHandle))
+		if err != nil {
+			return nil, err
+		}
+		owner, err = p.loadUser(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		return owner, nil
+	}, sessionData, parsed)
+	if err != nil {
+		return 0, p.rejected(ctx, err)
+	}
+	if credential.Authenticator.CloneWarning {
+		return 0, p.rejected(ctx, errors.New("sign count did not increase, the authenticator may be cloned"))
+	}
+
+	for _, passkey := range owner.passkeys {
+		if bytes.Equal(passkey.CredentialId, credential.ID) {
+			err = p.passkeyRepo.UpdatePasskeyUse(ctx, passkey.Id, int64(credential.Authenticator.SignCount), int16(credential.Flags.ProtocolValue()))
+			if err != nil {
+				return 0, err
+			}
+			break
+		}
+	}
+	return owner.user.Id, nil
+}
+
+func (p *WebAuthnProvider) loadUser(ctx context.Context, userId int) (*webAuthnUser, error) {
+	user, err := p.userRepo.FetchUserInfoById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	passkeys, err := p.passkeyRepo.GetPasskeys(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{user: user, passkeys: passkeys}, nil
+}
+
+// rejected logs why a ceremony failed, which the client is not told
+func (p *WebAuthnProvider) rejected(ctx context.Context, err error) error {
+	extra := map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()}
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.DevInfo != "" {
+		extra[constants.ErrorMessage] = protocolErr.Details + ": " + protocolErr.DevInfo
+	}
+	p.logger.WithContext(ctx).Warn(constants.Internal, constants.Passkey, "passkey ceremony rejected", extra)
+	return service_errors.Wrap(service_errors.CodePasskeyInvalid, err)
+}
+
+func marshalCeremony(options interface{}, session *webauthn.SessionData) ([]byte, []byte, error) {
+	optionsJson, err := json.Marshal(options)
+	if err != nil {
+		return nil, nil, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	sessionJson, err := json.Marshal(session)
+	if err != nil {
+		return nil, nil, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return optionsJson, sessionJson, nil
+}
+
+// webAuthnUser adapts a user and their passkeys to webauthn.User
+type webAuthnUser struct {
+	user     model.User
+	passkeys []model.Passkey
+}
+
+// WebAuthnID is the user handle stored in the passkey; it is the user id, which is not personal data
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(strconv.Itoa(u.user.Id))
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	if u.user.MobileNumber != "" {
+		return u.user.MobileNumber
+	}
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.WebAuthnName()
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i, passkey := range u.passkeys {
+		var transports []protocol.AuthenticatorTransport
+		for _, transport := range strings.Split(passkey.Transports, ",") {
+			if transport != "" {
+				transports = append(transports, protocol.AuthenticatorTransport(transport))
+			}
+		}
+		credentials[i] = webauthn.Credential{
+			ID:              passkey.CredentialId,
+			PublicKey:       passkey.PublicKey,
+			AttestationType: passkey.AttestationType,
+			Transport:       transports,
+			Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(passkey.Flags)),
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    passkey.Aaguid,
+				SignCount: uint32(passkey.SignCount),
+			},
+		}
+	}
+	return credentials
+}
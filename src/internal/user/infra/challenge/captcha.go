@@ -0,0 +1,96 @@
+package challenge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/challenge"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderRecaptcha = "recaptcha"
+)
+
+// defaultVerifyUrls are used when challenge.verifyUrl is empty
+var defaultVerifyUrls = map[string]string{
+	ProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	ProviderRecaptcha: "https://www.google.com/recaptcha/api/siteverify",
+}
+
+type captchaResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// CaptchaVerifier checks widget tokens against an hCaptcha or reCAPTCHA style siteverify
+// endpoint. reCAPTCHA v3 scores below challenge.minScore are rejected.
+type CaptchaVerifier struct {
+	cfg       *config.Config
+	provider  string
+	verifyUrl string
+	client    *http.Client
+}
+
+func NewCaptchaVerifier(cfg *config.Config, provider string) *CaptchaVerifier {
+	verifyUrl := cfg.Challenge.VerifyUrl
+	if verifyUrl == "" {
+		verifyUrl = defaultVerifyUrls[provider]
+	}
+	return &CaptchaVerifier{
+		cfg:       cfg,
+		provider:  provider,
+		verifyUrl: verifyUrl,
+		client: &http.Client{
+			Timeout:   cfg.Challenge.Timeout * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+func (v *CaptchaVerifier) Issue(ctx context.Context) (challenge.Challenge, error) {
+	return challenge.Challenge{Provider: v.provider, SiteKey: v.cfg.Challenge.SiteKey}, nil
+}
+
+func (v *CaptchaVerifier) Verify(ctx context.Context, response string, clientIp string) error {
+	form := url.Values{"secret": {v.cfg.Challenge.CaptchaSecret}, "response": {response}}
+	if clientIp != "" {
+		form.Set("remoteip", clientIp)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return service_errors.Wrap(service_errors.CodeInternal,
+			fmt.Errorf("%s siteverify responded with status %d", v.provider, res.StatusCode))
+	}
+	var result captchaResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+
+	if !result.Success {
+		return failed(fmt.Sprintf("%s rejected the token: %s", v.provider, strings.Join(result.ErrorCodes, ",")))
+	}
+	if result.Score != nil && *result.Score < v.cfg.Challenge.MinScore {
+		return failed(fmt.Sprintf("%s score %.2f is below %.2f", v.provider, *result.Score, v.cfg.Challenge.MinScore))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/challenge"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/go-redis/redis/v7"
+)
+
+const (
+	ProviderPow = "pow"
+	powVersion  = "v1"
+)
+
+// PowVerifier issues HMAC-signed proof-of-work challenges, so no challenge is stored until it is
+// redeemed. A challenge is "v1.<nonce>.<expires>.<difficulty>.<signature>"; the response appends
+// ".<solution>" such that SHA-256 of the response has at least <difficulty> leading zero bits.
+type PowVerifier struct {
+	cfg         *config.Config
+	redisClient *redis.Client
+}
+
+func NewPowVerifier(cfg *config.Config) *PowVerifier {
+	return &PowVerifier{cfg: cfg, redisClient: cache.GetRedis()}
+}
+
+func (v *PowVerifier) Issue(ctx context.Context) (challenge.Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return challenge.Challenge{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	expiresAt := time.Now().Add(v.cfg.Challenge.Ttl * time.Second)
+	payload := fmt.Sprintf("%s.%s.%d.%d", powVersion, hex.EncodeToString(nonce), expiresAt.Unix(), v.cfg.Challenge.Difficulty)
+	return challenge.Challenge{
+		Provider:   ProviderPow,
+		Challenge:  payload + "." + v.sign(payload),
+		Difficulty: v.cfg.Challenge.Difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Verify checks the signature, expiry and work, then burns the nonce so a solution is used once
+func (v *PowVerifier) Verify(ctx context.Context, response string, clientIp string) error {
+	parts := strings.Split(response, ".")
+	if len(parts) != 6 || parts[0] != powVersion {
+		return failed("malformed proof-of-work response")
+	}
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(parts[4]), []byte(v.sign(payload))) {
+		return failed("invalid challenge signature")
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return failed("challenge expired")
+	}
+	difficulty, err := strconv.Atoi(parts[3])
+	if err != nil || leadingZeroBits(sha256.Sum256([]byte(response))) < difficulty {
+		return failed("insufficient proof of work")
+	}
+
+	ttl := time.Until(time.Unix(expires, 0)) + time.Second
+	fresh, err := v.redisClient.WithContext(ctx).SetNX("challenge:pow:"+parts[1], 1, ttl).Result()
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	if !fresh {
+		return failed("challenge already used")
+	}
+	return nil
+}
+
+func (v *PowVerifier) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(v.cfg.Challenge.Secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func leadingZeroBits(sum [sha256.Size]byte) int {
+	count := 0
+	for _, b := range sum {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
+
+func failed(technicalMessage string) *service_errors.ServiceError {
+	serviceErr := service_errors.New(service_errors.CodeChallengeFailed)
+	serviceErr.TechnicalMessage = technicalMessage
+	return serviceErr
+}
@@ -0,0 +1,104 @@
+package challenge
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/go-redis/redis/v7"
+)
+
+const testDifficulty = 8
+
+func newTestPowVerifier(t *testing.T) *PowVerifier {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{}
+	cfg.Challenge.Secret = "testChallengeSecret"
+	cfg.Challenge.Ttl = 60
+	cfg.Challenge.Difficulty = testDifficulty
+	return &PowVerifier{cfg: cfg, redisClient: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+}
+
+// solve appends the first solution with at least difficulty leading zero bits
+func solve(t *testing.T, challenge string, difficulty int) string {
+	t.Helper()
+	for i := 0; i < 1<<20; i++ {
+		response := challenge + "." + strconv.Itoa(i)
+		if leadingZeroBits(sha256.Sum256([]byte(response))) >= difficulty {
+			return response
+		}
+	}
+	t.Fatal("no solution found")
+	return ""
+}
+
+// unsolved appends a solution that does not do the work
+func unsolved(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		response := challenge + "." + strconv.Itoa(i)
+		if leadingZeroBits(sha256.Sum256([]byte(response))) < difficulty {
+			return response
+		}
+	}
+}
+
+// signed builds a challenge for the given expiry and difficulty with the verifier's key
+func signed(v *PowVerifier, expiresAt time.Time, difficulty int) string {
+	payload := fmt.Sprintf("%s.%s.%d.%d", powVersion, "00112233445566778899aabbccddeeff", expiresAt.Unix(), difficulty)
+	return payload + "." + v.sign(payload)
+}
+
+func technicalMessage(err error) string {
+	var serviceErr *service_errors.ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.TechnicalMessage
+	}
+	return ""
+}
+
+func TestPowVerify(t *testing.T) {
+	ctx := context.Background()
+	v := newTestPowVerifier(t)
+	issued, err := v.Issue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := solve(t, issued.Challenge, testDifficulty)
+
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"malformed", "v1.abc", "malformed proof-of-work response"},
+		{"tampered difficulty", solve(t, strings.Replace(issued.Challenge, ".8.", ".0.", 1), 0), "invalid challenge signature"},
+		{"other key", solve(t, signed(&PowVerifier{cfg: &config.Config{}}, time.Now().Add(time.Minute), 0), 0), "invalid challenge signature"},
+		{"expired", solve(t, signed(v, time.Now().Add(-time.Second), testDifficulty), testDifficulty), "challenge expired"},
+		{"insufficient work", unsolved(issued.Challenge, testDifficulty), "insufficient proof of work"},
+		{"solved", valid, ""},
+		{"nonce reused", valid, "challenge already used"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Verify(ctx, tt.response, "127.0.0.1")
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("got %v, want success", err)
+				}
+				return
+			}
+			if !errors.Is(err, service_errors.New(service_errors.CodeChallengeFailed)) || technicalMessage(err) != tt.want {
+				t.Errorf("got %v (%q), want CHALLENGE_FAILED (%q)", err, technicalMessage(err), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+type smsRequest struct {
+	Sender  string `json:"sender"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// HttpSmsSender posts messages to a JSON SMS gateway, forwarding the request id so
+// delivery problems can be traced on the gateway side
+type HttpSmsSender struct {
+	cfg    *config.Config
+	client *http.Client
+}
+
+func NewHttpSmsSender(cfg *config.Config) *HttpSmsSender {
+	return &HttpSmsSender{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   cfg.Sms.Timeout * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+func (s *HttpSmsSender) SendSms(ctx context.Context, mobileNumber string, message string) error {
+	body, err := json.Marshal(smsRequest{Sender: s.cfg.Sms.Sender, To: mobileNumber, Message: message})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Sms.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.cfg.Sms.ApiKey)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.HeaderKey, id)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("sms gateway responded with status %d", res.StatusCode)
+	}
+	return nil
+}
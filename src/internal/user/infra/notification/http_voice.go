@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// VoiceCallRequest is the JSON body the voice gateway receives
+type VoiceCallRequest struct {
+	CallerId string `json:"caller_id"`
+	To       string `json:"to"`
+	Message  string `json:"message"`
+	Language string `json:"language"`
+}
+
+// HttpVoiceCaller asks a JSON voice-call gateway to read a message to a number with text-to-speech
+type HttpVoiceCaller struct {
+	cfg    *config.Config
+	client *http.Client
+}
+
+func NewHttpVoiceCaller(cfg *config.Config) *HttpVoiceCaller {
+	return &HttpVoiceCaller{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   cfg.Voice.Timeout * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+func (c *HttpVoiceCaller) Call(ctx context.Context, mobileNumber string, message string, language string) error {
+	body, err := json.Marshal(VoiceCallRequest{CallerId: c.cfg.Voice.CallerId, To: mobileNumber, Message: message, Language: language})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.Voice.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Voice.ApiKey)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.HeaderKey, id)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("voice gateway responded with status %d", res.StatusCode)
+	}
+	return nil
+}
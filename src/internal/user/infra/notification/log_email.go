@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+)
+
+// LogEmailSender writes messages to the debug log instead of sending them; for local development only
+type LogEmailSender struct {
+	logger logging.Logger
+}
+
+func NewLogEmailSender() *LogEmailSender {
+	return &LogEmailSender{logger: logging.GetLogger()}
+}
+
+func (s *LogEmailSender) SendEmail(ctx context.Context, to string, subject string, body string) error {
+	s.logger.WithContext(ctx).Debug(constants.General, constants.ExternalService, subject+": "+body,
+		map[constants.ExtraKey]interface{}{constants.Email: logging.MaskEmail(to)})
+	return nil
+}
@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+)
+
+// LogSmsSender writes messages to the debug log instead of sending them; for local development only
+type LogSmsSender struct {
+	logger logging.Logger
+}
+
+func NewLogSmsSender() *LogSmsSender {
+	return &LogSmsSender{logger: logging.GetLogger()}
+}
+
+func (s *LogSmsSender) SendSms(ctx context.Context, mobileNumber string, message string) error {
+	s.logger.WithContext(ctx).Debug(constants.General, constants.ExternalService, message,
+		map[constants.ExtraKey]interface{}{constants.MobileNumber: logging.MaskPhone(mobileNumber)})
+	return nil
+}
@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+)
+
+// LogVoiceCaller writes calls to the debug log instead of placing them; for local development only
+type LogVoiceCaller struct {
+	logger logging.Logger
+}
+
+func NewLogVoiceCaller() *LogVoiceCaller {
+	return &LogVoiceCaller{logger: logging.GetLogger()}
+}
+
+func (c *LogVoiceCaller) Call(ctx context.Context, mobileNumber string, message string, language string) error {
+	c.logger.WithContext(ctx).Debug(constants.General, constants.ExternalService, "["+language+"] "+message,
+		map[constants.ExtraKey]interface{}{constants.MobileNumber: logging.MaskPhone(mobileNumber)})
+	return nil
+}
@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"github.com/google/uuid"
+)
+
+// SmtpEmailSender delivers plain-text UTF-8 mail over SMTP. STARTTLS is used whenever the
+// server offers it and required when email.startTls is set; credentials are only sent over TLS
+// or to a local server, so a fake SMTP server such as Mailpit works without configuration.
+type SmtpEmailSender struct {
+	cfg *config.Config
+}
+
+func NewSmtpEmailSender(cfg *config.Config) *SmtpEmailSender {
+	return &SmtpEmailSender{cfg: cfg}
+}
+
+func (s *SmtpEmailSender) SendEmail(ctx context.Context, to string, subject string, body string) error {
+	cfg := s.cfg.Email
+	// From may carry a display name, e.g. "OTPAuth <no-reply@example.com>"; the envelope takes the address
+	sender, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return err
+	}
+	timeout := cfg.Timeout * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	} else if cfg.StartTls {
+		return fmt.Errorf("smtp server %s does not offer STARTTLS", addr)
+	}
+	if cfg.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	message, err := s.buildMessage(ctx, sender, to, subject, body)
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(message); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s *SmtpEmailSender) buildMessage(ctx context.Context, sender *mail.Address, to string, subject string, body string) ([]byte, error) {
+	var msg bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, value)
+	}
+	header("From", sender.String())
+	header("To", to)
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid.NewString(), sender.Address[strings.LastIndex(sender.Address, "@")+1:]))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	if id := requestid.FromContext(ctx); id != "" {
+		header(requestid.HeaderKey, id)
+	}
+	msg.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&msg)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
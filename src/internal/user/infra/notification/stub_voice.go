@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// StubVoiceGateway is an HTTP stand-in for the voice gateway, for tests and local runs. It
+// accepts what HttpVoiceCaller sends, keeps the calls in memory and answers 502 for numbers
+// marked with Fail, so error paths can be exercised.
+type StubVoiceGateway struct {
+	mu      sync.Mutex
+	calls   []VoiceCallRequest
+	failing map[string]bool
+	// OnCall, when set, sees every accepted call
+	OnCall func(VoiceCallRequest)
+}
+
+func NewStubVoiceGateway() *StubVoiceGateway {
+	return &StubVoiceGateway{failing: map[string]bool{}}
+}
+
+// Fail makes calls to mobileNumber answer 502 Bad Gateway
+func (g *StubVoiceGateway) Fail(mobileNumber string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.failing[mobileNumber] = true
+}
+
+func (g *StubVoiceGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var call VoiceCallRequest
+	if err := json.NewDecoder(r.Body).Decode(&call); err != nil || call.To == "" || call.Message == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	g.mu.Lock()
+	if g.failing[call.To] {
+		g.mu.Unlock()
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	g.calls = append(g.calls, call)
+	g.mu.Unlock()
+	if g.OnCall != nil {
+		g.OnCall(call)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Calls returns the calls accepted so far, oldest first
+func (g *StubVoiceGateway) Calls() []VoiceCallRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]VoiceCallRequest(nil), g.calls...)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"gorm.io/gorm"
+)
+
+type OtpAuditPgRepo struct {
+	db     *gorm.DB
+	logger logging.Logger
+}
+
+func NewOtpAuditPgRepo() *OtpAuditPgRepo {
+	return &OtpAuditPgRepo{db: db.GetDb(), logger: logging.GetLogger()}
+}
+
+func (r *OtpAuditPgRepo) CreateOtpAudit(ctx context.Context, audit model.OtpAudit) error {
+	defer metrics.ObservePostgres("create_otp_audit", time.Now())
+	if err := r.db.WithContext(ctx).Create(&audit).Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Insert, "create otp audit failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return err
+	}
+	return nil
+}
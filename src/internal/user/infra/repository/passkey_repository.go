@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"gorm.io/gorm"
+)
+
+type PasskeyPgRepo struct {
+	db     *gorm.DB
+	logger logging.Logger
+}
+
+func NewPasskeyPgRepo() *PasskeyPgRepo {
+	return &PasskeyPgRepo{db: db.GetDb(), logger: logging.GetLogger()}
+}
+
+func (r *PasskeyPgRepo) CreatePasskey(ctx context.Context, passkey *model.Passkey) error {
+	defer metrics.ObservePostgres("create_passkey", time.Now())
+	err := r.db.WithContext(ctx).Create(passkey).Error
+	if isUniqueViolation(err, "credential_id") {
+		return service_errors.Wrap(service_errors.CodePasskeyExists, err)
+	} else if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Insert, "create passkey failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return nil
+}
+
+func (r *PasskeyPgRepo) GetPasskeys(ctx context.Context, userId int) ([]model.Passkey, error) {
+	defer metrics.ObservePostgres("get_passkeys", time.Now())
+	var passkeys []model.Passkey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("id").Find(&passkeys).Error
+	if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "get passkeys failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return nil, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return passkeys, nil
+}
+
+// UpdatePasskeyUse stores the sign count and flags of a successful login
+func (r *PasskeyPgRepo) UpdatePasskeyUse(ctx context.Context, id int, signCount int64, flags int16) error {
+	defer metrics.ObservePostgres("update_passkey_use", time.Now())
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&model.Passkey{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"sign_count": signCount, "flags": flags, "last_used_at": now, "modified_at": now}).Error
+	if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Update, "update passkey use failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return nil
+}
+
+func (r *PasskeyPgRepo) DeletePasskey(ctx context.Context, userId int, id int) error {
+	defer metrics.ObservePostgres("delete_passkey", time.Now())
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userId).Delete(&model.Passkey{})
+	if result.Error != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Delete, "delete passkey failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: result.Error.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return service_errors.New(service_errors.CodeRecordNotFound)
+	}
+	return nil
+}
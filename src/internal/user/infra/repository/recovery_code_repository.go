@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"gorm.io/gorm"
+)
+
+const unusedRecoveryCodeFilterExp string = "user_id = ? AND used_at IS NULL"
+
+type RecoveryCodePgRepo struct {
+	db     *gorm.DB
+	logger logging.Logger
+}
+
+func NewRecoveryCodePgRepo() *RecoveryCodePgRepo {
+	return &RecoveryCodePgRepo{db: db.GetDb(), logger: logging.GetLogger()}
+}
+
+// ReplaceRecoveryCodes swaps the user's codes, used or not, for a new set
+func (r *RecoveryCodePgRepo) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	defer metrics.ObservePostgres("replace_recovery_codes", time.Now())
+	codes := make([]model.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, model.RecoveryCode{UserId: userId, CodeHash: hash})
+	}
+	tx := r.db.WithContext(ctx).Begin()
+	err := tx.Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error
+	if err == nil && len(codes) > 0 {
+		err = tx.Create(&codes).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Rollback, "transaction rolled back", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	tx.Commit()
+	return nil
+}
+
+// UseRecoveryCode marks the unused code with codeHash as used; it returns false when there is
+// none, so a code is accepted once even under concurrent requests
+func (r *RecoveryCodePgRepo) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	defer metrics.ObservePostgres("use_recovery_code", time.Now())
+	result := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where(unusedRecoveryCodeFilterExp+" AND code_hash = ?", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Update, "use recovery code failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: result.Error.Error()})
+		return false, service_errors.Wrap(service_errors.CodeDatabase, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CountRecoveryCodes returns how many of the user's codes are left
+func (r *RecoveryCodePgRepo) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	defer metrics.ObservePostgres("count_recovery_codes", time.Now())
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).Where(unusedRecoveryCodeFilterExp, userId).Count(&count).Error
+	if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "count recovery codes failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return 0, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return int(count), nil
+}
+
+func (r *RecoveryCodePgRepo) DeleteRecoveryCodes(ctx context.Context, userId int) error {
+	defer metrics.ObservePostgres("delete_recovery_codes", time.Now())
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Delete, "delete recovery codes failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return nil
+}
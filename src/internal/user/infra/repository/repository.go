@@ -2,30 +2,38 @@ package repository
 
 import (
 	"context"
-	"log"
+	"errors"
+	"strings"
+	"time"
 
 	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
 	"github.com/alielmi98/golang-otp-auth/pkg/constants"
 	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
 const userFilterExp string = "mobile_number = ?"
+const emailFilterExp string = "email = ?"
 const countFilterExp string = "count(*) > 0"
 
 type PgRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	logger logging.Logger
 }
 
 func NewUserPgRepo() *PgRepo {
-	return &PgRepo{db: db.GetDb()}
+	return &PgRepo{db: db.GetDb(), logger: logging.GetLogger()}
 }
 
 func (r *PgRepo) CreateUser(ctx context.Context, u model.User) (model.User, error) {
-
+	defer metrics.ObservePostgres("create_user", time.Now())
 	roleId, err := r.GetDefaultRole(ctx)
 	if err != nil {
-		log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.DefaultRoleNotFound, err.Error())
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, constants.DefaultRoleNotFound, map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
 
 		return u, err
 	}
@@ -33,14 +41,18 @@ func (r *PgRepo) CreateUser(ctx context.Context, u model.User) (model.User, erro
 	err = tx.Create(&u).Error
 	if err != nil {
 		tx.Rollback()
-		log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.Rollback, err.Error())
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Rollback, "transaction rolled back", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
 
+		// A concurrent sign-up with the same email won the race
+		if isUniqueViolation(err, "email") {
+			return u, service_errors.Wrap(service_errors.CodeEmailExists, err)
+		}
 		return u, err
 	}
 	err = tx.Create(&model.UserRole{RoleId: roleId, UserId: u.Id}).Error
 	if err != nil {
 		tx.Rollback()
-		log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.Rollback, err.Error())
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Rollback, "transaction rolled back", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
 		return u, err
 	}
 	tx.Commit()
@@ -48,10 +60,11 @@ func (r *PgRepo) CreateUser(ctx context.Context, u model.User) (model.User, erro
 }
 
 func (r *PgRepo) Update(ctx context.Context, id int, user *model.User) error {
+	defer metrics.ObservePostgres("update_user", time.Now())
 	tx := r.db.WithContext(ctx).Begin()
 	if err := tx.Model(&model.User{}).Where("id = ?", id).Updates(user).Error; err != nil {
 		tx.Rollback()
-		log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.Rollback, err.Error())
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Rollback, "transaction rolled back", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
 		return err
 	}
 	tx.Commit()
@@ -59,10 +72,11 @@ func (r *PgRepo) Update(ctx context.Context, id int, user *model.User) error {
 }
 
 func (r *PgRepo) Delete(ctx context.Context, id int) error {
+	defer metrics.ObservePostgres("delete_user", time.Now())
 	tx := r.db.WithContext(ctx).Begin()
 	if err := tx.Where("id = ?", id).Delete(&model.User{}).Error; err != nil {
 		tx.Rollback()
-		log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.Rollback, err.Error())
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Rollback, "transaction rolled back", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
 		return err
 	}
 	tx.Commit()
@@ -70,6 +84,7 @@ func (r *PgRepo) Delete(ctx context.Context, id int) error {
 }
 
 func (r *PgRepo) GetUserByMobileNumber(ctx context.Context, mobileNumber string) (model.User, error) {
+	defer metrics.ObservePostgres("get_user_by_mobile", time.Now())
 	var user model.User
 	err := r.db.WithContext(ctx).
 		Model(&model.User{}).
@@ -78,13 +93,16 @@ func (r *PgRepo) GetUserByMobileNumber(ctx context.Context, mobileNumber string)
 		}).
 		Where(userFilterExp, mobileNumber).First(&user).Error
 
-	if err != nil {
-		return user, err
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, service_errors.Wrap(service_errors.CodeRecordNotFound, err)
+	} else if err != nil {
+		return user, service_errors.Wrap(service_errors.CodeDatabase, err)
 	}
 	return user, nil
 }
 
 func (r *PgRepo) GetAllUsers(ctx context.Context, page, pageSize int, mobileNumber string) ([]model.User, int, error) {
+	defer metrics.ObservePostgres("get_all_users", time.Now())
 	offset := (page - 1) * pageSize
 	var users []model.User
 	var total int64
@@ -106,7 +124,7 @@ func (r *PgRepo) GetAllUsers(ctx context.Context, page, pageSize int, mobileNumb
 }
 
 func (r *PgRepo) GetDefaultRole(ctx context.Context) (roleId int, err error) {
-
+	defer metrics.ObservePostgres("get_default_role", time.Now())
 	if err = r.db.WithContext(ctx).Model(&model.Role{}).
 		Select("id").
 		Where("name = ?", constants.DefaultRoleName).
@@ -117,6 +135,7 @@ func (r *PgRepo) GetDefaultRole(ctx context.Context) (roleId int, err error) {
 }
 
 func (r *PgRepo) FetchUserInfo(ctx context.Context, mobileNumber string) (model.User, error) {
+	defer metrics.ObservePostgres("fetch_user_info", time.Now())
 	var user model.User
 	err := r.db.WithContext(ctx).
 		Model(&model.User{}).
@@ -134,15 +153,74 @@ func (r *PgRepo) FetchUserInfo(ctx context.Context, mobileNumber string) (model.
 }
 
 func (r *PgRepo) ExistsMobileNumber(ctx context.Context, mobileNumber string) (bool, error) {
+	defer metrics.ObservePostgres("exists_mobile_number", time.Now())
 	var exists bool
 	if err := r.db.WithContext(ctx).Model(&model.User{}).
 		Select(countFilterExp).
 		Where("mobile_number = ?", mobileNumber).
 		Find(&exists).
 		Error; err != nil {
-		log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.Select, err.Error())
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "exists mobile number query failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
 
 		return false, err
 	}
 	return exists, nil
 }
+
+func (r *PgRepo) FetchUserInfoByEmail(ctx context.Context, email string) (model.User, error) {
+	defer metrics.ObservePostgres("fetch_user_info_by_email", time.Now())
+	var user model.User
+	err := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where(emailFilterExp, email).
+		Preload("UserRoles", func(tx *gorm.DB) *gorm.DB {
+			return tx.Preload("Role")
+		}).
+		Find(&user).Error
+
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *PgRepo) FetchUserInfoById(ctx context.Context, id int) (model.User, error) {
+	defer metrics.ObservePostgres("fetch_user_info_by_id", time.Now())
+	var user model.User
+	err := r.db.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Preload("UserRoles", func(tx *gorm.DB) *gorm.DB {
+			return tx.Preload("Role")
+		}).
+		First(&user).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, service_errors.Wrap(service_errors.CodeRecordNotFound, err)
+	} else if err != nil {
+		return user, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return user, nil
+}
+
+func (r *PgRepo) ExistsEmail(ctx context.Context, email string) (bool, error) {
+	defer metrics.ObservePostgres("exists_email", time.Now())
+	var exists bool
+	if err := r.db.WithContext(ctx).Model(&model.User{}).
+		Select(countFilterExp).
+		Where(emailFilterExp, email).
+		Find(&exists).
+		Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "exists email query failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+
+		return false, err
+	}
+	return exists, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation on a constraint naming column
+func isUniqueViolation(err error, column string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && strings.Contains(pgErr.ConstraintName, column)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"gorm.io/gorm"
+)
+
+const totpUserFilterExp string = "user_id = ?"
+
+type TotpPgRepo struct {
+	db     *gorm.DB
+	logger logging.Logger
+}
+
+func NewTotpPgRepo() *TotpPgRepo {
+	return &TotpPgRepo{db: db.GetDb(), logger: logging.GetLogger()}
+}
+
+// SaveTotpSecret replaces an unconfirmed enrollment with a new sealed secret
+func (r *TotpPgRepo) SaveTotpSecret(ctx context.Context, userId int, sealedSecret string) error {
+	defer metrics.ObservePostgres("save_totp_secret", time.Now())
+	tx := r.db.WithContext(ctx).Begin()
+	err := tx.Where(totpUserFilterExp+" AND confirmed = ?", userId, false).Delete(&model.TotpCredential{}).Error
+	if err == nil {
+		err = tx.Create(&model.TotpCredential{UserId: userId, Secret: sealedSecret}).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Rollback, "transaction rolled back", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	tx.Commit()
+	return nil
+}
+
+func (r *TotpPgRepo) GetTotpCredential(ctx context.Context, userId int) (model.TotpCredential, error) {
+	defer metrics.ObservePostgres("get_totp_credential", time.Now())
+	var credential model.TotpCredential
+	err := r.db.WithContext(ctx).Where(totpUserFilterExp, userId).First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return credential, service_errors.Wrap(service_errors.CodeRecordNotFound, err)
+	} else if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Select, "get totp credential failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return credential, service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return credential, nil
+}
+
+// ConfirmTotp activates the enrollment once a first code was accepted
+func (r *TotpPgRepo) ConfirmTotp(ctx context.Context, userId int) error {
+	defer metrics.ObservePostgres("confirm_totp", time.Now())
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&model.TotpCredential{}).
+		Where(totpUserFilterExp+" AND confirmed = ?", userId, false).
+		Updates(map[string]interface{}{"confirmed": true, "confirmed_at": now, "modified_at": now}).Error
+	if err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Update, "confirm totp failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return nil
+}
+
+func (r *TotpPgRepo) DeleteTotpCredential(ctx context.Context, userId int) error {
+	defer metrics.ObservePostgres("delete_totp_credential", time.Now())
+	if err := r.db.WithContext(ctx).Where(totpUserFilterExp, userId).Delete(&model.TotpCredential{}).Error; err != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Delete, "delete totp credential failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return nil
+}
+
+// UseTotpStep records step as the newest accepted one; it returns false when step, or a later
+// one, was already used, which makes every code single-use even under concurrent requests
+func (r *TotpPgRepo) UseTotpStep(ctx context.Context, userId int, step int64) (bool, error) {
+	defer metrics.ObservePostgres("use_totp_step", time.Now())
+	result := r.db.WithContext(ctx).Model(&model.TotpCredential{}).
+		Where(totpUserFilterExp+" AND last_used_step < ?", userId, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		r.logger.WithContext(ctx).Error(constants.Postgres, constants.Update, "use totp step failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: result.Error.Error()})
+		return false, service_errors.Wrap(service_errors.CodeDatabase, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
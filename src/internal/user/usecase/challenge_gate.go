@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/challenge"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/policy"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+const reasonIpRate = "challenge:ip_rate"
+
+// challengeGate puts a challenge in front of send-otp only for suspicious traffic
+type challengeGate struct {
+	cfg      *config.Config
+	verifier challenge.Verifier
+	limiter  ratelimit.RateLimiter
+}
+
+// reason returns why this send needs a solved challenge, or "" when it does not: the abuse
+// verdict asked for one, or the client IP used up its free sends for the window
+func (g challengeGate) reason(ctx context.Context, clientIp string, assessment policy.AbuseAssessment) (string, error) {
+	if assessment.Verdict == policy.VerdictChallenge {
+		return "abuse:" + assessment.Signal, nil
+	}
+	allowed, err := g.limiter.CheckLimit(ctx, fmt.Sprintf("challenge_ip:%s", clientIp),
+		g.cfg.Challenge.IpFreeSends, g.cfg.Challenge.IpWindow*time.Second)
+	if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	if !allowed {
+		return reasonIpRate, nil
+	}
+	return "", nil
+}
+
+func (g challengeGate) verify(ctx context.Context, response string, clientIp string) error {
+	if response == "" {
+		return service_errors.New(service_errors.CodeChallenge)
+	}
+	return g.verifier.Verify(ctx, response, clientIp)
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+)
+
+// magicLinks issues and checks signed one-time login links. A link token names the recipient and
+// a nonce the OtpProvider keeps for them, so a link is spent exactly like a code.
+type magicLinks struct {
+	cfg      *config.Config
+	provider auth.RevocableOtpProvider
+}
+
+// magicLinkClaims is the signed part of a link token; the short names keep SMS links short
+type magicLinkClaims struct {
+	Channel string `json:"c"`
+	Address string `json:"a"`
+	Nonce   string `json:"n"`
+	// Device is the hash of the device token handed to the requester, when links are bound to it
+	Device    string `json:"d,omitempty"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// issue stores a new nonce for the recipient, hands the link to deliver and returns, with
+// magicLink.sameDevice, the device token that must come back with it
+func (m magicLinks) issue(ctx context.Context, recipient otpRecipient, deliver func(link string) error) (deviceToken string, expiresAt time.Time, err error) {
+	nonce, err := randomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt = time.Now().Add(m.cfg.MagicLink.ExpireTime * time.Second)
+	claims := magicLinkClaims{
+		Channel:   recipient.Channel,
+		Address:   recipient.Address,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt.Unix(),
+	}
+	if m.cfg.MagicLink.SameDevice {
+		deviceToken, err = randomToken(32)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		claims.Device = deviceHash(deviceToken)
+	}
+	link, err := m.url(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	err = m.provider.SetOtp(ctx, recipient.Address, nonce)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	err = deliver(link)
+	if err != nil {
+		// A link that never went out must not hold off a new request until it expires
+		m.provider.RevokeOtp(ctx, recipient.Address, nonce)
+		return "", time.Time{}, err
+	}
+	return deviceToken, expiresAt, nil
+}
+
+func (m magicLinks) url(claims magicLinkClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	link, err := url.Parse(m.cfg.MagicLink.Url)
+	if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	query := link.Query()
+	query.Set("token", encoded+"."+m.sign(encoded))
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// parse checks the token's signature, expiry and device and returns whom it was sent to and the
+// nonce to spend. A link opened on another device is refused without spending it, so the
+// requester can still use it.
+func (m magicLinks) parse(token string, deviceToken string) (otpRecipient, string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(m.sign(encoded))) {
+		return otpRecipient{}, "", service_errors.New(service_errors.CodeMagicLinkInvalid)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return otpRecipient{}, "", service_errors.Wrap(service_errors.CodeMagicLinkInvalid, err)
+	}
+	var claims magicLinkClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return otpRecipient{}, "", service_errors.Wrap(service_errors.CodeMagicLinkInvalid, err)
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return otpRecipient{}, "", service_errors.New(service_errors.CodeMagicLinkExpired)
+	}
+	if claims.Device != "" && !hmac.Equal([]byte(claims.Device), []byte(deviceHash(deviceToken))) {
+		return otpRecipient{}, "", service_errors.New(service_errors.CodeMagicLinkOtherDevice)
+	}
+	return otpRecipient{Channel: claims.Channel, Address: claims.Address}, claims.Nonce, nil
+}
+
+func (m magicLinks) sign(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(m.cfg.MagicLink.SigningKey))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// magicLinkError reports an OtpProvider rejection of a link's nonce in link terms
+func magicLinkError(err error) error {
+	switch {
+	case errors.Is(err, service_errors.New(service_errors.CodeOtpExpired)):
+		return service_errors.New(service_errors.CodeMagicLinkExpired)
+	case errors.Is(err, service_errors.New(service_errors.CodeOtpUsed)):
+		return service_errors.New(service_errors.CodeMagicLinkUsed)
+	case errors.Is(err, service_errors.New(service_errors.CodeOtpInvalid)),
+		errors.Is(err, service_errors.New(service_errors.CodeOtpLocked)):
+		return service_errors.New(service_errors.CodeMagicLinkInvalid)
+	}
+	return err
+}
+
+// deviceHash keeps the device token itself out of the link, which travels through SMS and email
+func deviceHash(deviceToken string) string {
+	sum := sha256.Sum256([]byte(deviceToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+func randomToken(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// LoginByMagicLink spends the link's token and logs its recipient in like RegisterAndLogin does
+// with a code. deviceToken is the one SendMagicLink returned when links are bound to the device.
+func (u *UserUsecase) LoginByMagicLink(ctx context.Context, token string, deviceToken string) (_ *dto.TokenDetail, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.LoginByMagicLink")
+	defer tracing.End(span, &err)
+
+	recipient, nonce, err := u.magicLinks.parse(token, deviceToken)
+	if err != nil {
+		return nil, err
+	}
+	err = u.magicLinks.provider.ValidateOtp(ctx, recipient.Address, nonce)
+	u.auditor.recordValidation(ctx, recipient, metrics.PurposeMagicLink, err)
+	if err != nil {
+		return nil, magicLinkError(err)
+	}
+	u.abuseDetector.RecordVerified(ctx, recipient.Address, recipient.Channel)
+	return u.login(ctx, recipient)
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+// normalizeMobileNumber converts client input to the E.164 form used for storage, OTP keys,
+// rate limits and audit records, so 09121234567 and +989121234567 are the same user
+func normalizeMobileNumber(cfg *config.Config, mobileNumber string) (string, error) {
+	normalized, err := common.NormalizeMobileNumber(mobileNumber, &cfg.Phone)
+	if err != nil {
+		return "", service_errors.Wrap(service_errors.CodeInvalidMobile, err)
+	}
+	return normalized, nil
+}
+
+// mobileNumberFilter turns a list filter into a substring of stored E.164 numbers: complete
+// numbers are normalized, and a national trunk prefix such as the 0 of 0912 is dropped
+func mobileNumberFilter(cfg *config.Config, filter string) string {
+	if filter == "" {
+		return ""
+	}
+	if normalized, err := common.NormalizeMobileNumber(filter, &cfg.Phone); err == nil {
+		return normalized
+	}
+	if strings.HasPrefix(filter, "0") && !strings.HasPrefix(filter, "00") {
+		return strings.TrimPrefix(filter, "0")
+	}
+	return filter
+}
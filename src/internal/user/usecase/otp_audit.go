@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+// otpAuditor writes OTP audit records; failures are logged by the repository and never fail the request
+type otpAuditor struct {
+	repo repository.OtpAuditRepository
+}
+
+func (a otpAuditor) record(ctx context.Context, recipient otpRecipient, purpose string, event string, policy string) {
+	audit := model.OtpAudit{
+		Channel:   recipient.Channel,
+		Purpose:   purpose,
+		Event:     event,
+		Policy:    policy,
+		RequestId: requestid.FromContext(ctx),
+	}
+	if recipient.isPhone() {
+		audit.MobileNumber = recipient.Address
+	} else {
+		audit.Email = recipient.Address
+	}
+	_ = a.repo.CreateOtpAudit(ctx, audit)
+}
+
+// recordValidation counts and audits the outcome of an OTP check
+func (a otpAuditor) recordValidation(ctx context.Context, recipient otpRecipient, purpose string, err error) {
+	switch {
+	case err == nil:
+		metrics.OtpVerified.WithLabelValues(purpose).Inc()
+		a.record(ctx, recipient, purpose, model.OtpEventVerified, "")
+	case errors.Is(err, service_errors.New(service_errors.CodeOtpExpired)):
+		metrics.OtpExpired.WithLabelValues(purpose).Inc()
+		a.record(ctx, recipient, purpose, model.OtpEventExpired, "")
+	case errors.Is(err, service_errors.New(service_errors.CodeOtpLocked)):
+		metrics.OtpFailed.WithLabelValues(purpose).Inc()
+		a.record(ctx, recipient, purpose, model.OtpEventLocked, "")
+	default:
+		metrics.OtpFailed.WithLabelValues(purpose).Inc()
+		a.record(ctx, recipient, purpose, model.OtpEventFailed, "")
+	}
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+// otpRecipient is where a code goes: Address is the E.164 number for SMS and voice calls or the
+// lower-cased address for email, and doubles as the OTP, rate-limit and audit key
+type otpRecipient struct {
+	Channel string
+	Address string
+}
+
+// resolveRecipient picks the channel from the identifier the client sent; email wins when both are
+// set. A mobile number gets an SMS unless channel is "voice".
+func resolveRecipient(cfg *config.Config, mobileNumber string, email string, channel string) (otpRecipient, error) {
+	if email != "" {
+		address, err := common.NormalizeEmail(email)
+		if err != nil {
+			return otpRecipient{}, service_errors.Wrap(service_errors.CodeInvalidEmail, err)
+		}
+		return otpRecipient{Channel: models.OtpChannelEmail, Address: address}, nil
+	}
+	number, err := normalizeMobileNumber(cfg, mobileNumber)
+	if err != nil {
+		return otpRecipient{}, err
+	}
+	if channel == models.OtpChannelVoice {
+		return otpRecipient{Channel: models.OtpChannelVoice, Address: number}, nil
+	}
+	return otpRecipient{Channel: models.OtpChannelSms, Address: number}, nil
+}
+
+// isPhone reports whether the code goes to a phone number, by SMS or by voice call
+func (r otpRecipient) isPhone() bool {
+	return r.Channel != models.OtpChannelEmail
+}
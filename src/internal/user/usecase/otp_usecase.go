@@ -1,49 +1,316 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
 	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/challenge"
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/notification"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/policy"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
 	"github.com/alielmi98/golang-otp-auth/pkg/cache"
 	"github.com/alielmi98/golang-otp-auth/pkg/common"
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/i18n"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
 	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
 	"github.com/go-redis/redis/v7"
 )
 
 type OtpUsecase struct {
 	cfg              *config.Config
 	redisClient      *redis.Client
-	otpProvider      auth.OtpProvider
+	otpProvider      auth.ResendableOtpProvider
 	rateLimitService *ratelimit.OTPRateLimitService
+	// voiceRateLimitService is the separate, usually stricter, policy for voice calls
+	voiceRateLimitService *ratelimit.OTPRateLimitService
+	smsSender             notification.SmsSender
+	voiceCaller           notification.VoiceCaller
+	emailSender           notification.EmailSender
+	phonePolicy           policy.PhoneNumberPolicy
+	abuseDetector         policy.AbuseDetector
+	challengeGate         challengeGate
+	auditor               otpAuditor
+	magicLinks            magicLinks
+}
+
+// OtpDependencies are the collaborators of an OtpUsecase; naming them keeps the two rate limit
+// services from being swapped
+type OtpDependencies struct {
+	OtpProvider       auth.ResendableOtpProvider
+	MagicLinkProvider auth.RevocableOtpProvider
+	RateLimitService  *ratelimit.OTPRateLimitService
+	// VoiceRateLimitService is the separate, usually stricter, policy for voice calls
+	VoiceRateLimitService *ratelimit.OTPRateLimitService
+	SmsSender             notification.SmsSender
+	VoiceCaller           notification.VoiceCaller
+	EmailSender           notification.EmailSender
+	PhonePolicy           policy.PhoneNumberPolicy
+	AbuseDetector         policy.AbuseDetector
+	ChallengeVerifier     challenge.Verifier
+	AuditRepo             repository.OtpAuditRepository
 }
 
-func NewOtpUsecase(cfg *config.Config, otpProvider auth.OtpProvider, rateLimitService *ratelimit.OTPRateLimitService) *OtpUsecase {
+func NewOtpUsecase(cfg *config.Config, deps OtpDependencies) *OtpUsecase {
 	redis := cache.GetRedis()
 	return &OtpUsecase{
-		cfg:              cfg,
-		redisClient:      redis,
-		otpProvider:      otpProvider,
-		rateLimitService: rateLimitService,
+		cfg:                   cfg,
+		redisClient:           redis,
+		otpProvider:           deps.OtpProvider,
+		rateLimitService:      deps.RateLimitService,
+		voiceRateLimitService: deps.VoiceRateLimitService,
+		smsSender:             deps.SmsSender,
+		voiceCaller:           deps.VoiceCaller,
+		emailSender:           deps.EmailSender,
+		phonePolicy:           deps.PhonePolicy,
+		abuseDetector:         deps.AbuseDetector,
+		challengeGate: challengeGate{
+			cfg:      cfg,
+			verifier: deps.ChallengeVerifier,
+			limiter:  ratelimit.NewRedisRateLimiter(redis),
+		},
+		auditor:    otpAuditor{repo: deps.AuditRepo},
+		magicLinks: magicLinks{cfg: cfg, provider: deps.MagicLinkProvider},
+	}
+}
+
+// SendOtp sends a code to mobileNumber by SMS, or by voice call when channel is "voice", or by
+// email to email. It checks, in order, the phone policy (phone numbers only), the abuse score, a
+// challenge when one is called for and the channel's rate limit. challengeResponse may be empty
+// until a challenge is required. The response names the channel used, which is "voice" when a
+// failed SMS fell back to a call.
+func (u *OtpUsecase) SendOtp(ctx context.Context, mobileNumber string, email string, channel string, clientIp string, challengeResponse string) (_ dto.SendOtpResponse, err error) {
+	ctx, span := tracing.Start(ctx, "OtpUsecase.SendOtp")
+	defer tracing.End(span, &err)
+
+	recipient, err := resolveRecipient(u.cfg, mobileNumber, email, channel)
+	if err != nil {
+		return dto.SendOtpResponse{}, err
+	}
+	err = u.send(ctx, &recipient, clientIp, challengeResponse)
+	if err != nil {
+		return dto.SendOtpResponse{}, err
+	}
+	return dto.SendOtpResponse{Channel: recipient.Channel}, nil
+}
+
+// SendMagicLink sends a one-time login link by SMS to mobileNumber or by email to email, after the
+// same checks as SendOtp and counted against the same rate limit. With magicLink.sameDevice the
+// response carries the device token the link must be opened with.
+func (u *OtpUsecase) SendMagicLink(ctx context.Context, mobileNumber string, email string, clientIp string, challengeResponse string) (_ dto.MagicLinkResponse, err error) {
+	ctx, span := tracing.Start(ctx, "OtpUsecase.SendMagicLink")
+	defer tracing.End(span, &err)
+
+	recipient, err := resolveRecipient(u.cfg, mobileNumber, email, "")
+	if err != nil {
+		return dto.MagicLinkResponse{}, err
+	}
+	err = u.guard(ctx, recipient, metrics.PurposeMagicLink, clientIp, challengeResponse)
+	if err != nil {
+		return dto.MagicLinkResponse{}, err
+	}
+	deviceToken, expiresAt, err := u.magicLinks.issue(ctx, recipient, func(link string) error {
+		return u.deliverLink(ctx, recipient, link)
+	})
+	if err != nil {
+		return dto.MagicLinkResponse{}, err
 	}
+	metrics.OtpSent.WithLabelValues(metrics.PurposeMagicLink).Inc()
+	u.abuseDetector.RecordSent(ctx, recipient.Address, recipient.Channel)
+	u.auditor.record(ctx, recipient, metrics.PurposeMagicLink, model.OtpEventSent, "")
+	return dto.MagicLinkResponse{Channel: recipient.Channel, DeviceToken: deviceToken, ExpiresAt: expiresAt}, nil
 }
 
-func (u *OtpUsecase) SendOtp(mobileNumber string) error {
-	// Check rate limit before sending OTP
-	err := u.rateLimitService.CheckOTPRateLimit(mobileNumber)
+// send runs the checks and delivers the code; recipient is updated when delivery falls back to another channel
+func (u *OtpUsecase) send(ctx context.Context, recipient *otpRecipient, clientIp string, challengeResponse string) (err error) {
+	err = u.guard(ctx, *recipient, metrics.PurposeLogin, clientIp, challengeResponse)
 	if err != nil {
 		return err
 	}
 
 	// Generate and send OTP
-	otp := common.GenerateOtp()
-	print(otp) // TODO: send otp to user by sms
-	err = u.otpProvider.SetOtp(mobileNumber, otp)
+	otp := common.GenerateOtp(u.cfg.Otp.Digits)
+	policyName := ""
+	resent := false
+	err = u.otpProvider.SetOtp(ctx, recipient.Address, otp)
+	if errors.Is(err, service_errors.New(service_errors.CodeOtpExists)) && recipient.Channel == model.OtpChannelVoice {
+		// The SMS did not arrive: read the pending code in a call instead of refusing, already
+		// charged to the voice rate limit by guard
+		otp, err = u.otpProvider.PendingOtp(ctx, recipient.Address)
+		policyName = "voice_resend"
+		resent = true
+	}
+	if err != nil {
+		return err
+	}
+	err = u.deliver(ctx, *recipient, otp)
+	if err != nil && recipient.Channel == model.OtpChannelSms && u.cfg.Voice.FallbackOnSmsFailure {
+		err = u.fallbackToVoice(ctx, recipient, otp, err)
+		policyName = "sms_failed"
+	}
 	if err != nil {
+		// A code stored for this request never reached the user, so it must not hold off a retry
+		// until it expires; a resent code may still arrive by SMS and stays
+		if !resent {
+			u.otpProvider.RevokeOtp(ctx, recipient.Address, otp)
+		}
 		return err
 	}
+	metrics.OtpSent.WithLabelValues(metrics.PurposeLogin).Inc()
+	u.abuseDetector.RecordSent(ctx, recipient.Address, recipient.Channel)
+	u.auditor.record(ctx, *recipient, metrics.PurposeLogin, model.OtpEventSent, policyName)
 	return nil
 }
 
+// guard runs, in order, the phone policy (phone numbers only), the abuse score, a challenge when
+// one is called for and the channel's rate limit before anything is sent to recipient
+func (u *OtpUsecase) guard(ctx context.Context, recipient otpRecipient, purpose string, clientIp string, challengeResponse string) (err error) {
+	// Banned numbers and prefixes must not consume rate-limit quota or reach the gateway
+	if recipient.isPhone() {
+		err = u.phonePolicy.CheckPhoneNumber(ctx, recipient.Address)
+		if err != nil {
+			var serviceErr *service_errors.ServiceError
+			if errors.As(err, &serviceErr) && serviceErr.StatusCode() == http.StatusForbidden {
+				u.auditor.record(ctx, recipient, purpose, model.OtpEventBlocked, serviceErr.ErrorCode())
+			}
+			return err
+		}
+	}
+
+	assessment, err := u.abuseDetector.AssessSend(ctx, recipient.Address, recipient.Channel, clientIp)
+	if err != nil {
+		return err
+	}
+	switch assessment.Verdict {
+	case policy.VerdictThrottle:
+		u.auditor.record(ctx, recipient, purpose, model.OtpEventThrottled, "abuse:"+assessment.Signal)
+		return service_errors.New(service_errors.CodeOtpThrottled)
+	}
+	reason, err := u.challengeGate.reason(ctx, clientIp, assessment)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		err = u.challengeGate.verify(ctx, challengeResponse, clientIp)
+		switch {
+		case errors.Is(err, service_errors.New(service_errors.CodeChallenge)):
+			u.auditor.record(ctx, recipient, purpose, model.OtpEventChallenged, reason)
+		case errors.Is(err, service_errors.New(service_errors.CodeChallengeFailed)):
+			u.auditor.record(ctx, recipient, purpose, model.OtpEventChallengeFailed, reason)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check rate limit before sending
+	return u.checkRateLimit(ctx, recipient, purpose)
+}
+
+// checkRateLimit applies the recipient's channel policy; voice calls are limited on their own
+func (u *OtpUsecase) checkRateLimit(ctx context.Context, recipient otpRecipient, purpose string) error {
+	limiter := u.rateLimitService
+	if recipient.Channel == model.OtpChannelVoice {
+		limiter = u.voiceRateLimitService
+	}
+	err := limiter.CheckOTPRateLimit(ctx, recipient.Address)
+	if err != nil && ratelimit.IsLimitExceeded(err) {
+		u.auditor.record(ctx, recipient, purpose, model.OtpEventRateLimited, limiter.Policy())
+	}
+	return err
+}
+
+// fallbackToVoice reads the code already stored for the number in a call after the SMS gateway
+// failed. The SMS error is returned when the call is rate limited or fails too.
+func (u *OtpUsecase) fallbackToVoice(ctx context.Context, recipient *otpRecipient, otp string, smsErr error) error {
+	voice := otpRecipient{Channel: model.OtpChannelVoice, Address: recipient.Address}
+	if err := u.checkRateLimit(ctx, voice, metrics.PurposeLogin); err != nil {
+		return smsErr
+	}
+	if err := u.deliver(ctx, voice, otp); err != nil {
+		return smsErr
+	}
+	*recipient = voice
+	return nil
+}
+
+// deliver sends the code in the request's language over the recipient's channel
+func (u *OtpUsecase) deliver(ctx context.Context, recipient otpRecipient, otp string) (err error) {
+	defer func() {
+		if err != nil {
+			metrics.OtpDeliveryFailures.WithLabelValues(recipient.Channel).Inc()
+		}
+	}()
+	localizer := i18n.FromContext(ctx)
+	params := map[string]any{"code": otp}
+	switch recipient.Channel {
+	case model.OtpChannelSms:
+		return u.smsSender.SendSms(ctx, recipient.Address, localizer.MessageOr(i18n.SmsOtpKey, params, otp))
+	case model.OtpChannelVoice:
+		params = map[string]any{"digits": spacedDigits(otp)}
+		message := localizer.MessageOr(i18n.VoiceOtpKey, params, spacedDigits(otp))
+		return u.voiceCaller.Call(ctx, recipient.Address, message, localizer.Locale)
+	default:
+		subject := localizer.MessageOr(i18n.EmailOtpSubjectKey, nil, "Verification code")
+		return u.emailSender.SendEmail(ctx, recipient.Address, subject, localizer.MessageOr(i18n.EmailOtpKey, params, otp))
+	}
+}
+
+// deliverLink sends a login link in the request's language by SMS or email
+func (u *OtpUsecase) deliverLink(ctx context.Context, recipient otpRecipient, link string) (err error) {
+	defer func() {
+		if err != nil {
+			metrics.OtpDeliveryFailures.WithLabelValues(recipient.Channel).Inc()
+		}
+	}()
+	localizer := i18n.FromContext(ctx)
+	params := map[string]any{"link": link, "minutes": int(u.cfg.MagicLink.ExpireTime / 60)}
+	if recipient.Channel == model.OtpChannelSms {
+		return u.smsSender.SendSms(ctx, recipient.Address, localizer.MessageOr(i18n.SmsMagicLinkKey, params, link))
+	}
+	subject := localizer.MessageOr(i18n.EmailMagicLinkSubjectKey, nil, "Login link")
+	return u.emailSender.SendEmail(ctx, recipient.Address, subject, localizer.MessageOr(i18n.EmailMagicLinkKey, params, link))
+}
+
+// spacedDigits separates the digits of a code so text-to-speech reads 4 8 1 5 rather than
+// four thousand eight hundred fifteen
+func spacedDigits(code string) string {
+	return strings.Join(strings.Split(code, ""), " ")
+}
+
+// IssueChallenge returns a challenge for clients told CHALLENGE_REQUIRED
+func (u *OtpUsecase) IssueChallenge(ctx context.Context) (_ dto.ChallengeResponse, err error) {
+	ctx, span := tracing.Start(ctx, "OtpUsecase.IssueChallenge")
+	defer tracing.End(span, &err)
+
+	issued, err := u.challengeGate.verifier.Issue(ctx)
+	if err != nil {
+		return dto.ChallengeResponse{}, err
+	}
+	response := dto.ChallengeResponse{
+		Provider:   issued.Provider,
+		Challenge:  issued.Challenge,
+		Difficulty: issued.Difficulty,
+		SiteKey:    issued.SiteKey,
+	}
+	if !issued.ExpiresAt.IsZero() {
+		response.ExpiresAt = &issued.ExpiresAt
+	}
+	return response, nil
+}
+
 // GetOTPRateLimitInfo returns rate limit information for a mobile number
-func (u *OtpUsecase) GetOTPRateLimitInfo(mobileNumber string) (*ratelimit.OTPRateLimitInfo, error) {
-	return u.rateLimitService.GetRateLimitInfo(mobileNumber)
+func (u *OtpUsecase) GetOTPRateLimitInfo(ctx context.Context, mobileNumber string) (*ratelimit.OTPRateLimitInfo, error) {
+	mobileNumber, err := normalizeMobileNumber(u.cfg, mobileNumber)
+	if err != nil {
+		return nil, err
+	}
+	return u.rateLimitService.GetRateLimitInfo(ctx, mobileNumber)
 }
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/challenge"
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/policy"
+	"github.com/alielmi98/golang-otp-auth/internal/user/infra/auth"
+	"github.com/alielmi98/golang-otp-auth/internal/user/infra/notification"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+)
+
+const testMobileNumber = "+989121234567"
+
+var otpPattern = regexp.MustCompile(`[0-9]{6}`)
+
+type recordingSmsSender struct {
+	messages []string
+	// err, when set, is returned instead of sending
+	err error
+}
+
+func (s *recordingSmsSender) SendSms(ctx context.Context, mobileNumber string, message string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+type allowAll struct{}
+
+func (allowAll) CheckPhoneNumber(ctx context.Context, mobileNumber string) error { return nil }
+func (allowAll) AssessSend(ctx context.Context, recipient string, channel string, clientIp string) (policy.AbuseAssessment, error) {
+	return policy.AbuseAssessment{Verdict: policy.VerdictAllow}, nil
+}
+func (allowAll) RecordSent(ctx context.Context, recipient string, channel string)     {}
+func (allowAll) RecordVerified(ctx context.Context, recipient string, channel string) {}
+func (allowAll) Issue(ctx context.Context) (challenge.Challenge, error) {
+	return challenge.Challenge{}, nil
+}
+func (allowAll) Verify(ctx context.Context, response string, clientIp string) error { return nil }
+func (allowAll) CreateOtpAudit(ctx context.Context, audit model.OtpAudit) error     { return nil }
+func (allowAll) SendEmail(ctx context.Context, to string, subject string, body string) error {
+	return nil
+}
+
+func newTestOtpUsecase(t *testing.T, sms *recordingSmsSender, gateway *notification.StubVoiceGateway) (*OtpUsecase, *config.Config) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	host, port, err := net.SplitHostPort(mr.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	voiceServer := httptest.NewServer(gateway)
+	t.Cleanup(voiceServer.Close)
+
+	cfg := &config.Config{}
+	cfg.Logger.Level = "error"
+	logging.InitLogger(cfg)
+	cfg.Redis.Host, cfg.Redis.Port = host, port
+	cfg.Redis.PoolSize = 5
+	cfg.Redis.DialTimeout, cfg.Redis.ReadTimeout, cfg.Redis.WriteTimeout = 5, 5, 5
+	cfg.Otp.ExpireTime = 120
+	cfg.Otp.Digits = 6
+	cfg.Otp.MaxVerifyAttempts = 5
+	cfg.Voice.Url = voiceServer.URL
+	cfg.Voice.Timeout = 5
+	cfg.Voice.MaxCalls = 1
+	cfg.Voice.Window = 600
+	cfg.Challenge.IpFreeSends = 100
+	cfg.Challenge.IpWindow = 600
+	if err := cache.InitRedis(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cache.CloseRedis)
+
+	limiter := ratelimit.NewRedisRateLimiter(cache.GetRedis())
+	smsLimit := ratelimit.NewOTPRateLimitService(limiter, ratelimit.DefaultOTPConfig())
+	voiceLimit := ratelimit.NewOTPRateLimitService(limiter, ratelimit.OTPRateLimitConfig{
+		Policy: "otp_voice", KeyPrefix: "otp_voice", MaxAttempts: cfg.Voice.MaxCalls, Window: cfg.Voice.Window * time.Second,
+	})
+	usecase := NewOtpUsecase(cfg, OtpDependencies{
+		OtpProvider:           auth.NewOtpProvider(cfg),
+		MagicLinkProvider:     auth.NewMagicLinkProvider(cfg),
+		RateLimitService:      smsLimit,
+		VoiceRateLimitService: voiceLimit,
+		SmsSender:             sms,
+		VoiceCaller:           notification.NewHttpVoiceCaller(cfg),
+		EmailSender:           allowAll{},
+		PhonePolicy:           allowAll{},
+		AbuseDetector:         allowAll{},
+		ChallengeVerifier:     allowAll{},
+		AuditRepo:             allowAll{},
+	})
+	return usecase, cfg
+}
+
+// A user whose SMS never arrived asks for a call while the code is still pending: the same code is
+// read aloud, and the call counts against the voice rate limit
+func TestSendOtpVoiceRedeliversPendingSmsCode(t *testing.T) {
+	ctx := context.Background()
+	sms := &recordingSmsSender{}
+	gateway := notification.NewStubVoiceGateway()
+	usecase, _ := newTestOtpUsecase(t, sms, gateway)
+
+	if _, err := usecase.SendOtp(ctx, testMobileNumber, "", model.OtpChannelSms, "127.0.0.1", ""); err != nil {
+		t.Fatalf("send by sms: %v", err)
+	}
+	if len(sms.messages) != 1 {
+		t.Fatalf("got %d sms messages, want 1", len(sms.messages))
+	}
+
+	response, err := usecase.SendOtp(ctx, testMobileNumber, "", model.OtpChannelVoice, "127.0.0.1", "")
+	if err != nil {
+		t.Fatalf("send by voice: %v", err)
+	}
+	if response.Channel != model.OtpChannelVoice {
+		t.Errorf("got channel %q, want %q", response.Channel, model.OtpChannelVoice)
+	}
+	calls := gateway.Calls()
+	if len(calls) != 1 || calls[0].To != testMobileNumber {
+		t.Fatalf("got calls %+v, want one call to %s", calls, testMobileNumber)
+	}
+	code := otpPattern.FindString(sms.messages[0])
+	if code == "" || !strings.Contains(calls[0].Message, spacedDigits(code)) {
+		t.Errorf("call read %q, want the code sent by sms in %q", calls[0].Message, sms.messages[0])
+	}
+
+	if err = usecase.otpProvider.ValidateOtp(ctx, testMobileNumber, code); err != nil {
+		t.Errorf("validate the resent code: %v", err)
+	}
+
+	_, err = usecase.SendOtp(ctx, testMobileNumber, "", model.OtpChannelVoice, "127.0.0.1", "")
+	if !ratelimit.IsLimitExceeded(err) {
+		t.Errorf("second call: got %v, want the voice rate limit", err)
+	}
+	if len(gateway.Calls()) != 1 {
+		t.Errorf("got %d calls, want 1", len(gateway.Calls()))
+	}
+}
+
+// An SMS the gateway rejected must not leave its code behind, or the retry would get OTP_EXISTS
+// until the code expires
+func TestSendOtpSmsFailureAllowsRetry(t *testing.T) {
+	ctx := context.Background()
+	sms := &recordingSmsSender{err: errors.New("gateway unavailable")}
+	usecase, _ := newTestOtpUsecase(t, sms, notification.NewStubVoiceGateway())
+
+	if _, err := usecase.SendOtp(ctx, testMobileNumber, "", model.OtpChannelSms, "127.0.0.1", ""); err == nil {
+		t.Fatal("send with a failing gateway: got nil, want an error")
+	}
+
+	sms.err = nil
+	if _, err := usecase.SendOtp(ctx, testMobileNumber, "", model.OtpChannelSms, "127.0.0.1", ""); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(sms.messages) != 1 {
+		t.Fatalf("got %d sms messages, want 1", len(sms.messages))
+	}
+	if err := usecase.otpProvider.ValidateOtp(ctx, testMobileNumber, otpPattern.FindString(sms.messages[0])); err != nil {
+		t.Errorf("validate the retried code: %v", err)
+	}
+}
+
+// The same holds for a login link: a link that was never delivered must not block the next request
+func TestSendMagicLinkFailureAllowsRetry(t *testing.T) {
+	ctx := context.Background()
+	sms := &recordingSmsSender{err: errors.New("gateway unavailable")}
+	usecase, cfg := newTestOtpUsecase(t, sms, notification.NewStubVoiceGateway())
+	cfg.MagicLink.Url = "http://localhost:3000/login/magic"
+	cfg.MagicLink.SigningKey = "testMagicLinkSigningKey"
+	cfg.MagicLink.ExpireTime = 600
+
+	if _, err := usecase.SendMagicLink(ctx, testMobileNumber, "", "127.0.0.1", ""); err == nil {
+		t.Fatal("send with a failing gateway: got nil, want an error")
+	}
+
+	sms.err = nil
+	if _, err := usecase.SendMagicLink(ctx, testMobileNumber, "", "127.0.0.1", ""); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(sms.messages) != 1 || !strings.Contains(sms.messages[0], cfg.MagicLink.Url+"?token=") {
+		t.Errorf("got sms messages %q, want one with the link", sms.messages)
+	}
+}
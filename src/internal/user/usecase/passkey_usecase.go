@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/go-redis/redis/v7"
+)
+
+const passkeyKeyPrefix = "passkey"
+
+// PasskeyUsecase registers passkeys for logged-in users and logs users in with them. Ceremony
+// state waits in Redis for webAuthn.timeout seconds and is removed when the browser answers, so
+// each challenge is answered at most once.
+type PasskeyUsecase struct {
+	cfg         *config.Config
+	redisClient *redis.Client
+	userRepo    repository.UserRepository
+	passkeyRepo repository.PasskeyRepository
+	provider    auth.PasskeyProvider
+	token       auth.TokenProvider
+}
+
+func NewPasskeyUsecase(cfg *config.Config, userRepo repository.UserRepository, passkeyRepo repository.PasskeyRepository, provider auth.PasskeyProvider, token auth.TokenProvider) *PasskeyUsecase {
+	return &PasskeyUsecase{
+		cfg:         cfg,
+		redisClient: cache.GetRedis(),
+		userRepo:    userRepo,
+		passkeyRepo: passkeyRepo,
+		provider:    provider,
+		token:       token,
+	}
+}
+
+// BeginRegistration returns the creation options; a new call replaces an unfinished registration
+func (u *PasskeyUsecase) BeginRegistration(ctx context.Context, userId int) (_ dto.PasskeyOptions, err error) {
+	ctx, span := tracing.Start(ctx, "PasskeyUsecase.BeginRegistration")
+	defer tracing.End(span, &err)
+
+	options, session, err := u.provider.BeginRegistration(ctx, userId)
+	if err != nil {
+		return dto.PasskeyOptions{}, err
+	}
+	err = u.saveCeremony(ctx, registrationKey(userId), session)
+	if err != nil {
+		return dto.PasskeyOptions{}, err
+	}
+	return dto.PasskeyOptions{Options: options}, nil
+}
+
+func (u *PasskeyUsecase) FinishRegistration(ctx context.Context, userId int, name string, credential []byte) (_ dto.Passkey, err error) {
+	ctx, span := tracing.Start(ctx, "PasskeyUsecase.FinishRegistration")
+	defer tracing.End(span, &err)
+
+	session, err := u.takeCeremony(ctx, registrationKey(userId))
+	if err != nil {
+		return dto.Passkey{}, err
+	}
+	passkey, err := u.provider.FinishRegistration(ctx, userId, name, session, credential)
+	if err != nil {
+		metrics.PasskeyCeremonies.WithLabelValues(metrics.CeremonyRegistration, metrics.ResultFailure).Inc()
+		return dto.Passkey{}, err
+	}
+	metrics.PasskeyCeremonies.WithLabelValues(metrics.CeremonyRegistration, metrics.ResultSuccess).Inc()
+	return toPasskeyDto(passkey), nil
+}
+
+func (u *PasskeyUsecase) GetPasskeys(ctx context.Context, userId int) (_ []dto.Passkey, err error) {
+	ctx, span := tracing.Start(ctx, "PasskeyUsecase.GetPasskeys")
+	defer tracing.End(span, &err)
+
+	passkeys, err := u.passkeyRepo.GetPasskeys(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.Passkey, len(passkeys))
+	for i, passkey := range passkeys {
+		result[i] = toPasskeyDto(passkey)
+	}
+	return result, nil
+}
+
+func (u *PasskeyUsecase) DeletePasskey(ctx context.Context, userId int, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "PasskeyUsecase.DeletePasskey")
+	defer tracing.End(span, &err)
+
+	return u.passkeyRepo.DeletePasskey(ctx, userId, id)
+}
+
+// BeginLogin returns request options for any passkey of this site; the browser lets the user pick
+func (u *PasskeyUsecase) BeginLogin(ctx context.Context) (_ dto.PasskeyOptions, err error) {
+	ctx, span := tracing.Start(ctx, "PasskeyUsecase.BeginLogin")
+	defer tracing.End(span, &err)
+
+	options, session, err := u.provider.BeginLogin(ctx)
+	if err != nil {
+		return dto.PasskeyOptions{}, err
+	}
+	raw := make([]byte, 32)
+	if _, err = rand.Read(raw); err != nil {
+		return dto.PasskeyOptions{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	sessionId := base64.RawURLEncoding.EncodeToString(raw)
+	err = u.saveCeremony(ctx, loginKey(sessionId), session)
+	if err != nil {
+		return dto.PasskeyOptions{}, err
+	}
+	return dto.PasskeyOptions{SessionId: sessionId, Options: options}, nil
+}
+
+// Login verifies the assertion and issues the same tokens as the OTP login. A passkey with user
+// verification already proves two factors, so no second factor is asked for.
+func (u *PasskeyUsecase) Login(ctx context.Context, sessionId string, credential []byte) (_ *dto.TokenDetail, err error) {
+	ctx, span := tracing.Start(ctx, "PasskeyUsecase.Login")
+	defer tracing.End(span, &err)
+
+	session, err := u.takeCeremony(ctx, loginKey(sessionId))
+	if err != nil {
+		return nil, err
+	}
+	userId, err := u.provider.FinishLogin(ctx, session, credential)
+	if err != nil {
+		metrics.PasskeyCeremonies.WithLabelValues(metrics.CeremonyLogin, metrics.ResultFailure).Inc()
+		return nil, err
+	}
+	metrics.PasskeyCeremonies.WithLabelValues(metrics.CeremonyLogin, metrics.ResultSuccess).Inc()
+	user, err := u.userRepo.FetchUserInfoById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return generateToken(ctx, u.token, &user)
+}
+
+func (u *PasskeyUsecase) saveCeremony(ctx context.Context, key string, session []byte) error {
+	err := u.redisClient.WithContext(ctx).Set(key, session, u.cfg.WebAuthn.Timeout*time.Second).Err()
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return nil
+}
+
+// takeCeremony reads and removes the ceremony state in one transaction
+func (u *PasskeyUsecase) takeCeremony(ctx context.Context, key string) ([]byte, error) {
+	pipe := u.redisClient.WithContext(ctx).TxPipeline()
+	get := pipe.Get(key)
+	pipe.Del(key)
+	_, err := pipe.Exec()
+	if err == redis.Nil {
+		return nil, service_errors.New(service_errors.CodePasskeyExpired)
+	} else if err != nil {
+		return nil, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return get.Bytes()
+}
+
+func registrationKey(userId int) string {
+	return fmt.Sprintf("%s:register:%s", passkeyKeyPrefix, strconv.Itoa(userId))
+}
+
+func loginKey(sessionId string) string {
+	return fmt.Sprintf("%s:login:%s", passkeyKeyPrefix, sessionId)
+}
+
+func toPasskeyDto(passkey model.Passkey) dto.Passkey {
+	result := dto.Passkey{Id: passkey.Id, Name: passkey.Name, CreatedAt: passkey.CreatedAt}
+	if passkey.LastUsedAt.Valid {
+		result.LastUsedAt = &passkey.LastUsedAt.Time
+	}
+	return result
+}
@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/i18n"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/go-redis/redis/v7"
+)
+
+const passwordFailPrefix = "password_fail"
+
+// LoginByPassword logs in a user who set a password. Unknown identifiers, users without a password
+// and wrong passwords all answer INVALID_CREDENTIALS. Users with a second factor get an mfa token.
+func (u *UserUsecase) LoginByPassword(ctx context.Context, mobileNumber string, email string, password string, clientIp string) (_ *dto.TokenDetail, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.LoginByPassword")
+	defer tracing.End(span, &err)
+
+	recipient, err := resolveRecipient(u.cfg, mobileNumber, email, "")
+	if err != nil {
+		return nil, err
+	}
+	err = u.loginLimiter.check(ctx, recipient.Address, clientIp)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := u.existsRecipient(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		// Spend the same time as a real check so response times do not reveal registered users
+		_, err = u.hasher.check(ctx, "", password)
+		if err != nil {
+			return nil, err
+		}
+		metrics.PasswordLogins.WithLabelValues(metrics.ResultFailure).Inc()
+		return nil, service_errors.New(service_errors.CodeInvalidCredentials)
+	}
+	user, err := u.fetchUserInfo(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	err = u.lockout.attempt(ctx, user.Id)
+	if err != nil {
+		metrics.PasswordLogins.WithLabelValues(metrics.ResultLocked).Inc()
+		return nil, err
+	}
+
+	ok, err := u.hasher.check(ctx, user.Password, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		metrics.PasswordLogins.WithLabelValues(metrics.ResultFailure).Inc()
+		return nil, service_errors.New(service_errors.CodeInvalidCredentials)
+	}
+	u.lockout.reset(ctx, user.Id)
+	metrics.PasswordLogins.WithLabelValues(metrics.ResultSuccess).Inc()
+	methods, err := u.secondFactor.methods(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		return u.secondFactor.begin(ctx, user.Id, methods)
+	}
+	return generateToken(ctx, u.token, &user)
+}
+
+// SetPassword sets the password of a user logged in with an OTP; replacing an existing password
+// also needs the current one
+func (u *UserUsecase) SetPassword(ctx context.Context, userId int, currentPassword string, password string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.SetPassword")
+	defer tracing.End(span, &err)
+
+	user, err := u.repo.FetchUserInfoById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if user.Password != "" {
+		ok, err := u.hasher.check(ctx, user.Password, currentPassword)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return service_errors.New(service_errors.CodePasswordRequired)
+		}
+	}
+	return u.storePassword(ctx, user, password)
+}
+
+// ResetPassword replaces a forgotten password once the code sent by send-otp is verified
+func (u *UserUsecase) ResetPassword(ctx context.Context, mobileNumber string, email string, otp string, password string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ResetPassword")
+	defer tracing.End(span, &err)
+
+	recipient, err := resolveRecipient(u.cfg, mobileNumber, email, "")
+	if err != nil {
+		return err
+	}
+	err = u.otpProvider.ValidateOtp(ctx, recipient.Address, otp)
+	u.auditor.recordValidation(ctx, recipient, metrics.PurposePasswordReset, err)
+	if err != nil {
+		return err
+	}
+	exists, err := u.existsRecipient(ctx, recipient)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return service_errors.New(service_errors.CodeRecordNotFound)
+	}
+	user, err := u.fetchUserInfo(ctx, recipient)
+	if err != nil {
+		return err
+	}
+	err = u.storePassword(ctx, user, password)
+	if err != nil {
+		return err
+	}
+	u.lockout.reset(ctx, user.Id)
+	return nil
+}
+
+func (u *UserUsecase) storePassword(ctx context.Context, user model.User, password string) error {
+	err := checkPasswordStrength(u.cfg, user, password)
+	if err != nil {
+		return err
+	}
+	hash, err := u.hasher.hash(ctx, password)
+	if err != nil {
+		logging.GetLogger().WithContext(ctx).Error(constants.Internal, constants.HashPassword, "hash password failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	err = u.repo.Update(ctx, user.Id, &model.User{Password: hash, ModifiedAt: sql.NullTime{Time: time.Now(), Valid: true}})
+	if err != nil {
+		return service_errors.Wrap(service_errors.CodeDatabase, err)
+	}
+	return nil
+}
+
+// checkPasswordStrength applies the password policy: length, character classes and not containing
+// the user's own identifiers
+func checkPasswordStrength(cfg *config.Config, user model.User, password string) error {
+	length := len([]rune(password))
+	weak := length < cfg.Password.MinLength || length > cfg.Password.MaxLength ||
+		common.PasswordClasses(password) < cfg.Password.MinClasses
+	lower := strings.ToLower(password)
+	// The last eight digits catch the number in national and international form alike
+	if number := user.MobileNumber; len(number) >= 8 && strings.Contains(lower, number[len(number)-8:]) {
+		weak = true
+	}
+	if local, _, ok := strings.Cut(user.Email, "@"); ok && len(local) >= 3 && strings.Contains(lower, local) {
+		weak = true
+	}
+	if !weak {
+		return nil
+	}
+	serviceErr := service_errors.New(service_errors.CodeWeakPassword)
+	serviceErr.Params = map[string]any{
+		"minLength":  cfg.Password.MinLength,
+		"maxLength":  cfg.Password.MaxLength,
+		"minClasses": cfg.Password.MinClasses,
+	}
+	serviceErr.EndUserMessage = i18n.Default.MessageOr(string(service_errors.CodeWeakPassword), serviceErr.Params, serviceErr.EndUserMessage)
+	return serviceErr
+}
+
+// passwordLockout counts password attempts per user in Redis. Each attempt restarts the
+// password.lockoutWindow; once more than password.maxAttempts are counted, password login is locked
+// until it ends. A successful login clears the count. OTP login stays open, so guessing at
+// someone's password cannot lock them out of the account.
+type passwordLockout struct {
+	cfg         *config.Config
+	redisClient *redis.Client
+}
+
+// attempt counts an attempt before the password is checked, so concurrent guesses cannot all read
+// the same count and get past the limit
+func (l passwordLockout) attempt(ctx context.Context, userId int) error {
+	pipe := l.redisClient.WithContext(ctx).TxPipeline()
+	attempts := pipe.Incr(passwordFailKey(userId))
+	pipe.Expire(passwordFailKey(userId), l.cfg.Password.LockoutWindow*time.Second)
+	if _, err := pipe.Exec(); err != nil {
+		return service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	if attempts.Val() <= int64(l.cfg.Password.MaxAttempts) {
+		return nil
+	}
+	serviceErr := service_errors.New(service_errors.CodePasswordLocked)
+	serviceErr.Params = map[string]any{"resetTime": time.Now().Add(l.cfg.Password.LockoutWindow * time.Second)}
+	serviceErr.EndUserMessage = i18n.Default.MessageOr(string(service_errors.CodePasswordLocked), serviceErr.Params, serviceErr.EndUserMessage)
+	return serviceErr
+}
+
+func (l passwordLockout) reset(ctx context.Context, userId int) {
+	l.redisClient.WithContext(ctx).Del(passwordFailKey(userId))
+}
+
+func passwordFailKey(userId int) string {
+	return fmt.Sprintf("%s:%s", passwordFailPrefix, strconv.Itoa(userId))
+}
+
+// passwordLoginLimiter rate limits password logins per IP address and per identifier. It runs
+// before any hashing, so unknown identifiers cannot be used to keep argon2id busy.
+type passwordLoginLimiter struct {
+	identifier *ratelimit.OTPRateLimitService
+	ip         *ratelimit.OTPRateLimitService
+}
+
+func (l passwordLoginLimiter) check(ctx context.Context, identifier string, clientIp string) error {
+	err := l.ip.CheckOTPRateLimit(ctx, clientIp)
+	if err != nil {
+		return err
+	}
+	return l.identifier.CheckOTPRateLimit(ctx, identifier)
+}
+
+// passwordHasher runs at most password.maxConcurrentHashes argon2id hashes at once, since each one
+// holds password.memory KiB until it finishes. Further callers wait for a slot.
+type passwordHasher struct {
+	cfg   *config.Config
+	slots chan struct{}
+	// dummyHash is checked against when there is no real hash, to keep timing uniform
+	dummyHash string
+}
+
+func newPasswordHasher(cfg *config.Config) passwordHasher {
+	dummyHash, _ := common.HashPassword(passwordFailPrefix, cfg.Password.Memory, cfg.Password.Iterations, cfg.Password.Parallelism)
+	return passwordHasher{cfg: cfg, slots: make(chan struct{}, max(cfg.Password.MaxConcurrentHashes, 1)), dummyHash: dummyHash}
+}
+
+// check reports whether password matches encoded; an empty encoded is checked against the dummy
+// hash and never matches
+func (h passwordHasher) check(ctx context.Context, encoded string, password string) (bool, error) {
+	if err := h.acquire(ctx); err != nil {
+		return false, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	defer h.release()
+	if encoded == "" {
+		common.CheckPassword(h.dummyHash, password)
+		return false, nil
+	}
+	ok, err := common.CheckPassword(encoded, password)
+	if err != nil {
+		return false, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return ok, nil
+}
+
+func (h passwordHasher) hash(ctx context.Context, password string) (string, error) {
+	if err := h.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer h.release()
+	return common.HashPassword(password, h.cfg.Password.Memory, h.cfg.Password.Iterations, h.cfg.Password.Parallelism)
+}
+
+func (h passwordHasher) acquire(ctx context.Context) error {
+	select {
+	case h.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h passwordHasher) release() {
+	<-h.slots
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/go-redis/redis/v7"
+)
+
+func TestPasswordLockoutThreshold(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{}
+	cfg.Password.MaxAttempts = 3
+	cfg.Password.LockoutWindow = 900
+	lockout := passwordLockout{cfg: cfg, redisClient: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+
+	for i := 1; i <= cfg.Password.MaxAttempts; i++ {
+		if err := lockout.attempt(ctx, 7); err != nil {
+			t.Fatalf("attempt %d: got %v, want it counted", i, err)
+		}
+	}
+	if err := lockout.attempt(ctx, 7); !errors.Is(err, service_errors.New(service_errors.CodePasswordLocked)) {
+		t.Errorf("attempt past the limit: got %v, want PASSWORD_LOCKED", err)
+	}
+	if err := lockout.attempt(ctx, 8); err != nil {
+		t.Errorf("another user: got %v, want no lockout", err)
+	}
+
+	lockout.reset(ctx, 7)
+	if err := lockout.attempt(ctx, 7); err != nil {
+		t.Errorf("after reset: got %v, want the count cleared", err)
+	}
+	mr.FastForward(cfg.Password.LockoutWindow * time.Second)
+	if mr.Exists(passwordFailKey(7)) {
+		t.Error("count outlived password.lockoutWindow")
+	}
+}
+
+func TestCheckPasswordStrength(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Password.MinLength = 10
+	cfg.Password.MaxLength = 20
+	cfg.Password.MinClasses = 3
+	user := model.User{MobileNumber: "+989121234567", Email: "sara.ahmadi@example.com"}
+
+	tests := []struct {
+		name     string
+		password string
+		weak     bool
+	}{
+		{"strong", "Blue-Fox-Runs-9", false},
+		{"too short", "Ab1!", true},
+		{"too long", "Blue-Fox-Runs-9-Blue-Fox", true},
+		{"too few classes", "bluefoxruns99", true},
+		{"contains the number", "Pass-21234567", true},
+		{"contains the email name", "Sara.Ahmadi-99", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPasswordStrength(cfg, user, tt.password)
+			if weak := errors.Is(err, service_errors.New(service_errors.CodeWeakPassword)); weak != tt.weak {
+				t.Errorf("got %v, want weak %v", err, tt.weak)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/go-redis/redis/v7"
+	"github.com/skip2/go-qrcode"
+)
+
+const qrLoginKeyPrefix = "qr_login"
+
+// QR login states; approved and denied are final
+const (
+	QrLoginPending  = "pending"
+	QrLoginApproved = "approved"
+	QrLoginDenied   = "denied"
+)
+
+// QrLoginUsecase lets a logged-in phone approve a desktop login by scanning its QR code. The
+// pending login lives in Redis until it expires or the desktop collects the answer, and answers
+// are published so waiting polls return at once.
+type QrLoginUsecase struct {
+	cfg         *config.Config
+	redisClient *redis.Client
+	userRepo    repository.UserRepository
+	token       auth.TokenProvider
+	limiter     *ratelimit.OTPRateLimitService
+	logger      logging.Logger
+}
+
+type qrLoginState struct {
+	Status string
+	// PollHash is the hash of the desktop's poll token; the code alone, which anyone near the
+	// screen can read, must not be enough to collect the tokens
+	PollHash  string
+	UserId    int
+	ClientIp  string
+	UserAgent string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func NewQrLoginUsecase(cfg *config.Config, userRepo repository.UserRepository, token auth.TokenProvider, limiter *ratelimit.OTPRateLimitService) *QrLoginUsecase {
+	return &QrLoginUsecase{
+		cfg:         cfg,
+		redisClient: cache.GetRedis(),
+		userRepo:    userRepo,
+		token:       token,
+		limiter:     limiter,
+		logger:      logging.GetLogger(),
+	}
+}
+
+// Create starts a pending login for the desktop at clientIp
+func (u *QrLoginUsecase) Create(ctx context.Context, clientIp string, userAgent string) (_ dto.QrLogin, err error) {
+	ctx, span := tracing.Start(ctx, "QrLoginUsecase.Create")
+	defer tracing.End(span, &err)
+
+	err = u.limiter.CheckOTPRateLimit(ctx, clientIp)
+	if err != nil {
+		return dto.QrLogin{}, err
+	}
+	code, err := randomToken(32)
+	if err != nil {
+		return dto.QrLogin{}, err
+	}
+	pollToken, err := randomToken(32)
+	if err != nil {
+		return dto.QrLogin{}, err
+	}
+	now := time.Now()
+	state := qrLoginState{
+		Status:    QrLoginPending,
+		PollHash:  pollHash(pollToken),
+		ClientIp:  clientIp,
+		UserAgent: userAgent,
+		CreatedAt: now,
+		ExpiresAt: now.Add(u.cfg.QrLogin.ExpireTime * time.Second),
+	}
+	err = cache.Set(ctx, u.redisClient, qrLoginKey(code), state, u.cfg.QrLogin.ExpireTime*time.Second)
+	if err != nil {
+		return dto.QrLogin{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+
+	payload := u.cfg.QrLogin.PayloadPrefix + code
+	png, err := qrcode.Encode(payload, qrcode.Medium, u.cfg.QrLogin.QrSize)
+	if err != nil {
+		return dto.QrLogin{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return dto.QrLogin{
+		Code:      code,
+		PollToken: pollToken,
+		QrPayload: payload,
+		QrPng:     base64.StdEncoding.EncodeToString(png),
+		ExpiresAt: state.ExpiresAt,
+	}, nil
+}
+
+// Get describes a pending login so the phone can show which desktop asks before answering
+func (u *QrLoginUsecase) Get(ctx context.Context, code string) (_ dto.QrLoginInfo, err error) {
+	ctx, span := tracing.Start(ctx, "QrLoginUsecase.Get")
+	defer tracing.End(span, &err)
+
+	state, err := u.load(ctx, code)
+	if err != nil {
+		return dto.QrLoginInfo{}, err
+	}
+	if state.Status != QrLoginPending {
+		return dto.QrLoginInfo{}, service_errors.New(service_errors.CodeQrLoginAnswered)
+	}
+	return dto.QrLoginInfo{
+		ClientIp:  state.ClientIp,
+		UserAgent: state.UserAgent,
+		CreatedAt: state.CreatedAt,
+		ExpiresAt: state.ExpiresAt,
+	}, nil
+}
+
+// Answer approves the login for userId, or denies it. Only the first answer counts.
+func (u *QrLoginUsecase) Answer(ctx context.Context, userId int, code string, approve bool) (err error) {
+	ctx, span := tracing.Start(ctx, "QrLoginUsecase.Answer")
+	defer tracing.End(span, &err)
+
+	key := qrLoginKey(code)
+	status := QrLoginDenied
+	if approve {
+		status = QrLoginApproved
+	}
+	client := u.redisClient.WithContext(ctx)
+	err = client.Watch(func(tx *redis.Tx) error {
+		raw, err := tx.Get(key).Bytes()
+		if err == redis.Nil {
+			return service_errors.New(service_errors.CodeQrLoginExpired)
+		} else if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		var state qrLoginState
+		if err = json.Unmarshal(raw, &state); err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		if state.Status != QrLoginPending {
+			return service_errors.New(service_errors.CodeQrLoginAnswered)
+		}
+		state.Status = status
+		if approve {
+			state.UserId = userId
+		}
+		raw, err = json.Marshal(state)
+		if err != nil {
+			return service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		// Keep the expiry the login was created with
+		ttl := time.Until(state.ExpiresAt)
+		if ttl <= 0 {
+			return service_errors.New(service_errors.CodeQrLoginExpired)
+		}
+		_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+			pipe.Set(key, raw, ttl)
+			return nil
+		})
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		return service_errors.New(service_errors.CodeQrLoginAnswered)
+	} else if err != nil {
+		return err
+	}
+
+	result := metrics.ResultDenied
+	if approve {
+		result = metrics.ResultApproved
+	}
+	metrics.QrLogins.WithLabelValues(result).Inc()
+	if err := client.Publish(key, status).Err(); err != nil {
+		// Waiting polls still see the answer on their next request
+		u.logger.WithContext(ctx).Warn(constants.Redis, constants.Publish, "publish qr login answer failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+	return nil
+}
+
+// Poll waits up to qrLogin.pollTimeout seconds for the phone's answer. An approved login returns
+// the user's tokens once; later polls answer QR_LOGIN_EXPIRED.
+func (u *QrLoginUsecase) Poll(ctx context.Context, code string, pollToken string) (_ dto.QrLoginStatus, err error) {
+	ctx, span := tracing.Start(ctx, "QrLoginUsecase.Poll")
+	defer tracing.End(span, &err)
+
+	key := qrLoginKey(code)
+	// Subscribe before reading the state so an answer in between is not missed
+	pubsub := u.redisClient.Subscribe(key)
+	defer pubsub.Close()
+	if _, err = pubsub.ReceiveTimeout(u.cfg.Redis.ReadTimeout * time.Second); err != nil {
+		return dto.QrLoginStatus{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+
+	state, err := u.load(ctx, code)
+	if err != nil {
+		return dto.QrLoginStatus{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(state.PollHash), []byte(pollHash(pollToken))) != 1 {
+		return dto.QrLoginStatus{}, service_errors.New(service_errors.CodeQrLoginExpired)
+	}
+	if state.Status == QrLoginPending {
+		timer := time.NewTimer(u.cfg.QrLogin.PollTimeout * time.Second)
+		defer timer.Stop()
+		select {
+		case <-pubsub.Channel():
+		case <-timer.C:
+			return dto.QrLoginStatus{Status: QrLoginPending}, nil
+		case <-ctx.Done():
+			return dto.QrLoginStatus{}, ctx.Err()
+		}
+	}
+	return u.collect(ctx, key)
+}
+
+// collect removes an answered login and returns its outcome
+func (u *QrLoginUsecase) collect(ctx context.Context, key string) (dto.QrLoginStatus, error) {
+	pipe := u.redisClient.WithContext(ctx).TxPipeline()
+	get := pipe.Get(key)
+	pipe.Del(key)
+	_, err := pipe.Exec()
+	if err == redis.Nil {
+		return dto.QrLoginStatus{}, service_errors.New(service_errors.CodeQrLoginExpired)
+	} else if err != nil {
+		return dto.QrLoginStatus{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	var state qrLoginState
+	if err = json.Unmarshal([]byte(get.Val()), &state); err != nil {
+		return dto.QrLoginStatus{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	if state.Status != QrLoginApproved {
+		return dto.QrLoginStatus{Status: state.Status}, nil
+	}
+	user, err := u.userRepo.FetchUserInfoById(ctx, state.UserId)
+	if err != nil {
+		return dto.QrLoginStatus{}, err
+	}
+	// The phone's session already passed any second factor, so none is asked for
+	token, err := generateToken(ctx, u.token, &user)
+	if err != nil {
+		return dto.QrLoginStatus{}, err
+	}
+	return dto.QrLoginStatus{Status: QrLoginApproved, Token: token}, nil
+}
+
+func (u *QrLoginUsecase) load(ctx context.Context, code string) (qrLoginState, error) {
+	state, err := cache.Get[qrLoginState](ctx, u.redisClient, qrLoginKey(code))
+	if err == redis.Nil {
+		return qrLoginState{}, service_errors.New(service_errors.CodeQrLoginExpired)
+	} else if err != nil {
+		return qrLoginState{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return state, nil
+}
+
+func qrLoginKey(code string) string {
+	return fmt.Sprintf("%s:%s", qrLoginKeyPrefix, code)
+}
+
+func pollHash(pollToken string) string {
+	sum := sha256.Sum256([]byte(pollToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
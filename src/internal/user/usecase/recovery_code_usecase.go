@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+)
+
+// RecoveryCodeUsecase reports and regenerates the recovery codes of a second-factor user
+type RecoveryCodeUsecase struct {
+	totpRepo repository.TotpRepository
+	check    factorCheck
+}
+
+func NewRecoveryCodeUsecase(cfg *config.Config, totpRepo repository.TotpRepository, recoveryRepo repository.RecoveryCodeRepository, totpProvider auth.OtpProvider, limiter *ratelimit.OTPRateLimitService) *RecoveryCodeUsecase {
+	return &RecoveryCodeUsecase{
+		totpRepo: totpRepo,
+		check: factorCheck{
+			totp:     totpVerifier{provider: totpProvider, limiter: limiter},
+			recovery: recoveryCodes{cfg: cfg, repo: recoveryRepo, limiter: limiter},
+		},
+	}
+}
+
+// Remaining returns how many unused codes the user has left
+func (u *RecoveryCodeUsecase) Remaining(ctx context.Context, userId int) (_ dto.RecoveryCodes, err error) {
+	ctx, span := tracing.Start(ctx, "RecoveryCodeUsecase.Remaining")
+	defer tracing.End(span, &err)
+
+	remaining, err := u.check.recovery.repo.CountRecoveryCodes(ctx, userId)
+	if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	return dto.RecoveryCodes{Remaining: remaining}, nil
+}
+
+// Regenerate replaces every code, used or not, with a new set once the caller proved the
+// second factor
+func (u *RecoveryCodeUsecase) Regenerate(ctx context.Context, userId int, code string, recoveryCode string) (_ dto.RecoveryCodes, err error) {
+	ctx, span := tracing.Start(ctx, "RecoveryCodeUsecase.Regenerate")
+	defer tracing.End(span, &err)
+
+	credential, err := u.totpRepo.GetTotpCredential(ctx, userId)
+	if errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) || (err == nil && !credential.Confirmed) {
+		return dto.RecoveryCodes{}, service_errors.New(service_errors.CodeTotpNotEnrolled)
+	} else if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	err = u.check.verify(ctx, userId, code, recoveryCode)
+	if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	codes, err := u.check.recovery.generate(ctx, userId)
+	if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	return dto.RecoveryCodes{Codes: codes, Remaining: len(codes)}, nil
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/go-redis/redis/v7"
+)
+
+// Second-factor methods listed in TokenDetail.MfaMethods
+const (
+	MfaMethodTotp         = "totp"
+	MfaMethodRecoveryCode = "recovery_code"
+)
+
+const mfaKeyPrefix = "mfa"
+
+type mfaState struct {
+	UserId int
+}
+
+// secondFactor holds a login between the first factor and the authenticator or recovery code.
+// The mfa token is opaque, lives in Redis for totp.mfaTokenTtl seconds and is removed once used.
+type secondFactor struct {
+	cfg         *config.Config
+	redisClient *redis.Client
+	totpRepo    repository.TotpRepository
+	check       factorCheck
+}
+
+// methods lists the second factors the user must choose from; none means the first factor is enough
+func (f secondFactor) methods(ctx context.Context, userId int) ([]string, error) {
+	credential, err := f.totpRepo.GetTotpCredential(ctx, userId)
+	if errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !credential.Confirmed {
+		return nil, nil
+	}
+	methods := []string{MfaMethodTotp}
+	remaining, err := f.check.recovery.repo.CountRecoveryCodes(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		methods = append(methods, MfaMethodRecoveryCode)
+	}
+	return methods, nil
+}
+
+func (f secondFactor) begin(ctx context.Context, userId int, methods []string) (*dto.TokenDetail, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	err := cache.Set(ctx, f.redisClient, mfaKey(token), mfaState{UserId: userId}, f.cfg.Totp.MfaTokenTtl*time.Second)
+	if err != nil {
+		return nil, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return &dto.TokenDetail{MfaRequired: true, MfaToken: token, MfaMethods: methods}, nil
+}
+
+// complete checks the authenticator or recovery code for the login behind mfaToken and
+// returns its user id
+func (f secondFactor) complete(ctx context.Context, mfaToken string, code string, recoveryCode string) (int, error) {
+	state, err := cache.Get[mfaState](ctx, f.redisClient, mfaKey(mfaToken))
+	if errors.Is(err, redis.Nil) {
+		return 0, service_errors.New(service_errors.CodeMfaTokenInvalid)
+	} else if err != nil {
+		return 0, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	err = f.check.verify(ctx, state.UserId, code, recoveryCode)
+	if err != nil {
+		return 0, err
+	}
+	// Only one request may turn the token into a session
+	deleted, err := f.redisClient.WithContext(ctx).Del(mfaKey(mfaToken)).Result()
+	if err != nil {
+		return 0, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	if deleted == 0 {
+		return 0, service_errors.New(service_errors.CodeMfaTokenInvalid)
+	}
+	return state.UserId, nil
+}
+
+func mfaKey(token string) string {
+	return fmt.Sprintf("%s:%s", mfaKeyPrefix, token)
+}
+
+// totpVerifier checks authenticator codes behind a per-user limit, so neither login nor the
+// enrollment endpoints allow guessing the 10^digits codes
+type totpVerifier struct {
+	provider auth.OtpProvider
+	limiter  *ratelimit.OTPRateLimitService
+}
+
+func (v totpVerifier) verify(ctx context.Context, userId int, code string) error {
+	subject := strconv.Itoa(userId)
+	err := v.limiter.CheckOTPRateLimit(ctx, subject)
+	if err != nil {
+		return err
+	}
+	err = v.provider.ValidateOtp(ctx, subject, code)
+	if err != nil {
+		metrics.OtpFailed.WithLabelValues(metrics.PurposeSecondFactor).Inc()
+		return err
+	}
+	metrics.OtpVerified.WithLabelValues(metrics.PurposeSecondFactor).Inc()
+	return nil
+}
+
+// recoveryCodes issues and redeems the one-time codes that replace a lost authenticator.
+// Redeeming shares the authenticator's per-user limit.
+type recoveryCodes struct {
+	cfg     *config.Config
+	repo    repository.RecoveryCodeRepository
+	limiter *ratelimit.OTPRateLimitService
+}
+
+// generate replaces the user's codes with recovery.count new ones and returns them in plain text,
+// the only time they are available
+func (r recoveryCodes) generate(ctx context.Context, userId int) ([]string, error) {
+	codes := make([]string, r.cfg.Recovery.Count)
+	hashes := make([]string, r.cfg.Recovery.Count)
+	for i := range codes {
+		code, err := common.GenerateRecoveryCode()
+		if err != nil {
+			return nil, service_errors.Wrap(service_errors.CodeInternal, err)
+		}
+		codes[i] = code
+		hashes[i] = common.HashRecoveryCode(r.cfg.Recovery.HashKey, code)
+	}
+	err := r.repo.ReplaceRecoveryCodes(ctx, userId, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (r recoveryCodes) use(ctx context.Context, userId int, code string) error {
+	err := r.limiter.CheckOTPRateLimit(ctx, strconv.Itoa(userId))
+	if err != nil {
+		return err
+	}
+	used, err := r.repo.UseRecoveryCode(ctx, userId, common.HashRecoveryCode(r.cfg.Recovery.HashKey, code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		metrics.OtpFailed.WithLabelValues(metrics.PurposeSecondFactor).Inc()
+		return service_errors.New(service_errors.CodeRecoveryInvalid)
+	}
+	metrics.OtpVerified.WithLabelValues(metrics.PurposeSecondFactor).Inc()
+	return nil
+}
+
+// factorCheck proves the caller holds the second factor with an authenticator code or, when
+// recoveryCode is set, one of the recovery codes
+type factorCheck struct {
+	totp     totpVerifier
+	recovery recoveryCodes
+}
+
+func (c factorCheck) verify(ctx context.Context, userId int, code string, recoveryCode string) error {
+	if recoveryCode != "" {
+		return c.recovery.use(ctx, userId, recoveryCode)
+	}
+	return c.totp.verify(ctx, userId, code)
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/infra/notification"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+)
+
+const testUserId = 7
+
+// memoryRecoveryRepo keeps code hashes per user; a used code stays in the set but no longer counts
+type memoryRecoveryRepo struct {
+	codes map[int]map[string]bool
+}
+
+func (r *memoryRecoveryRepo) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	r.codes[userId] = map[string]bool{}
+	for _, hash := range codeHashes {
+		r.codes[userId][hash] = false
+	}
+	return nil
+}
+func (r *memoryRecoveryRepo) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	used, ok := r.codes[userId][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[userId][codeHash] = true
+	return true, nil
+}
+func (r *memoryRecoveryRepo) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	remaining := 0
+	for _, used := range r.codes[userId] {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining, nil
+}
+func (r *memoryRecoveryRepo) DeleteRecoveryCodes(ctx context.Context, userId int) error {
+	delete(r.codes, userId)
+	return nil
+}
+
+// confirmedTotp reports a confirmed enrollment for every user
+type confirmedTotp struct{}
+
+func (confirmedTotp) SaveTotpSecret(ctx context.Context, userId int, sealedSecret string) error {
+	return nil
+}
+func (confirmedTotp) GetTotpCredential(ctx context.Context, userId int) (model.TotpCredential, error) {
+	return model.TotpCredential{UserId: userId, Confirmed: true}, nil
+}
+func (confirmedTotp) ConfirmTotp(ctx context.Context, userId int) error          { return nil }
+func (confirmedTotp) DeleteTotpCredential(ctx context.Context, userId int) error { return nil }
+func (confirmedTotp) UseTotpStep(ctx context.Context, userId int, step int64) (bool, error) {
+	return true, nil
+}
+
+func newTestSecondFactor(t *testing.T, count int) (secondFactor, []string) {
+	t.Helper()
+	// The OTP test setup brings up Redis for the shared limiter
+	_, cfg := newTestOtpUsecase(t, &recordingSmsSender{}, notification.NewStubVoiceGateway())
+	cfg.Recovery.Count = count
+	cfg.Recovery.HashKey = "testRecoveryCodeHashKey"
+	limiter := ratelimit.NewOTPRateLimitService(ratelimit.NewRedisRateLimiter(cache.GetRedis()), ratelimit.OTPRateLimitConfig{
+		Policy: "second_factor", KeyPrefix: "mfa_verify", MaxAttempts: 100, Window: time.Minute,
+	})
+	repo := &memoryRecoveryRepo{codes: map[int]map[string]bool{}}
+	factor := secondFactor{
+		cfg:      cfg,
+		totpRepo: confirmedTotp{},
+		check:    factorCheck{recovery: recoveryCodes{cfg: cfg, repo: repo, limiter: limiter}},
+	}
+	codes, err := factor.check.recovery.generate(context.Background(), testUserId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return factor, codes
+}
+
+func TestRecoveryCodeWorksOnce(t *testing.T) {
+	ctx := context.Background()
+	factor, codes := newTestSecondFactor(t, 2)
+
+	if err := factor.check.verify(ctx, testUserId, "", codes[0]); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := factor.check.verify(ctx, testUserId, "", codes[0]); !errors.Is(err, service_errors.New(service_errors.CodeRecoveryInvalid)) {
+		t.Errorf("second use: got %v, want RECOVERY_INVALID", err)
+	}
+	if err := factor.check.verify(ctx, testUserId+1, "", codes[1]); !errors.Is(err, service_errors.New(service_errors.CodeRecoveryInvalid)) {
+		t.Errorf("other user: got %v, want RECOVERY_INVALID", err)
+	}
+	if err := factor.check.verify(ctx, testUserId, "", codes[1]); err != nil {
+		t.Errorf("remaining code: %v", err)
+	}
+}
+
+func TestRecoveryCodeNormalization(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		typed func(code string) string
+	}{
+		{"upper case", strings.ToUpper},
+		{"space instead of dash", func(code string) string { return strings.Replace(code, "-", " ", 1) }},
+		{"without dash", func(code string) string { return strings.Replace(code, "-", "", 1) }},
+		{"surrounding spaces", func(code string) string { return " " + code + " " }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factor, codes := newTestSecondFactor(t, 1)
+			if err := factor.check.verify(ctx, testUserId, "", tt.typed(codes[0])); err != nil {
+				t.Errorf("%q for %q: %v", tt.typed(codes[0]), codes[0], err)
+			}
+		})
+	}
+}
+
+func TestSecondFactorMethodsDropUsedUpRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	factor, codes := newTestSecondFactor(t, 1)
+
+	methods, err := factor.methods(ctx, testUserId)
+	if err != nil || strings.Join(methods, ",") != MfaMethodTotp+","+MfaMethodRecoveryCode {
+		t.Fatalf("got %v, %v; want totp and recovery_code", methods, err)
+	}
+	if err = factor.check.verify(ctx, testUserId, "", codes[0]); err != nil {
+		t.Fatal(err)
+	}
+	methods, err = factor.methods(ctx, testUserId)
+	if err != nil || strings.Join(methods, ",") != MfaMethodTotp {
+		t.Errorf("got %v, %v; want only totp once no codes remain", methods, err)
+	}
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
+	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
+	"github.com/skip2/go-qrcode"
+)
+
+// TotpUsecase enrolls and removes authenticator apps. An enrollment only guards logins once
+// a first code confirmed the user copied the secret; confirming also issues the recovery codes.
+type TotpUsecase struct {
+	cfg      *config.Config
+	userRepo repository.UserRepository
+	totpRepo repository.TotpRepository
+	provider auth.OtpProvider
+	check    factorCheck
+}
+
+func NewTotpUsecase(cfg *config.Config, userRepo repository.UserRepository, totpRepo repository.TotpRepository, recoveryRepo repository.RecoveryCodeRepository, provider auth.OtpProvider, limiter *ratelimit.OTPRateLimitService) *TotpUsecase {
+	return &TotpUsecase{
+		cfg:      cfg,
+		userRepo: userRepo,
+		totpRepo: totpRepo,
+		provider: provider,
+		check: factorCheck{
+			totp:     totpVerifier{provider: provider, limiter: limiter},
+			recovery: recoveryCodes{cfg: cfg, repo: recoveryRepo, limiter: limiter},
+		},
+	}
+}
+
+// Enroll creates a new secret, replacing an unconfirmed one
+func (u *TotpUsecase) Enroll(ctx context.Context, userId int) (_ dto.TotpEnrollment, err error) {
+	ctx, span := tracing.Start(ctx, "TotpUsecase.Enroll")
+	defer tracing.End(span, &err)
+
+	credential, err := u.totpRepo.GetTotpCredential(ctx, userId)
+	if err == nil && credential.Confirmed {
+		return dto.TotpEnrollment{}, service_errors.New(service_errors.CodeTotpEnrolled)
+	} else if err != nil && !errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return dto.TotpEnrollment{}, err
+	}
+	user, err := u.userRepo.FetchUserInfoById(ctx, userId)
+	if err != nil {
+		return dto.TotpEnrollment{}, err
+	}
+
+	secret, err := common.GenerateTotpSecret()
+	if err != nil {
+		return dto.TotpEnrollment{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	err = u.provider.SetOtp(ctx, strconv.Itoa(userId), secret)
+	if err != nil {
+		return dto.TotpEnrollment{}, err
+	}
+
+	account := user.MobileNumber
+	if account == "" {
+		account = user.Email
+	}
+	uri := common.TotpUri(u.cfg.Totp.Issuer, account, secret, u.cfg.Totp.Digits, u.cfg.Totp.Period)
+	png, err := qrcode.Encode(uri, qrcode.Medium, u.cfg.Totp.QrSize)
+	if err != nil {
+		return dto.TotpEnrollment{}, service_errors.Wrap(service_errors.CodeInternal, err)
+	}
+	return dto.TotpEnrollment{
+		Secret:     secret,
+		OtpauthUri: uri,
+		QrPng:      base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Confirm activates the enrollment with the first code from the app and returns a new set of
+// recovery codes
+func (u *TotpUsecase) Confirm(ctx context.Context, userId int, code string) (_ dto.RecoveryCodes, err error) {
+	ctx, span := tracing.Start(ctx, "TotpUsecase.Confirm")
+	defer tracing.End(span, &err)
+
+	credential, err := u.credential(ctx, userId)
+	if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	if credential.Confirmed {
+		return dto.RecoveryCodes{}, service_errors.New(service_errors.CodeTotpEnrolled)
+	}
+	err = u.check.totp.verify(ctx, userId, code)
+	if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	codes, err := u.check.recovery.generate(ctx, userId)
+	if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	err = u.totpRepo.ConfirmTotp(ctx, userId)
+	if err != nil {
+		return dto.RecoveryCodes{}, err
+	}
+	return dto.RecoveryCodes{Codes: codes, Remaining: len(codes)}, nil
+}
+
+// Disable removes the authenticator app and the recovery codes; a current code, or a recovery
+// code when the app is lost, proves the caller still holds the second factor
+func (u *TotpUsecase) Disable(ctx context.Context, userId int, code string, recoveryCode string) (err error) {
+	ctx, span := tracing.Start(ctx, "TotpUsecase.Disable")
+	defer tracing.End(span, &err)
+
+	credential, err := u.credential(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if credential.Confirmed {
+		err = u.check.verify(ctx, userId, code, recoveryCode)
+		if err != nil {
+			return err
+		}
+	}
+	err = u.totpRepo.DeleteTotpCredential(ctx, userId)
+	if err != nil {
+		return err
+	}
+	return u.check.recovery.repo.DeleteRecoveryCodes(ctx, userId)
+}
+
+func (u *TotpUsecase) credential(ctx context.Context, userId int) (model.TotpCredential, error) {
+	credential, err := u.totpRepo.GetTotpCredential(ctx, userId)
+	if errors.Is(err, service_errors.New(service_errors.CodeRecordNotFound)) {
+		return credential, service_errors.Wrap(service_errors.CodeTotpNotEnrolled, err)
+	}
+	return credential, err
+}
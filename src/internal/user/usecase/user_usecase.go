@@ -2,110 +2,223 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/alielmi98/golang-otp-auth/internal/user/api/dto"
 	"github.com/alielmi98/golang-otp-auth/internal/user/domain/auth"
 	model "github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/policy"
 	"github.com/alielmi98/golang-otp-auth/internal/user/domain/repository"
 	"github.com/alielmi98/golang-otp-auth/internal/user/entity"
+	"github.com/alielmi98/golang-otp-auth/pkg/cache"
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
+	"github.com/alielmi98/golang-otp-auth/pkg/ratelimit"
+	"github.com/alielmi98/golang-otp-auth/pkg/tracing"
 )
 
 type UserUsecase struct {
-	cfg         *config.Config
-	repo        repository.UserRepository
-	token       auth.TokenProvider
-	otpProvider auth.OtpProvider
+	cfg           *config.Config
+	repo          repository.UserRepository
+	token         auth.TokenProvider
+	otpProvider   auth.OtpProvider
+	abuseDetector policy.AbuseDetector
+	auditor       otpAuditor
+	secondFactor  secondFactor
+	lockout       passwordLockout
+	hasher        passwordHasher
+	loginLimiter  passwordLoginLimiter
+	magicLinks    magicLinks
 }
 
-func NewUserUsecase(cfg *config.Config, repository repository.UserRepository, token auth.TokenProvider, otpProvider auth.OtpProvider) *UserUsecase {
+// UserDependencies are the collaborators of a UserUsecase; naming them keeps the providers and
+// the rate limit services from being swapped
+type UserDependencies struct {
+	Repo              repository.UserRepository
+	Token             auth.TokenProvider
+	OtpProvider       auth.OtpProvider
+	MagicLinkProvider auth.RevocableOtpProvider
+	AbuseDetector     policy.AbuseDetector
+	AuditRepo         repository.OtpAuditRepository
+	TotpRepo          repository.TotpRepository
+	RecoveryRepo      repository.RecoveryCodeRepository
+	TotpProvider      auth.OtpProvider
+	// TotpLimiter counts authenticator and recovery code guesses per user
+	TotpLimiter *ratelimit.OTPRateLimitService
+	// PasswordLimiter and PasswordIpLimiter count password logins per identifier and per IP address
+	PasswordLimiter   *ratelimit.OTPRateLimitService
+	PasswordIpLimiter *ratelimit.OTPRateLimitService
+}
+
+func NewUserUsecase(cfg *config.Config, deps UserDependencies) *UserUsecase {
 	return &UserUsecase{
-		cfg:         cfg,
-		repo:        repository,
-		token:       token,
-		otpProvider: otpProvider,
+		cfg:           cfg,
+		repo:          deps.Repo,
+		token:         deps.Token,
+		otpProvider:   deps.OtpProvider,
+		abuseDetector: deps.AbuseDetector,
+		auditor:       otpAuditor{repo: deps.AuditRepo},
+		lockout:       passwordLockout{cfg: cfg, redisClient: cache.GetRedis()},
+		hasher:        newPasswordHasher(cfg),
+		loginLimiter:  passwordLoginLimiter{identifier: deps.PasswordLimiter, ip: deps.PasswordIpLimiter},
+		magicLinks:    magicLinks{cfg: cfg, provider: deps.MagicLinkProvider},
+		secondFactor: secondFactor{
+			cfg:         cfg,
+			redisClient: cache.GetRedis(),
+			totpRepo:    deps.TotpRepo,
+			check: factorCheck{
+				totp:     totpVerifier{provider: deps.TotpProvider, limiter: deps.TotpLimiter},
+				recovery: recoveryCodes{cfg: cfg, repo: deps.RecoveryRepo, limiter: deps.TotpLimiter},
+			},
+		},
 	}
 }
 
 // Register/login by mobile number
 func (u *UserUsecase) RegisterAndLoginByMobileNumber(ctx context.Context, mobileNumber string, otp string) (*dto.TokenDetail, error) {
-	err := u.otpProvider.ValidateOtp(mobileNumber, otp)
+	return u.RegisterAndLogin(ctx, mobileNumber, "", otp)
+}
+
+// RegisterAndLogin checks the code sent to the mobile number or the email and logs the user in,
+// registering them first when the identifier is new. Users with a second factor get an mfa
+// token for LoginSecondFactor instead of tokens.
+func (u *UserUsecase) RegisterAndLogin(ctx context.Context, mobileNumber string, email string, otp string) (_ *dto.TokenDetail, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.RegisterAndLogin")
+	defer tracing.End(span, &err)
+
+	recipient, err := resolveRecipient(u.cfg, mobileNumber, email, "")
 	if err != nil {
 		return nil, err
 	}
-	exists, err := u.repo.ExistsMobileNumber(ctx, mobileNumber)
+	err = u.otpProvider.ValidateOtp(ctx, recipient.Address, otp)
+	u.auditor.recordValidation(ctx, recipient, metrics.PurposeLogin, err)
 	if err != nil {
 		return nil, err
 	}
+	u.abuseDetector.RecordVerified(ctx, recipient.Address, recipient.Channel)
+	return u.login(ctx, recipient)
+}
 
-	user := model.User{MobileNumber: mobileNumber}
-
-	if exists {
-		user, err = u.repo.FetchUserInfo(ctx, user.MobileNumber)
-		if err != nil {
-			return nil, err
+// login registers a verified recipient that is new and returns their tokens, or an mfa token when
+// they have a second factor
+func (u *UserUsecase) login(ctx context.Context, recipient otpRecipient) (*dto.TokenDetail, error) {
+	exists, err := u.existsRecipient(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		// Register and login
+		user := model.User{}
+		if recipient.isPhone() {
+			user.MobileNumber = recipient.Address
+		} else {
+			user.Email = recipient.Address
 		}
-
-		token, err := u.generateToken(&user)
+		user.RegisteredAt = time.Now()
+		_, err = u.repo.CreateUser(ctx, user)
 		if err != nil {
 			return nil, err
 		}
-		return token, nil
 	}
 
-	// Register and login
-	user.RegisteredAt = user.CreatedAt
-	user, err = u.repo.CreateUser(ctx, user)
+	user, err := u.fetchUserInfo(ctx, recipient)
 	if err != nil {
 		return nil, err
 	}
+	methods, err := u.secondFactor.methods(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		return u.secondFactor.begin(ctx, user.Id, methods)
+	}
+	return generateToken(ctx, u.token, &user)
+}
+
+// LoginSecondFactor finishes a login that answered mfaRequired with an authenticator code or,
+// when recoveryCode is set, a recovery code
+func (u *UserUsecase) LoginSecondFactor(ctx context.Context, mfaToken string, code string, recoveryCode string) (_ *dto.TokenDetail, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.LoginSecondFactor")
+	defer tracing.End(span, &err)
 
-	user, err = u.repo.FetchUserInfo(ctx, user.MobileNumber)
-	token, err := u.generateToken(&user)
+	userId, err := u.secondFactor.complete(ctx, mfaToken, code, recoveryCode)
 	if err != nil {
 		return nil, err
 	}
-	return token, nil
+	user, err := u.repo.FetchUserInfoById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return generateToken(ctx, u.token, &user)
+}
 
+func (u *UserUsecase) existsRecipient(ctx context.Context, recipient otpRecipient) (bool, error) {
+	if recipient.isPhone() {
+		return u.repo.ExistsMobileNumber(ctx, recipient.Address)
+	}
+	return u.repo.ExistsEmail(ctx, recipient.Address)
 }
 
-func (s *UserUsecase) GetUserByMobileNumber(ctx context.Context, mobileNumber string) (dto.UserInfo, error) {
+func (u *UserUsecase) fetchUserInfo(ctx context.Context, recipient otpRecipient) (model.User, error) {
+	if recipient.isPhone() {
+		return u.repo.FetchUserInfo(ctx, recipient.Address)
+	}
+	return u.repo.FetchUserInfoByEmail(ctx, recipient.Address)
+}
+
+func (s *UserUsecase) GetUserByMobileNumber(ctx context.Context, mobileNumber string) (_ dto.UserInfo, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.GetUserByMobileNumber")
+	defer tracing.End(span, &err)
+
+	mobileNumber, err = normalizeMobileNumber(s.cfg, mobileNumber)
+	if err != nil {
+		return dto.UserInfo{}, err
+	}
 	user, err := s.repo.GetUserByMobileNumber(ctx, mobileNumber)
 	if err != nil {
 		return dto.UserInfo{}, err
 	}
 
 	// Map domain model to response DTO
-	return dto.UserInfo{ID: user.Id, MobileNumber: user.MobileNumber, RegisteredAt: user.RegisteredAt}, nil
+	return dto.UserInfo{ID: user.Id, MobileNumber: user.MobileNumber, Email: user.Email, RegisteredAt: user.RegisteredAt}, nil
 }
 
-func (s *UserUsecase) RefreshToken(refreshToken string) (*dto.TokenDetail, error) {
-	tokenDetail, err := s.token.RefreshToken(refreshToken)
+func (s *UserUsecase) RefreshToken(ctx context.Context, refreshToken string) (_ *dto.TokenDetail, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.RefreshToken")
+	defer tracing.End(span, &err)
+
+	tokenDetail, err := s.token.RefreshToken(ctx, refreshToken, "")
 	if err != nil {
 		return nil, err
 	}
+	metrics.TokenRefreshes.Inc()
 
 	return tokenDetail, nil
 }
 
-func (s *UserUsecase) generateToken(user *model.User) (*dto.TokenDetail, error) {
-	tokenDto := entity.TokenPayload{UserId: user.Id, MobileNumber: user.MobileNumber}
+// generateToken issues the access and refresh tokens of a logged-in user
+func generateToken(ctx context.Context, tokenProvider auth.TokenProvider, user *model.User) (*dto.TokenDetail, error) {
+	tokenDto := entity.TokenPayload{UserId: user.Id, MobileNumber: user.MobileNumber, Email: user.Email}
 
-	if len(*user.UserRoles) > 0 {
+	if user.UserRoles != nil {
 		for _, ur := range *user.UserRoles {
 			tokenDto.Roles = append(tokenDto.Roles, ur.Role.Name)
 		}
 	}
 
-	token, err := s.token.GenerateToken(&tokenDto)
+	token, err := tokenProvider.GenerateToken(ctx, &tokenDto)
 	if err != nil {
 		return nil, err
 	}
+	metrics.TokensIssued.Inc()
 	return token, nil
 }
 
-func (s *UserUsecase) GetAllUsers(ctx context.Context, page, pageSize int, mobileNumber string) (dto.UserList, error) {
-	users, total, err := s.repo.GetAllUsers(ctx, page, pageSize, mobileNumber)
+func (s *UserUsecase) GetAllUsers(ctx context.Context, page, pageSize int, mobileNumber string) (_ dto.UserList, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.GetAllUsers")
+	defer tracing.End(span, &err)
+
+	users, total, err := s.repo.GetAllUsers(ctx, page, pageSize, mobileNumberFilter(s.cfg, mobileNumber))
 	if err != nil {
 		return dto.UserList{}, err
 	}
@@ -114,6 +227,7 @@ func (s *UserUsecase) GetAllUsers(ctx context.Context, page, pageSize int, mobil
 		userInfos[i] = dto.UserInfo{
 			ID:           user.Id,
 			MobileNumber: user.MobileNumber,
+			Email:        user.Email,
 			RegisteredAt: user.RegisteredAt,
 		}
 	}
@@ -1,20 +1,25 @@
 package migrations
 
 import (
-	"log"
 	"time"
 
 	"github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/common"
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/alielmi98/golang-otp-auth/pkg/constants"
 	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
 	"gorm.io/gorm"
 )
 
-func Up1() {
+// defaultAdminMobileNumber is the seeded admin, in national format for phone.defaultRegion
+const defaultAdminMobileNumber = "09111112222"
+
+func Up1(cfg *config.Config) {
 	database := db.GetDb()
 
 	createTables(database)
-	createDefaultUserInformation(database)
+	createDefaultUserInformation(database, cfg)
 
 }
 
@@ -26,11 +31,12 @@ func createTables(database *gorm.DB) {
 	tables = addNewTable(database, models.Role{}, tables)
 	tables = addNewTable(database, models.UserRole{}, tables)
 
+	logger := logging.GetLogger()
 	err := database.Migrator().CreateTable(tables...)
 	if err != nil {
-		log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.Migration, err.Error())
+		logger.Fatal(constants.Postgres, constants.Migration, "create tables failed", map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
 	}
-	log.Printf("Caller:%s Level:%s Msg:%s", constants.Postgres, constants.Migration, "tables created")
+	logger.Info(constants.Postgres, constants.Migration, "tables created", nil)
 }
 
 func addNewTable(database *gorm.DB, model interface{}, tables []interface{}) []interface{} {
@@ -40,7 +46,7 @@ func addNewTable(database *gorm.DB, model interface{}, tables []interface{}) []i
 	return tables
 }
 
-func createDefaultUserInformation(database *gorm.DB) {
+func createDefaultUserInformation(database *gorm.DB, cfg *config.Config) {
 
 	adminRole := models.Role{Name: constants.AdminRoleName}
 	createRoleIfNotExists(database, &adminRole)
@@ -48,7 +54,12 @@ func createDefaultUserInformation(database *gorm.DB) {
 	defaultRole := models.Role{Name: constants.DefaultRoleName}
 	createRoleIfNotExists(database, &defaultRole)
 
-	u := models.User{MobileNumber: "09111112222", RegisteredAt: time.Now()}
+	mobileNumber, err := common.NormalizeMobileNumber(defaultAdminMobileNumber, &cfg.Phone)
+	if err != nil {
+		logging.GetLogger().Fatal(constants.Postgres, constants.Migration, "normalize default admin mobile number failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+	u := models.User{MobileNumber: mobileNumber, RegisteredAt: time.Now()}
 
 	createAdminUserIfNotExists(database, &u, adminRole.Id)
 
@@ -71,7 +82,8 @@ func createAdminUserIfNotExists(database *gorm.DB, u *models.User, roleId int) {
 	database.
 		Model(&models.User{}).
 		Select("1").
-		Where("mobile_number = ?", u.MobileNumber).
+		// Databases created before Up3 hold the seed as entered until Up3 converts it
+		Where("mobile_number IN ?", []string{u.MobileNumber, defaultAdminMobileNumber}).
 		First(&exists)
 	if exists == 0 {
 		database.Create(u)
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"gorm.io/gorm"
+)
+
+// legacyIranianMobilePattern matches the 09xxxxxxxxx numbers stored before E.164 normalization
+const legacyIranianMobilePattern = `^09[0-9]{9}$`
+
+// Up3 widens users.mobile_number for E.164 and rewrites legacy 09... rows as +989...
+func Up3() {
+	database := db.GetDb()
+	logger := logging.GetLogger()
+
+	if !database.Migrator().HasTable(&models.User{}) {
+		return
+	}
+	err := database.Migrator().AlterColumn(&models.User{}, "MobileNumber")
+	if err != nil {
+		logger.Fatal(constants.Postgres, constants.Migration, "widen mobile number column failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+
+	result := database.Model(&models.User{}).
+		Where("mobile_number ~ ?", legacyIranianMobilePattern).
+		Update("mobile_number", gorm.Expr("'+98' || substr(mobile_number, 2)"))
+	if result.Error != nil {
+		logger.Fatal(constants.Postgres, constants.Migration, "convert mobile numbers to E.164 failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: result.Error.Error()})
+	}
+	if result.RowsAffected > 0 {
+		logger.Infof("%d mobile numbers converted to E.164", result.RowsAffected)
+	}
+}
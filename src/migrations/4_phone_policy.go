@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"github.com/alielmi98/golang-otp-auth/internal/phonepolicy/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+)
+
+func Up4() {
+	database := db.GetDb()
+	logger := logging.GetLogger()
+
+	tables := addNewTable(database, models.PhonePolicy{}, []interface{}{})
+	if len(tables) == 0 {
+		return
+	}
+	err := database.Migrator().CreateTable(tables...)
+	if err != nil {
+		logger.Fatal(constants.Postgres, constants.Migration, "create phone policy table failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+	logger.Info(constants.Postgres, constants.Migration, "phone policy table created", nil)
+}
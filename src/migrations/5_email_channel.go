@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"github.com/alielmi98/golang-otp-auth/internal/user/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+)
+
+// Up5 adds the email identifier to users and the delivery channel to otp_audits, whose
+// mobile_number becomes optional
+func Up5() {
+	database := db.GetDb()
+	logger := logging.GetLogger()
+	migrator := database.Migrator()
+
+	columns := []struct {
+		model interface{}
+		field string
+	}{
+		{&models.User{}, "Email"},
+		{&models.OtpAudit{}, "Email"},
+		{&models.OtpAudit{}, "Channel"},
+	}
+	for _, column := range columns {
+		if !migrator.HasTable(column.model) || migrator.HasColumn(column.model, column.field) {
+			continue
+		}
+		if err := migrator.AddColumn(column.model, column.field); err != nil {
+			logger.Fatal(constants.Postgres, constants.Migration, "add "+column.field+" column failed",
+				map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		}
+	}
+
+	// Email sends have no mobile number
+	if err := database.Exec("ALTER TABLE otp_audits ALTER COLUMN mobile_number DROP NOT NULL").Error; err != nil {
+		logger.Fatal(constants.Postgres, constants.Migration, "drop otp audit mobile number not null failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+
+	if migrator.HasTable(&models.User{}) && !migrator.HasConstraint(&models.User{}, "Email") {
+		if err := migrator.CreateConstraint(&models.User{}, "Email"); err != nil {
+			logger.Fatal(constants.Postgres, constants.Migration, "create unique email constraint failed",
+				map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		}
+	}
+	if !migrator.HasIndex(&models.OtpAudit{}, "Email") {
+		if err := migrator.CreateIndex(&models.OtpAudit{}, "Email"); err != nil {
+			logger.Fatal(constants.Postgres, constants.Migration, "create otp audit email index failed",
+				map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+		}
+	}
+}
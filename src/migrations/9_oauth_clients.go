@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/alielmi98/golang-otp-auth/internal/oauth/domain/models"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/db"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
+	"gorm.io/gorm"
+)
+
+// Up9 creates the OAuth client table and registers the CLI and TV clients the device grant
+// previously read from configuration
+func Up9() {
+	database := db.GetDb()
+	logger := logging.GetLogger()
+
+	tables := addNewTable(database, models.Client{}, []interface{}{})
+	if len(tables) == 0 {
+		return
+	}
+	err := database.Migrator().CreateTable(tables...)
+	if err != nil {
+		logger.Fatal(constants.Postgres, constants.Migration, "create oauth client table failed",
+			map[constants.ExtraKey]interface{}{constants.ErrorMessage: err.Error()})
+	}
+	createClientIfNotExists(database, &models.Client{ClientId: "otpauth-cli", Name: "OTPAuth CLI", CreatedAt: time.Now()})
+	createClientIfNotExists(database, &models.Client{ClientId: "otpauth-tv", Name: "OTPAuth TV", CreatedAt: time.Now()})
+	logger.Info(constants.Postgres, constants.Migration, "oauth client table created", nil)
+}
+
+func createClientIfNotExists(database *gorm.DB, c *models.Client) {
+	exists := 0
+	database.
+		Model(&models.Client{}).
+		Select("1").
+		Where("client_id = ?", c.ClientId).
+		First(&exists)
+	if exists == 0 {
+		database.Create(c)
+	}
+}
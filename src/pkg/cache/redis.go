@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -24,6 +25,8 @@ func InitRedis(cfg *config.Config) error {
 		IdleTimeout:        500 * time.Millisecond,
 		IdleCheckFrequency: cfg.Redis.IdleCheckFrequency * time.Millisecond,
 	})
+	redisClient.AddHook(metricsHook{})
+	redisClient.AddHook(tracingHook{})
 
 	_, err := redisClient.Ping().Result()
 	if err != nil {
@@ -40,17 +43,17 @@ func CloseRedis() {
 	redisClient.Close()
 }
 
-func Set[T any](c *redis.Client, key string, value T, duration time.Duration) error {
+func Set[T any](ctx context.Context, c *redis.Client, key string, value T, duration time.Duration) error {
 	v, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	return c.Set(key, v, duration).Err()
+	return c.WithContext(ctx).Set(key, v, duration).Err()
 }
 
-func Get[T any](c *redis.Client, key string) (T, error) {
+func Get[T any](ctx context.Context, c *redis.Client, key string) (T, error) {
 	var dest T = *new(T)
-	v, err := c.Get(key).Result()
+	v, err := c.WithContext(ctx).Get(key).Result()
 	if err != nil {
 		return dest, err
 	}
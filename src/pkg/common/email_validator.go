@@ -0,0 +1,30 @@
+package common
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+const maxEmailLength = 254
+
+var ErrInvalidEmail = errors.New("invalid email address")
+
+// NormalizeEmail returns the address trimmed and lower-cased, the form stored and used as a key
+// everywhere. Display names ("Ali <ali@example.com>") and addresses without a dotted domain are rejected.
+func NormalizeEmail(raw string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+	if email == "" || len(email) > maxEmailLength {
+		return "", ErrInvalidEmail
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
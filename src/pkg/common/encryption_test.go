@@ -0,0 +1,70 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	sealed, err := Seal("passphrase", rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, rfc6238Secret) {
+		t.Fatalf("sealed value %q is not an opaque v1 value", sealed)
+	}
+	opened, err := Open("passphrase", sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened != rfc6238Secret {
+		t.Errorf("got %q, want %q", opened, rfc6238Secret)
+	}
+
+	again, err := Seal("passphrase", rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == sealed {
+		t.Error("sealing twice gave the same value; the nonce is not random")
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	sealed, err := Seal("passphrase", rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 1
+	tampered := sealedPrefix + base64.StdEncoding.EncodeToString(data)
+
+	tests := []struct {
+		name       string
+		passphrase string
+		sealed     string
+		malformed  bool
+	}{
+		{"wrong key", "other passphrase", sealed, false},
+		{"tampered", "passphrase", tampered, false},
+		{"no prefix", "passphrase", strings.TrimPrefix(sealed, sealedPrefix), true},
+		{"not base64", "passphrase", sealedPrefix + "***", true},
+		{"too short", "passphrase", sealedPrefix + "AAAA", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Open(tt.passphrase, tt.sealed)
+			if err == nil {
+				t.Fatal("got nil, want an error")
+			}
+			if tt.malformed != errors.Is(err, ErrSealedValue) {
+				t.Errorf("got %v, malformed %v", err, tt.malformed)
+			}
+		})
+	}
+}
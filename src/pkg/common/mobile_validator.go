@@ -1,16 +1,95 @@
 package common
 
 import (
-	"log"
-	"regexp"
+	"errors"
+	"strings"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/nyaruka/phonenumbers"
+)
+
+type PhoneNumberType string
+
+const (
+	PhoneTypeMobile            PhoneNumberType = "mobile"
+	PhoneTypeFixedLine         PhoneNumberType = "fixed_line"
+	PhoneTypeFixedLineOrMobile PhoneNumberType = "fixed_line_or_mobile"
+	PhoneTypeOther             PhoneNumberType = "other"
 )
 
-const iranianMobileNumberPattern string = `^09(1[0-9]|2[0-2]|3[0-9]|9[0-9])[0-9]{7}$`
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// PhoneNumber is a parsed number; E164 is the canonical form stored and used as a key everywhere
+type PhoneNumber struct {
+	E164   string
+	Region string
+	Type   PhoneNumberType
+}
+
+// ParsePhoneNumber accepts international input (+44 7911 123456, 0044...) as well as national
+// input such as the legacy 09121234567, which is read in defaultRegion
+func ParsePhoneNumber(raw, defaultRegion string) (PhoneNumber, error) {
+	number, err := phonenumbers.Parse(strings.TrimSpace(raw), strings.ToUpper(defaultRegion))
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return PhoneNumber{}, ErrInvalidPhoneNumber
+	}
+	return PhoneNumber{
+		E164:   phonenumbers.Format(number, phonenumbers.E164),
+		Region: phonenumbers.GetRegionCodeForNumber(number),
+		Type:   phoneNumberType(phonenumbers.GetNumberType(number)),
+	}, nil
+}
+
+// CanReceiveSms reports whether the number may be a mobile line. Regions such as the US do not
+// distinguish mobile from landline numbers, so fixed_line_or_mobile is accepted too.
+func (p PhoneNumber) CanReceiveSms() bool {
+	return p.Type == PhoneTypeMobile || p.Type == PhoneTypeFixedLineOrMobile
+}
 
-func IranianMobileNumberValidate(mobileNumber string) bool {
-	res, err := regexp.MatchString(iranianMobileNumberPattern, mobileNumber)
+// IsPhoneRegion reports whether region is an ISO 3166 code with a calling code, e.g. IR
+func IsPhoneRegion(region string) bool {
+	return phonenumbers.GetCountryCodeForRegion(region) != 0
+}
+
+func phoneNumberType(t phonenumbers.PhoneNumberType) PhoneNumberType {
+	switch t {
+	case phonenumbers.MOBILE:
+		return PhoneTypeMobile
+	case phonenumbers.FIXED_LINE:
+		return PhoneTypeFixedLine
+	case phonenumbers.FIXED_LINE_OR_MOBILE:
+		return PhoneTypeFixedLineOrMobile
+	default:
+		return PhoneTypeOther
+	}
+}
+
+var (
+	ErrNotMobileNumber  = errors.New("phone number is not a mobile number")
+	ErrRegionNotAllowed = errors.New("phone number region is not allowed")
+)
+
+// NormalizeMobileNumber returns the E.164 form of raw when it is a mobile number from a region
+// cfg allows
+func NormalizeMobileNumber(raw string, cfg *config.PhoneConfig) (string, error) {
+	phone, err := ParsePhoneNumber(raw, cfg.DefaultRegion)
 	if err != nil {
-		log.Print(err.Error())
+		return "", err
+	}
+	if !phone.CanReceiveSms() {
+		return "", ErrNotMobileNumber
+	}
+	if len(cfg.AllowedRegions) > 0 && !containsFold(cfg.AllowedRegions, phone.Region) {
+		return "", ErrRegionNotAllowed
+	}
+	return phone.E164, nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
 	}
-	return res
+	return false
 }
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPasswordRoundTrip(t *testing.T) {
+	encoded, err := HashPassword("correct horse 9!", 1024, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("got %q, want a PHC argon2id hash with the given cost", encoded)
+	}
+	if ok, err := CheckPassword(encoded, "correct horse 9!"); err != nil || !ok {
+		t.Errorf("right password: got %v, %v, want true", ok, err)
+	}
+	if ok, err := CheckPassword(encoded, "correct horse 9?"); err != nil || ok {
+		t.Errorf("wrong password: got %v, %v, want false", ok, err)
+	}
+}
+
+func TestCheckPasswordMalformed(t *testing.T) {
+	valid, err := HashPassword("secret", 1024, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, "$")
+	tests := map[string]string{
+		"empty":         "",
+		"bcrypt":        "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+		"argon2i":       strings.Replace(valid, "argon2id", "argon2i", 1),
+		"other version": strings.Replace(valid, "v=19", "v=16", 1),
+		"bad params":    strings.Join([]string{"", parts[1], parts[2], "m=x,t=1,p=1", parts[4], parts[5]}, "$"),
+		"bad salt":      strings.Join([]string{"", parts[1], parts[2], parts[3], "!!", parts[5]}, "$"),
+		"bad key":       strings.Join([]string{"", parts[1], parts[2], parts[3], parts[4], "!!"}, "$"),
+		"missing key":   strings.Join(parts[:5], "$"),
+	}
+	for name, encoded := range tests {
+		t.Run(name, func(t *testing.T) {
+			ok, err := CheckPassword(encoded, "secret")
+			if ok || !errors.Is(err, ErrPasswordHash) {
+				t.Errorf("got %v, %v, want ErrPasswordHash", ok, err)
+			}
+		})
+	}
+}
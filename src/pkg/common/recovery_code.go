@@ -0,0 +1,47 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+// recoveryCodeAlphabet leaves out 0, 1, i, l and o, which are easily misread on paper
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// recoveryCodeLength characters give about 50 bits, enough to store them with a fast keyed hash
+const recoveryCodeLength = 10
+
+// GenerateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func GenerateRecoveryCode() (string, error) {
+	var code strings.Builder
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeLength; i++ {
+		if i == recoveryCodeLength/2 {
+			code.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return code.String(), nil
+}
+
+// NormalizeRecoveryCode accepts codes typed in upper case or with spaces instead of the dash
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// HashRecoveryCode returns the hex HMAC-SHA256 of the normalized code under key, which is what
+// gets stored
+func HashRecoveryCode(key string, code string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(NormalizeRecoveryCode(code)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -7,8 +7,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/alielmi98/golang-otp-auth/pkg/config"
 )
 
 var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
@@ -20,12 +18,13 @@ func ToSnakeCase(str string) string {
 	snake = matchAllCap.ReplaceAllString(snake, "${1}_${2}")
 	return strings.ToLower(snake)
 }
-func GenerateOtp() string {
-	cfg := config.GetConfig()
+
+// GenerateOtp returns a random code of otp.digits digits
+func GenerateOtp(digits int) string {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	min := int(math.Pow(10, float64(cfg.Otp.Digits-1)))   // 10^d-1 100000
-	max := int(math.Pow(10, float64(cfg.Otp.Digits)) - 1) // 999999 = 1000000 - 1 (10^d) -1
+	min := int(math.Pow(10, float64(digits-1)))   // 10^d-1 100000
+	max := int(math.Pow(10, float64(digits)) - 1) // 999999 = 1000000 - 1 (10^d) -1
 
 	var num = r.Intn(max-min) + min
 	return strconv.Itoa(num)
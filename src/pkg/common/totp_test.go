@@ -0,0 +1,46 @@
+package common
+
+import "testing"
+
+// rfc6238Secret is the SHA1 seed "12345678901234567890" of the RFC 6238 test vectors, in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCodeRfc6238(t *testing.T) {
+	tests := []struct {
+		unixTime int64
+		want     string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		got, err := TotpCode(rfc6238Secret, tt.unixTime/30, 8)
+		if err != nil {
+			t.Fatalf("T=%d: %v", tt.unixTime, err)
+		}
+		if got != tt.want {
+			t.Errorf("T=%d: got %s, want %s", tt.unixTime, got, tt.want)
+		}
+	}
+}
+
+func TestTotpCodeSixDigits(t *testing.T) {
+	// The last six digits of the 8-digit vector, as authenticator apps show them
+	got, err := TotpCode(rfc6238Secret, 59/30, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "287082" {
+		t.Errorf("got %s, want 287082", got)
+	}
+}
+
+func TestTotpCodeMalformedSecret(t *testing.T) {
+	if _, err := TotpCode("not base32!", 1, 6); err == nil {
+		t.Error("got nil, want an error")
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -10,18 +11,49 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Redis    RedisConfig
-	Cors     CorsConfig
-	Otp      OtpConfig
-	JWT      JWTConfig
+	Server      ServerConfig
+	Postgres    PostgresConfig
+	Redis       RedisConfig
+	Cors        CorsConfig
+	Otp         OtpConfig
+	Totp        TotpConfig
+	Recovery    RecoveryConfig
+	WebAuthn    WebAuthnConfig
+	Password    PasswordConfig
+	MagicLink   MagicLinkConfig
+	QrLogin     QrLoginConfig
+	OAuth       OAuthConfig
+	JWT         JWTConfig
+	Health      HealthConfig
+	Tracing     TracingConfig
+	Logger      LoggerConfig
+	AccessLog   AccessLogConfig
+	Sms         SmsConfig
+	Email       EmailConfig
+	Voice       VoiceConfig
+	I18n        I18nConfig
+	Phone       PhoneConfig
+	PhonePolicy PhonePolicyConfig
+	Abuse       AbuseConfig
+	Challenge   ChallengeConfig
 }
 
 type ServerConfig struct {
 	InternalPort string
 	ExternalPort string
-	RunMode      string
+	// MetricsPort serves /metrics on a listener of its own so the API port never exposes it
+	MetricsPort string
+	RunMode     string
+	// ShutdownTimeout is the number of seconds in-flight requests get to finish
+	ShutdownTimeout time.Duration
+	// DrainDelay is the number of seconds readiness reports failure before the listener closes
+	DrainDelay time.Duration
+	// ErrorFormat is "envelope" (default) or "problem" to always answer errors with RFC 7807
+	ErrorFormat string
+	// ProblemTypeBaseUrl prefixes the problem "type" URI, e.g. https://example.com/problems
+	ProblemTypeBaseUrl string
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For is believed; empty trusts none
+	TrustedProxies []string
 }
 
 type PostgresConfig struct {
@@ -58,6 +90,184 @@ type OtpConfig struct {
 	ExpireTime time.Duration
 	Digits     int
 	Limiter    time.Duration
+	// MaxVerifyAttempts is the number of wrong codes after which the OTP is locked
+	MaxVerifyAttempts int
+}
+
+// TotpConfig configures authenticator-app second factors (RFC 6238)
+type TotpConfig struct {
+	// Issuer is the account label shown in authenticator apps
+	Issuer string
+	// Period is the code lifetime in seconds and Digits the code length
+	Period int
+	Digits int
+	// Skew is how many periods before and after now a code is still accepted, for clock drift
+	Skew int
+	// EncryptionKey encrypts secrets at rest; changing it invalidates every enrollment
+	EncryptionKey string
+	// MfaTokenTtl is how many seconds a user has to enter the code after the first login step
+	MfaTokenTtl time.Duration
+	// MaxVerifyAttempts per MaxVerifyWindow seconds limits code guesses for one user
+	MaxVerifyAttempts int
+	MaxVerifyWindow   time.Duration
+	// QrSize is the QR PNG width and height in pixels
+	QrSize int
+}
+
+// RecoveryConfig configures the one-time codes that replace a lost second factor
+type RecoveryConfig struct {
+	// Count is how many codes a set holds
+	Count int
+	// HashKey keys the stored code hashes; changing it invalidates every issued code
+	HashKey string
+}
+
+// WebAuthnConfig configures passkey login
+type WebAuthnConfig struct {
+	// RpId is the relying party id, the domain passkeys are bound to, e.g. "example.com"
+	RpId          string
+	RpDisplayName string
+	// RpOrigins are the exact origins, scheme and port included, browsers may call from
+	RpOrigins []string
+	// UserVerification is required, preferred or discouraged
+	UserVerification string
+	// Timeout is how many seconds the browser has to complete a ceremony
+	Timeout time.Duration
+}
+
+// PasswordConfig configures the optional password login
+type PasswordConfig struct {
+	MinLength int
+	MaxLength int
+	// MinClasses is how many of lower case, upper case, digits and symbols a password must mix
+	MinClasses int
+	// Memory in KiB, Iterations and Parallelism are the argon2id cost of new hashes
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	// MaxAttempts wrong passwords, each within LockoutWindow seconds of the previous one, lock
+	// password login until LockoutWindow seconds after the last
+	MaxAttempts   int
+	LockoutWindow time.Duration
+	// MaxLogins attempts per identifier and IpMaxLogins per IP address are allowed every
+	// LoginWindow seconds, checked before any hashing
+	MaxLogins   int
+	IpMaxLogins int
+	LoginWindow time.Duration
+	// MaxConcurrentHashes bounds how many argon2id hashes run at once, each holding Memory KiB
+	MaxConcurrentHashes int
+}
+
+// MagicLinkConfig configures one-time login links sent by SMS or email
+type MagicLinkConfig struct {
+	// Url is the dashboard page links open; the token is added as the "token" query parameter
+	Url string
+	// SigningKey signs link tokens; changing it invalidates every unused link
+	SigningKey string
+	// ExpireTime is how many seconds a link stays valid
+	ExpireTime time.Duration
+	// SameDevice binds each link to the client that requested it, so a forwarded link cannot log in
+	SameDevice bool
+}
+
+// QrLoginConfig configures approving a desktop login by scanning its QR code with a logged-in phone
+type QrLoginConfig struct {
+	// PayloadPrefix comes before the login code in the QR payload the mobile app reads
+	PayloadPrefix string
+	// ExpireTime is how many seconds the phone has to answer a pending login
+	ExpireTime time.Duration
+	// PollTimeout is how many seconds a poll waits for the answer before reporting "pending"
+	PollTimeout time.Duration
+	// MaxCreates pending logins per Window seconds are allowed from one IP address
+	MaxCreates int
+	Window     time.Duration
+	// QrSize is the QR PNG width and height in pixels
+	QrSize int
+}
+
+// OAuthConfig configures the OAuth 2.0 and OpenID Connect endpoints. Clients are registered
+// through the admin API.
+type OAuthConfig struct {
+	// Issuer is the public base URL of the service; discovery is served under it
+	Issuer string
+	// SigningKeyFile is the PEM RSA private key that signs ID tokens. Without one a key is
+	// generated at startup, so ID tokens do not survive restarts or span instances.
+	SigningKeyFile string
+	// IdTokenExpireTime is how many seconds an ID token is valid
+	IdTokenExpireTime time.Duration
+	// AuthorizationRequestTtl is how many seconds the user has to log in on the hosted page
+	AuthorizationRequestTtl time.Duration
+	// AuthorizationCodeTtl is how many seconds a client has to exchange an authorization code
+	AuthorizationCodeTtl time.Duration
+	// DeviceVerificationUri is the page where users enter the user code after logging in
+	DeviceVerificationUri string
+	// DeviceCodeTtl is how many seconds a device has to be approved
+	DeviceCodeTtl time.Duration
+	// DevicePollInterval is the minimum number of seconds between token polls; slow_down adds 5
+	DevicePollInterval int
+	// MaxDeviceAuthorizations per DeviceAuthorizationWindow seconds are allowed from one IP address
+	MaxDeviceAuthorizations   int
+	DeviceAuthorizationWindow time.Duration
+	// MaxUserCodeAttempts per UserCodeWindow seconds limits user code guesses for one user
+	MaxUserCodeAttempts int
+	UserCodeWindow      time.Duration
+}
+
+type PhoneConfig struct {
+	// DefaultRegion is the ISO 3166 region national input such as 0912... is read in
+	DefaultRegion string
+	// AllowedRegions limits accepted numbers to these regions; empty accepts every region
+	AllowedRegions []string
+}
+
+type PhonePolicyConfig struct {
+	// CacheTtl is how many seconds the in-memory copy of the rules is used without a change notice
+	CacheTtl time.Duration
+}
+
+// AbuseConfig tunes the send-otp abuse heuristics; every score is 0 to 100
+type AbuseConfig struct {
+	Enabled bool
+	// Window is how many seconds the heuristics look back
+	Window time.Duration
+	// IpNumbersThreshold is the count of distinct numbers from one IP that scores 100
+	IpNumbersThreshold int
+	// BlockNumbersThreshold is the count of distinct numbers differing only in the last two digits that scores 100
+	BlockNumbersThreshold int
+	// PrefixLength is the number of E.164 characters, including +, that group numbers into a prefix
+	PrefixLength int
+	// PrefixMinSends is the number of sends a prefix needs before its verify ratio is scored
+	PrefixMinSends int
+	// PrefixMinVerifyRatio is the verified/sent ratio below which a prefix starts to score
+	PrefixMinVerifyRatio float64
+	ChallengeScore       float64
+	ThrottleScore        float64
+	// TopScores is how many subjects per heuristic the admin API lists
+	TopScores int64
+}
+
+// ChallengeConfig gates send-otp behind a challenge when the abuse score or the client's IP rate calls for it
+type ChallengeConfig struct {
+	// Provider is "pow" for the built-in proof of work, "hcaptcha" or "recaptcha"
+	Provider string
+	// IpFreeSends is how many sends one IP gets per IpWindow seconds before a challenge is required
+	IpFreeSends int
+	IpWindow    time.Duration
+	// Secret signs proof-of-work challenges
+	Secret string
+	// Difficulty is the number of leading zero bits a proof-of-work solution needs
+	Difficulty int
+	// Ttl is how many seconds an issued proof-of-work challenge stays valid
+	Ttl time.Duration
+	// SiteKey, CaptchaSecret and VerifyUrl configure the CAPTCHA providers; an empty VerifyUrl
+	// uses the provider's public siteverify endpoint
+	SiteKey       string
+	CaptchaSecret string
+	VerifyUrl     string
+	// MinScore rejects reCAPTCHA v3 tokens scoring lower
+	MinScore float64
+	// Timeout is the siteverify call timeout in seconds
+	Timeout time.Duration
 }
 
 type JWTConfig struct {
@@ -67,6 +277,95 @@ type JWTConfig struct {
 	RefreshSecret              string
 }
 
+type HealthConfig struct {
+	// CheckTimeout is the per-dependency ping timeout in milliseconds
+	CheckTimeout time.Duration
+}
+
+type TracingConfig struct {
+	Enabled     bool
+	ServiceName string
+	// Exporter is "otlp" or "stdout"
+	Exporter string
+	// Endpoint is the OTLP/HTTP collector host:port
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+type LoggerConfig struct {
+	// Backend is "zap" or "zerolog"
+	Backend string
+	// Encoding is "json" or "console"
+	Encoding string
+	// Level is one of debug, info, warn, error, fatal
+	Level   string
+	AppName string
+}
+
+type AccessLogConfig struct {
+	Enabled bool
+	// CaptureBody adds redacted request and response bodies to each entry
+	CaptureBody bool
+	// MaxBodySize is the number of bytes captured per body; larger bodies are truncated
+	MaxBodySize int
+	SkipPaths   []string
+	Redaction   []RedactionRule
+}
+
+// RedactionRule masks a JSON field or query parameter; Mode is "mask", "phone" or "remove"
+type RedactionRule struct {
+	Field string
+	Mode  string
+}
+
+type SmsConfig struct {
+	// Provider is "http" for the SMS gateway or "log" to print messages locally
+	Provider string
+	Url      string
+	ApiKey   string
+	Sender   string
+	// Timeout is the gateway call timeout in seconds
+	Timeout time.Duration
+}
+
+type VoiceConfig struct {
+	// Provider is "http" for the voice-call gateway or "log" to print calls locally
+	Provider string
+	Url      string
+	ApiKey   string
+	CallerId string
+	// Timeout is the gateway call timeout in seconds
+	Timeout time.Duration
+	// FallbackOnSmsFailure calls the number when the SMS gateway rejects a code
+	FallbackOnSmsFailure bool
+	// MaxCalls per number in Window seconds; calls are limited separately from SMS
+	MaxCalls int
+	Window   time.Duration
+}
+
+type EmailConfig struct {
+	// Provider is "smtp" to send mail or "log" to print messages locally
+	Provider string
+	Host     string
+	Port     int
+	// Username and Password are only used when set; leave them empty for a local fake SMTP server
+	Username string
+	Password string
+	From     string
+	// StartTls fails delivery when the server does not offer STARTTLS
+	StartTls bool
+	// Timeout is the SMTP session timeout in seconds
+	Timeout time.Duration
+}
+
+type I18nConfig struct {
+	// DefaultLocale is "fa" or "en", used when Accept-Language names no supported language
+	DefaultLocale string
+	// DefaultTimezone is the IANA zone for times when the client sends no X-Timezone header
+	DefaultTimezone string
+}
+
 func GetConfig() *Config {
 	cfgPath := getConfigPath(os.Getenv("APP_ENV"))
 	v, err := LoadConfig(cfgPath, "yml")
@@ -94,10 +393,45 @@ func GetConfig() *Config {
 	if err != nil {
 		log.Fatalf("Error in parse config %v", err)
 	}
+	if err = loadSecrets(cfg, os.Getenv("APP_ENV")); err != nil {
+		log.Fatalf("Error in config secrets: %v", err)
+	}
 
 	return cfg
 }
 
+// secret is a signing or encryption key that may come from the environment instead of the file
+type secret struct {
+	env    string
+	value  *string
+	sample string
+}
+
+// loadSecrets overrides the keys with their environment variables. Every key must be set, and in
+// production none may keep the sample value the development configs publish.
+func loadSecrets(cfg *Config, env string) error {
+	secrets := []secret{
+		{"JWT_SECRET", &cfg.JWT.Secret, "mySecretKey"},
+		{"JWT_REFRESH_SECRET", &cfg.JWT.RefreshSecret, "mySecretKey"},
+		{"CHALLENGE_SECRET", &cfg.Challenge.Secret, "myChallengeSecret"},
+		{"TOTP_ENCRYPTION_KEY", &cfg.Totp.EncryptionKey, "myTotpEncryptionKey"},
+		{"RECOVERY_HASH_KEY", &cfg.Recovery.HashKey, "myRecoveryCodeHashKey"},
+		{"MAGIC_LINK_SIGNING_KEY", &cfg.MagicLink.SigningKey, "myMagicLinkSigningKey"},
+	}
+	for _, s := range secrets {
+		if value := os.Getenv(s.env); value != "" {
+			*s.value = value
+		}
+		if *s.value == "" {
+			return fmt.Errorf("%s is not set", s.env)
+		}
+		if env == "production" && *s.value == s.sample {
+			return fmt.Errorf("%s still has the sample value", s.env)
+		}
+	}
+	return nil
+}
+
 func ParseConfig(v *viper.Viper) (*Config, error) {
 	var cfg Config
 	err := v.Unmarshal(&cfg)
@@ -6,13 +6,23 @@ const (
 	DefaultRoleName    string = "default"
 	DefaultUserName    string = "admin"
 	RedisOtpDefaultKey string = "otp"
+	RedisMagicLinkKey  string = "magic_link"
 
 	// Claims
 	AuthorizationHeaderKey string = "Authorization"
 	MobileNumberKey        string = "MobileNumber"
+	EmailKey               string = "Email"
 	ExpireTimeKey          string = "Exp"
 	UserIdKey              string = "UserId"
 	RolesKey               string = "Roles"
+	ClientIdKey            string = "client_id"
 	RefreshTokenCookieName string = "refresh_token"
 	RegisteredAtKey        string = "RegisteredAt"
+
+	// Request
+	RequestIdKey string = "RequestId"
+
+	// Error rendering
+	ProblemDetailsKey     string = "ProblemDetails"
+	ProblemTypeBaseUrlKey string = "ProblemTypeBaseUrl"
 )
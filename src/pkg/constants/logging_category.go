@@ -17,7 +17,10 @@ const (
 const (
 	// General
 	Startup         SubCategory = "Startup"
+	Shutdown        SubCategory = "Shutdown"
 	ExternalService SubCategory = "ExternalService"
+	HealthCheck     SubCategory = "HealthCheck"
+	Recovery        SubCategory = "Recovery"
 
 	// Postgres
 	Migration           SubCategory = "Migration"
@@ -28,13 +31,20 @@ const (
 	Insert              SubCategory = "Insert"
 	DefaultRoleNotFound string      = "default role not found"
 
+	// Redis
+	Get     SubCategory = "Get"
+	Set     SubCategory = "Set"
+	Publish SubCategory = "Publish"
+
 	// Internal
 	Api          SubCategory = "Api"
 	HashPassword SubCategory = "HashPassword"
 	UseCase      SubCategory = "UseCase"
+	Passkey      SubCategory = "Passkey"
 
 	// Validation
 	PasswordValidation SubCategory = "PasswordValidation"
+	MobileValidation   SubCategory = "MobileValidation"
 
 	// IO
 	RemoveFile SubCategory = "RemoveFile"
@@ -49,8 +59,13 @@ const (
 	StatusCode   ExtraKey = "StatusCode"
 	BodySize     ExtraKey = "BodySize"
 	Path         ExtraKey = "Path"
+	Query        ExtraKey = "Query"
 	Latency      ExtraKey = "Latency"
 	RequestBody  ExtraKey = "RequestBody"
 	ResponseBody ExtraKey = "ResponseBody"
 	ErrorMessage ExtraKey = "ErrorMessage"
+	RequestId    ExtraKey = "RequestId"
+	MobileNumber ExtraKey = "MobileNumber"
+	Email        ExtraKey = "Email"
+	Stack        ExtraKey = "Stack"
 )
@@ -2,13 +2,14 @@ package db
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/alielmi98/golang-otp-auth/pkg/config"
 	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/logging"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormTracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 var dbClient *gorm.DB
@@ -27,6 +28,11 @@ func InitDb(cfg *config.Config) error {
 		return err
 	}
 
+	err = dbClient.Use(gormTracing.NewPlugin(gormTracing.WithoutMetrics(), gormTracing.WithoutQueryVariables()))
+	if err != nil {
+		return err
+	}
+
 	sqlDb, _ := dbClient.DB()
 	err = sqlDb.Ping()
 	if err != nil {
@@ -37,7 +43,7 @@ func InitDb(cfg *config.Config) error {
 	sqlDb.SetMaxOpenConns(cfg.Postgres.MaxOpenConns)
 	sqlDb.SetConnMaxLifetime(cfg.Postgres.ConnMaxLifetime * time.Minute)
 
-	log.Printf("caller:%s  Level:%s Msg:Db connection established", constants.Postgres, constants.Startup)
+	logging.GetLogger().Info(constants.Postgres, constants.Startup, "Db connection established", nil)
 	return nil
 }
 
@@ -1,10 +1,42 @@
 package helper
 
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/i18n"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
 type BaseHttpResponse struct {
 	Result     any        `json:"result"`
 	Success    bool       `json:"success"`
 	ResultCode ResultCode `json:"resultCode"`
 	Error      any        `json:"error"`
+	ErrorCode  string     `json:"errorCode,omitempty"`
+	RequestId  string     `json:"requestId,omitempty"`
+
+	// messageParams fill the placeholders of the localized Error message
+	messageParams map[string]any
+}
+
+// WriteResponse stamps the request id onto resp, localizes its error for the request locale and
+// writes it with the given status. Failures are rendered as problem+json when the request negotiated it.
+func WriteResponse(c *gin.Context, status int, resp *BaseHttpResponse) {
+	resp.RequestId = requestid.FromContext(c.Request.Context())
+	localizeError(i18n.FromContext(c.Request.Context()), resp)
+	if !resp.Success && status >= http.StatusBadRequest && WantsProblemDetails(c) {
+		renderProblem(c, status, resp)
+		return
+	}
+	c.JSON(status, resp)
+}
+
+// AbortWithResponse is WriteResponse for handlers and middlewares that stop the chain
+func AbortWithResponse(c *gin.Context, status int, resp *BaseHttpResponse) {
+	c.Abort()
+	WriteResponse(c, status, resp)
 }
 
 func GenerateBaseResponse(result any, success bool, resultCode ResultCode) *BaseHttpResponse {
@@ -15,14 +47,22 @@ func GenerateBaseResponse(result any, success bool, resultCode ResultCode) *Base
 }
 
 func GenerateBaseResponseWithError(result any, success bool, resultCode ResultCode, err error) *BaseHttpResponse {
+	code, message, params := translateError(err)
 	return &BaseHttpResponse{Result: result,
-		Success:    success,
-		ResultCode: resultCode,
-		Error:      err.Error(),
+		Success:       success,
+		ResultCode:    resultCode,
+		Error:         message,
+		ErrorCode:     code,
+		messageParams: params,
 	}
 
 }
 
+// GenerateBaseResponseFromError derives the result code from err through the central mapping
+func GenerateBaseResponseFromError(err error) *BaseHttpResponse {
+	return GenerateBaseResponseWithError(nil, false, TranslateErrorToResultCode(err), err)
+}
+
 func GenerateBaseResponseWithAnyError(result any, success bool, resultCode ResultCode, err any) *BaseHttpResponse {
 	return &BaseHttpResponse{Result: result,
 		Success:    success,
@@ -31,9 +71,32 @@ func GenerateBaseResponseWithAnyError(result any, success bool, resultCode Resul
 	}
 }
 
+// GenerateBaseResponseWithValidationError lists the field errors behind err. Pass the binding
+// error wrapped as service_errors.CodeValidation; errorCode is taken from that wrapper.
 func GenerateBaseResponseWithValidationError(result any, success bool, resultCode ResultCode, err error) *BaseHttpResponse {
-	return &BaseHttpResponse{Result: result,
+	resp := &BaseHttpResponse{Result: result,
 		Success:    success,
 		ResultCode: resultCode,
+		Error:      GetValidationErrors(err),
+	}
+	var typed TypedError
+	if errors.As(err, &typed) {
+		resp.ErrorCode = typed.ErrorCode()
+	}
+	return resp
+}
+
+// localizeError replaces the English message of a coded error, or of each field error, with the
+// catalog text for l; messages without a catalog entry are left as they are
+func localizeError(l i18n.Localizer, resp *BaseHttpResponse) {
+	switch e := resp.Error.(type) {
+	case string:
+		if resp.ErrorCode != "" {
+			resp.Error = l.MessageOr(resp.ErrorCode, resp.messageParams, e)
+		}
+	case []FieldError:
+		for i := range e {
+			e[i].Message = fieldErrorMessage(l, e[i])
+		}
 	}
 }
@@ -0,0 +1,75 @@
+package helper
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+const ProblemJsonContentType = "application/problem+json"
+
+// ProblemDetails is the RFC 7807 rendering of a failed BaseHttpResponse. Members after
+// Instance are extensions carrying what the envelope would have carried.
+type ProblemDetails struct {
+	Type       string     `json:"type"`
+	Title      string     `json:"title"`
+	Status     int        `json:"status"`
+	Detail     string     `json:"detail,omitempty"`
+	Instance   string     `json:"instance,omitempty"`
+	ResultCode ResultCode `json:"resultCode"`
+	ErrorCode  string     `json:"errorCode,omitempty"`
+	RequestId  string     `json:"requestId,omitempty"`
+	Errors     any        `json:"errors,omitempty"`
+}
+
+// WantsProblemDetails reports whether the error for this request should use problem+json,
+// as decided by the ProblemDetails middleware
+func WantsProblemDetails(c *gin.Context) bool {
+	return c.GetBool(constants.ProblemDetailsKey)
+}
+
+// NewProblemDetails converts a failed envelope; typeBaseUrl + lower-kebab errorCode forms the type URI
+func NewProblemDetails(c *gin.Context, status int, resp *BaseHttpResponse, typeBaseUrl string) *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Instance:   c.Request.URL.Path,
+		ResultCode: resp.ResultCode,
+		ErrorCode:  resp.ErrorCode,
+		RequestId:  resp.RequestId,
+	}
+	if resp.ErrorCode != "" && typeBaseUrl != "" {
+		problem.Type = strings.TrimSuffix(typeBaseUrl, "/") + "/" + strings.ReplaceAll(strings.ToLower(resp.ErrorCode), "_", "-")
+	}
+	switch e := resp.Error.(type) {
+	case string:
+		problem.Detail = e
+	case nil:
+	default:
+		problem.Errors = e
+	}
+	return problem
+}
+
+func renderProblem(c *gin.Context, status int, resp *BaseHttpResponse) {
+	problem := NewProblemDetails(c, status, resp, c.GetString(constants.ProblemTypeBaseUrlKey))
+	c.Render(status, problemJSON{problem})
+}
+
+// problemJSON is render.JSON with the problem+json content type
+type problemJSON struct {
+	data any
+}
+
+func (r problemJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.data)
+}
+
+func (r problemJSON) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ProblemJsonContentType)
+}
@@ -1,37 +1,53 @@
 package helper
 
 import (
+	"errors"
 	"net/http"
-
-	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
 )
 
-var StatusCodeMapping = map[string]int{
-	// User
-	service_errors.EmailExists:               409,
-	service_errors.UsernameExists:            409,
-	service_errors.RecordNotFound:            404,
-	service_errors.PermissionDenied:          403,
-	service_errors.UsernameOrPasswordInvalid: 401,
-	// Token
-	service_errors.InvalidRefreshToken: 401,
-	service_errors.TokenRequired:       401,
-	service_errors.TokenExpired:        401,
-	service_errors.TokenInvalid:        401,
-	service_errors.ClaimsNotFound:      401,
-	service_errors.InvalidRolesFormat:  400,
-	// OTP
-	service_errors.OptExists:   409,
-	service_errors.OtpUsed:     400,
-	service_errors.OtpNotValid: 400,
-	// Validation
-	service_errors.ValidationError: 400,
+// TypedError is implemented by service_errors.ServiceError. It is declared here so rendering
+// does not depend on the errors package; the code-to-status mapping itself lives there.
+type TypedError interface {
+	error
+	ErrorCode() string
+	StatusCode() int
+	ResponseCode() ResultCode
+	MessageParams() map[string]any
 }
 
+const (
+	internalErrorCode    = "INTERNAL_ERROR"
+	internalErrorMessage = "Internal server error"
+)
+
 func TranslateErrorToStatusCode(err error) int {
-	value, ok := StatusCodeMapping[err.Error()]
-	if !ok {
-		return http.StatusInternalServerError
+	var typed TypedError
+	if errors.As(err, &typed) && typed.StatusCode() != 0 {
+		return typed.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+func TranslateErrorToResultCode(err error) ResultCode {
+	var typed TypedError
+	if errors.As(err, &typed) && typed.ResponseCode() != 0 {
+		return typed.ResponseCode()
+	}
+	return InternalError
+}
+
+// TranslateErrorToCodeAndMessage returns what a client may see about err; errors that are not
+// typed are reported as a generic internal error so driver messages such as "redis: nil" never leak
+func TranslateErrorToCodeAndMessage(err error) (string, string) {
+	code, message, _ := translateError(err)
+	return code, message
+}
+
+// translateError is TranslateErrorToCodeAndMessage plus the params for localizing the message
+func translateError(err error) (string, string, map[string]any) {
+	var typed TypedError
+	if errors.As(err, &typed) {
+		return typed.ErrorCode(), typed.Error(), typed.MessageParams()
 	}
-	return value
+	return internalErrorCode, internalErrorMessage, nil
 }
@@ -0,0 +1,51 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/i18n"
+	"github.com/go-playground/validator/v10"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// GetValidationErrors flattens binding errors into one entry per offending field. Field names
+// are the JSON names registered through the validator's tag name func. Messages are English
+// here; WriteResponse localizes them for the request.
+func GetValidationErrors(err error) []FieldError {
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		result := make([]FieldError, 0, len(fieldErrors))
+		for _, fe := range fieldErrors {
+			fieldErr := FieldError{Field: fe.Field(), Tag: fe.Tag(), Param: fe.Param()}
+			fieldErr.Message = fieldErrorMessage(i18n.Default, fieldErr)
+			result = append(result, fieldErr)
+		}
+		return result
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		fieldErr := FieldError{Field: typeErr.Field, Tag: "type", Param: typeErr.Type.String()}
+		fieldErr.Message = fieldErrorMessage(i18n.Default, fieldErr)
+		return []FieldError{fieldErr}
+	}
+
+	fieldErr := FieldError{Tag: "body"}
+	fieldErr.Message = fieldErrorMessage(i18n.Default, fieldErr)
+	return []FieldError{fieldErr}
+}
+
+func fieldErrorMessage(l i18n.Localizer, fe FieldError) string {
+	params := map[string]any{"field": fe.Field, "param": fe.Param, "tag": fe.Tag}
+	if message, ok := l.Message("validation."+fe.Tag, params); ok {
+		return message
+	}
+	return l.MessageOr("validation.default", params, fe.Tag)
+}
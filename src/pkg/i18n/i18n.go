@@ -0,0 +1,147 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	LocaleEn = "en"
+	LocaleFa = "fa"
+
+	// TimezoneHeader carries the client's IANA timezone, e.g. Asia/Tehran
+	TimezoneHeader = "X-Timezone"
+
+	// SmsOtpKey is the catalog key of the OTP SMS text; it receives {code}
+	SmsOtpKey = "SMS_OTP"
+	// EmailOtpSubjectKey and EmailOtpKey are the catalog keys of the OTP email; the body receives {code}
+	EmailOtpSubjectKey = "EMAIL_OTP_SUBJECT"
+	EmailOtpKey        = "EMAIL_OTP"
+	// VoiceOtpKey is the catalog key of the text read aloud on an OTP call; it receives {digits},
+	// the code with its digits spaced so text-to-speech reads them one by one
+	VoiceOtpKey = "VOICE_OTP"
+	// SmsMagicLinkKey, EmailMagicLinkSubjectKey and EmailMagicLinkKey are the catalog keys of the
+	// login-link messages; the texts receive {link} and the email {minutes} until it expires
+	SmsMagicLinkKey          = "SMS_MAGIC_LINK"
+	EmailMagicLinkSubjectKey = "EMAIL_MAGIC_LINK_SUBJECT"
+	EmailMagicLinkKey        = "EMAIL_MAGIC_LINK"
+)
+
+// supported is ordered by preference; the first entry wins when nothing matches
+var supported = []language.Tag{language.English, language.Persian}
+
+var catalogs = map[string]map[string]string{
+	LocaleEn: messagesEn,
+	LocaleFa: messagesFa,
+}
+
+// Localizer renders catalog messages for one locale and formats times in one timezone
+type Localizer struct {
+	Locale   string
+	Location *time.Location
+}
+
+// Default is used when no localizer was stored in the context, e.g. outside a request
+var Default = Localizer{Locale: LocaleEn, Location: time.UTC}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l
+func NewContext(ctx context.Context, l Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the localizer stored in ctx, or Default when there is none
+func FromContext(ctx context.Context) Localizer {
+	if ctx == nil {
+		return Default
+	}
+	if l, ok := ctx.Value(contextKey{}).(Localizer); ok {
+		return l
+	}
+	return Default
+}
+
+// MatchLocale picks the supported locale that best matches an Accept-Language header,
+// falling back when the header is empty or names only unsupported languages
+func MatchLocale(acceptLanguage, fallback string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return fallback
+	}
+	_, index, confidence := language.NewMatcher(supported).Match(tags...)
+	if confidence == language.No {
+		return fallback
+	}
+	base, _ := supported[index].Base()
+	return base.String()
+}
+
+// LoadLocation resolves an IANA timezone name, falling back when it is empty or unknown
+func LoadLocation(name string, fallback *time.Location) *time.Location {
+	if name == "" {
+		return fallback
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// Message returns the catalog text for key with {name} placeholders replaced from params.
+// Missing keys fall back to English; ok is false when neither catalog has the key.
+func (l Localizer) Message(key string, params map[string]any) (string, bool) {
+	template, ok := catalogs[l.Locale][key]
+	if !ok {
+		template, ok = messagesEn[key]
+	}
+	if !ok {
+		return "", false
+	}
+	if len(params) == 0 {
+		return template, true
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", l.format(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template), true
+}
+
+// MessageOr is Message with a fallback for keys missing from every catalog
+func (l Localizer) MessageOr(key string, params map[string]any, fallback string) string {
+	if message, ok := l.Message(key, params); ok {
+		return message
+	}
+	return fallback
+}
+
+func (l Localizer) format(value any) string {
+	if t, ok := value.(time.Time); ok {
+		location := l.Location
+		if location == nil {
+			location = time.UTC
+		}
+		return l.digits(t.In(location).Format("15:04:05"))
+	}
+	return fmt.Sprint(value)
+}
+
+// digits converts ASCII digits to Persian ones for the fa locale. Only formatted values such as
+// times go through it; OTP codes stay ASCII so phones can autofill them.
+func (l Localizer) digits(s string) string {
+	if l.Locale != LocaleFa {
+		return s
+	}
+	return persianDigits.Replace(s)
+}
+
+var persianDigits = strings.NewReplacer(
+	"0", "۰", "1", "۱", "2", "۲", "3", "۳", "4", "۴",
+	"5", "۵", "6", "۶", "7", "۷", "8", "۸", "9", "۹",
+)
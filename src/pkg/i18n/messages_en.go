@@ -0,0 +1,95 @@
+package i18n
+
+// messagesEn is keyed by service error code, "validation.<tag>" and the other *Key constants
+var messagesEn = map[string]string{
+	// Token
+	"UNEXPECTED_ERROR":      "An unexpected error occurred",
+	"CLAIMS_NOT_FOUND":      "Token claims were not found",
+	"TOKEN_REQUIRED":        "An access token is required",
+	"TOKEN_EXPIRED":         "The token has expired",
+	"TOKEN_INVALID":         "The token is invalid",
+	"INVALID_REFRESH_TOKEN": "The refresh token is invalid",
+	"INVALID_ROLES_FORMAT":  "The roles format is invalid",
+	// OTP
+	"OTP_EXISTS":         "A code was already sent; please wait until it expires",
+	"OTP_USED":           "This code has already been used",
+	"OTP_INVALID":        "The code is incorrect",
+	"OTP_EXPIRED":        "The code has expired; please request a new one",
+	"OTP_LOCKED":         "Too many incorrect attempts; please request a new code",
+	"OTP_RATE_LIMITED":   "Too many code requests. Try again after {resetTime}",
+	"OTP_THROTTLED":      "Too many code requests from your network; please try again later",
+	"CHALLENGE_REQUIRED": "Please complete the verification challenge and try again",
+	"CHALLENGE_FAILED":   "The verification challenge was not solved; please request a new one",
+	// User
+	"EMAIL_EXISTS":              "This email is already registered",
+	"USERNAME_EXISTS":           "This username is already taken",
+	"PERMISSION_DENIED":         "Permission denied",
+	"INVALID_CREDENTIALS":       "Username or password is incorrect",
+	"INVALID_MOBILE_NUMBER":     "The mobile number is not valid",
+	"INVALID_EMAIL":             "The email address is not valid",
+	"PASSWORD_TOO_WEAK":         "The password must be {minLength} to {maxLength} characters, mix {minClasses} of lower case, upper case, digits and symbols, and not contain your phone number or email",
+	"PASSWORD_LOCKED":           "Too many wrong passwords. Try again after {resetTime} or log in with a code",
+	"CURRENT_PASSWORD_REQUIRED": "Please enter your current password",
+	// Second factor
+	"TOTP_NOT_ENROLLED":     "No authenticator app is set up for this account",
+	"TOTP_ALREADY_ENROLLED": "An authenticator app is already set up; remove it first",
+	"MFA_TOKEN_INVALID":     "The login step has expired; please sign in again",
+	"RECOVERY_CODE_INVALID": "The recovery code is incorrect or was already used",
+	// Passkey
+	"PASSKEY_INVALID":           "The passkey could not be verified",
+	"PASSKEY_CHALLENGE_EXPIRED": "The passkey request has expired; please try again",
+	"PASSKEY_EXISTS":            "This passkey is already registered",
+	// Magic link
+	"MAGIC_LINK_INVALID":      "The login link is invalid",
+	"MAGIC_LINK_EXPIRED":      "The login link has expired; please request a new one",
+	"MAGIC_LINK_USED":         "This login link has already been used",
+	"MAGIC_LINK_OTHER_DEVICE": "Open the login link on the device you requested it from",
+	// QR login
+	"QR_LOGIN_EXPIRED":  "The QR code has expired; please reload it",
+	"QR_LOGIN_ANSWERED": "This login was already approved or denied",
+	// Device authorization
+	"USER_CODE_INVALID":             "The code is incorrect or has expired; check the code on your device",
+	"DEVICE_AUTHORIZATION_ANSWERED": "This device was already approved or denied",
+	// OAuth
+	"AUTHORIZATION_REQUEST_EXPIRED": "The sign-in request has expired; return to the application and try again",
+	"INVALID_OAUTH_CLIENT":          "The client registration is invalid",
+	"OAUTH_CLIENT_EXISTS":           "A client with this id is already registered",
+	// Phone policy
+	"PHONE_NUMBER_BLOCKED": "This phone number cannot receive verification codes",
+	"COUNTRY_NOT_ALLOWED":  "Phone numbers from this country are not supported",
+	"INVALID_PHONE_POLICY": "The policy value does not match its kind",
+	// Validation
+	"VALIDATION_ERROR":  "The request is invalid",
+	"USER_ID_NOT_FOUND": "User id was not found",
+	// DB
+	"RECORD_NOT_FOUND": "The record was not found",
+	"DATABASE_ERROR":   "Internal server error",
+	// Internal
+	"INTERNAL_ERROR": "Internal server error",
+	"UNKNOWN_ERROR":  "Unknown error",
+
+	// Field validation; {field} is the JSON name and {param} the rule parameter
+	"validation.required": "{field} is required",
+	"validation.min":      "{field} must be at least {param} characters long",
+	"validation.max":      "{field} must be at most {param} characters long",
+	"validation.len":      "{field} must be exactly {param} characters long",
+	"validation.oneof":    "{field} must be one of: {param}",
+	"validation.numeric":  "{field} must contain digits only",
+	"validation.mobile":   "{field} must be a valid mobile number, e.g. 09121234567 or +447911123456",
+	"validation.type":     "{field} must be of type {param}",
+	"validation.body":     "request body is not valid JSON",
+	"validation.default":  "{field} failed on the {tag} rule",
+	"validation.email":    "{field} must be a valid email address, e.g. name@example.com",
+
+	"validation.required_without": "{field} is required when {param} is not given",
+	"validation.excluded_with":    "{field} cannot be sent together with {param}",
+
+	SmsOtpKey:          "Your verification code: {code}",
+	EmailOtpSubjectKey: "Your verification code",
+	VoiceOtpKey:        "Your verification code is {digits}. Again, your code is {digits}.",
+	EmailOtpKey:        "Your verification code is {code}.\n\nIf you did not request this code, you can ignore this email.",
+
+	SmsMagicLinkKey:          "Log in: {link}",
+	EmailMagicLinkSubjectKey: "Your login link",
+	EmailMagicLinkKey:        "Open this link to log in:\n\n{link}\n\nThe link works once and expires in {minutes} minutes. If you did not request it, you can ignore this email.",
+}
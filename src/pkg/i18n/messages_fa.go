@@ -0,0 +1,93 @@
+package i18n
+
+var messagesFa = map[string]string{
+	// Token
+	"UNEXPECTED_ERROR":      "خطای غیرمنتظره‌ای رخ داد",
+	"CLAIMS_NOT_FOUND":      "اطلاعات توکن یافت نشد",
+	"TOKEN_REQUIRED":        "توکن دسترسی الزامی است",
+	"TOKEN_EXPIRED":         "توکن منقضی شده است",
+	"TOKEN_INVALID":         "توکن نامعتبر است",
+	"INVALID_REFRESH_TOKEN": "توکن تازه‌سازی نامعتبر است",
+	"INVALID_ROLES_FORMAT":  "قالب نقش‌ها نامعتبر است",
+	// OTP
+	"OTP_EXISTS":         "کد تأیید قبلاً ارسال شده است؛ لطفاً تا پایان اعتبار آن صبر کنید",
+	"OTP_USED":           "این کد تأیید قبلاً استفاده شده است",
+	"OTP_INVALID":        "کد تأیید نادرست است",
+	"OTP_EXPIRED":        "کد تأیید منقضی شده است؛ لطفاً کد جدید درخواست کنید",
+	"OTP_LOCKED":         "تعداد تلاش‌های ناموفق بیش از حد مجاز است؛ لطفاً کد جدید درخواست کنید",
+	"OTP_RATE_LIMITED":   "تعداد درخواست‌های کد تأیید بیش از حد مجاز است. لطفاً بعد از ساعت {resetTime} دوباره تلاش کنید",
+	"OTP_THROTTLED":      "درخواست‌های کد تأیید از شبکه شما بیش از حد است؛ لطفاً بعداً دوباره تلاش کنید",
+	"CHALLENGE_REQUIRED": "لطفاً آزمون امنیتی را کامل کرده و دوباره تلاش کنید",
+	"CHALLENGE_FAILED":   "آزمون امنیتی با موفقیت انجام نشد؛ لطفاً آزمون جدیدی دریافت کنید",
+	// User
+	"EMAIL_EXISTS":              "این ایمیل قبلاً ثبت شده است",
+	"USERNAME_EXISTS":           "این نام کاربری قبلاً ثبت شده است",
+	"PERMISSION_DENIED":         "دسترسی مجاز نیست",
+	"INVALID_CREDENTIALS":       "نام کاربری یا رمز عبور نادرست است",
+	"INVALID_MOBILE_NUMBER":     "شماره موبایل معتبر نیست",
+	"INVALID_EMAIL":             "آدرس ایمیل معتبر نیست",
+	"PASSWORD_TOO_WEAK":         "رمز عبور باید {minLength} تا {maxLength} نویسه باشد، دست‌کم {minClasses} نوع از حروف کوچک، حروف بزرگ، ارقام و نمادها را داشته باشد و شامل شماره تلفن یا ایمیل شما نباشد",
+	"PASSWORD_LOCKED":           "تعداد رمزهای نادرست بیش از حد مجاز است. لطفاً بعد از ساعت {resetTime} دوباره تلاش کنید یا با کد تأیید وارد شوید",
+	"CURRENT_PASSWORD_REQUIRED": "لطفاً رمز عبور فعلی خود را وارد کنید",
+	// Second factor
+	"TOTP_NOT_ENROLLED":     "برنامه احراز هویت برای این حساب تنظیم نشده است",
+	"TOTP_ALREADY_ENROLLED": "برنامه احراز هویت قبلاً تنظیم شده است؛ ابتدا آن را حذف کنید",
+	"MFA_TOKEN_INVALID":     "مرحله ورود منقضی شده است؛ لطفاً دوباره وارد شوید",
+	"RECOVERY_CODE_INVALID": "کد بازیابی نادرست است یا قبلاً استفاده شده است",
+	// Passkey
+	"PASSKEY_INVALID":           "کلید عبور تأیید نشد",
+	"PASSKEY_CHALLENGE_EXPIRED": "درخواست کلید عبور منقضی شده است؛ لطفاً دوباره تلاش کنید",
+	"PASSKEY_EXISTS":            "این کلید عبور قبلاً ثبت شده است",
+	// Magic link
+	"MAGIC_LINK_INVALID":      "پیوند ورود معتبر نیست",
+	"MAGIC_LINK_EXPIRED":      "پیوند ورود منقضی شده است؛ لطفاً پیوند جدیدی درخواست کنید",
+	"MAGIC_LINK_USED":         "این پیوند ورود قبلاً استفاده شده است",
+	"MAGIC_LINK_OTHER_DEVICE": "پیوند ورود را روی همان دستگاهی باز کنید که آن را درخواست کرده‌اید",
+	// QR login
+	"QR_LOGIN_EXPIRED":  "کد QR منقضی شده است؛ لطفاً آن را دوباره بارگذاری کنید",
+	"QR_LOGIN_ANSWERED": "این ورود قبلاً تأیید یا رد شده است",
+	// Device authorization
+	"USER_CODE_INVALID":             "کد نادرست است یا منقضی شده است؛ کد روی دستگاه خود را بررسی کنید",
+	"DEVICE_AUTHORIZATION_ANSWERED": "این دستگاه قبلاً تأیید یا رد شده است",
+	// OAuth
+	"AUTHORIZATION_REQUEST_EXPIRED": "درخواست ورود منقضی شده است؛ به برنامه برگردید و دوباره تلاش کنید",
+	"INVALID_OAUTH_CLIENT":          "مشخصات ثبت برنامه نامعتبر است",
+	"OAUTH_CLIENT_EXISTS":           "برنامه‌ای با این شناسه قبلاً ثبت شده است",
+	// Phone policy
+	"PHONE_NUMBER_BLOCKED": "امکان ارسال کد تأیید به این شماره وجود ندارد",
+	"COUNTRY_NOT_ALLOWED":  "شماره‌های این کشور پشتیبانی نمی‌شوند",
+	"INVALID_PHONE_POLICY": "مقدار سیاست با نوع آن مطابقت ندارد",
+	// Validation
+	"VALIDATION_ERROR":  "اطلاعات ارسال‌شده نامعتبر است",
+	"USER_ID_NOT_FOUND": "شناسه کاربر یافت نشد",
+	// DB
+	"RECORD_NOT_FOUND": "موردی یافت نشد",
+	"DATABASE_ERROR":   "خطای داخلی سرور",
+	// Internal
+	"INTERNAL_ERROR": "خطای داخلی سرور",
+	"UNKNOWN_ERROR":  "خطای ناشناخته",
+
+	"validation.required": "{field} الزامی است",
+	"validation.min":      "{field} باید حداقل {param} کاراکتر باشد",
+	"validation.max":      "{field} باید حداکثر {param} کاراکتر باشد",
+	"validation.len":      "{field} باید دقیقاً {param} کاراکتر باشد",
+	"validation.oneof":    "{field} باید یکی از این مقادیر باشد: {param}",
+	"validation.numeric":  "{field} فقط باید شامل رقم باشد",
+	"validation.mobile":   "{field} باید شماره موبایل معتبر باشد، مانند 09121234567 یا +447911123456",
+	"validation.type":     "{field} باید از نوع {param} باشد",
+	"validation.body":     "بدنه درخواست JSON معتبر نیست",
+	"validation.default":  "{field} با قاعده {tag} مطابقت ندارد",
+	"validation.email":    "{field} باید آدرس ایمیل معتبر باشد، مانند name@example.com",
+
+	"validation.required_without": "وقتی {param} ارسال نشده، {field} الزامی است",
+	"validation.excluded_with":    "{field} را نمی‌توان همراه با {param} ارسال کرد",
+
+	SmsOtpKey:          "کد تأیید شما: {code}",
+	EmailOtpSubjectKey: "کد تأیید شما",
+	VoiceOtpKey:        "کد تأیید شما {digits} است. تکرار می‌کنم، کد شما {digits} است.",
+	EmailOtpKey:        "کد تأیید شما {code} است.\n\nاگر این کد را درخواست نکرده‌اید، این ایمیل را نادیده بگیرید.",
+
+	SmsMagicLinkKey:          "ورود: {link}",
+	EmailMagicLinkSubjectKey: "پیوند ورود شما",
+	EmailMagicLinkKey:        "برای ورود این پیوند را باز کنید:\n\n{link}\n\nاین پیوند یک بار کار می‌کند و تا {minutes} دقیقه دیگر منقضی می‌شود. اگر آن را درخواست نکرده‌اید، این ایمیل را نادیده بگیرید.",
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+)
+
+const (
+	ZapBackend     = "zap"
+	ZeroLogBackend = "zerolog"
+)
+
+// Logger writes leveled, structured entries; every entry carries its category and sub-category
+// so logs can be filtered by layer (Postgres/Redis/...) and action (Startup/Select/...).
+type Logger interface {
+	Init()
+
+	// WithContext returns a logger that adds the request id found in ctx to every entry
+	WithContext(ctx context.Context) Logger
+
+	Debug(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{})
+	Debugf(template string, args ...interface{})
+
+	Info(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{})
+	Infof(template string, args ...interface{})
+
+	Warn(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{})
+	Warnf(template string, args ...interface{})
+
+	Error(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{})
+	Errorf(template string, args ...interface{})
+
+	Fatal(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{})
+	Fatalf(template string, args ...interface{})
+}
+
+var (
+	logger Logger
+	once   sync.Once
+)
+
+// NewLogger builds the backend selected by logger.backend
+func NewLogger(cfg *config.Config) Logger {
+	var l Logger
+	switch cfg.Logger.Backend {
+	case ZeroLogBackend:
+		l = newZeroLogger(cfg)
+	default:
+		l = newZapLogger(cfg)
+	}
+	l.Init()
+	return l
+}
+
+// InitLogger sets up the process-wide logger; later calls are no-ops
+func InitLogger(cfg *config.Config) {
+	once.Do(func() {
+		logger = NewLogger(cfg)
+	})
+}
+
+// GetLogger returns the process-wide logger, falling back to the file config if InitLogger was not called
+func GetLogger() Logger {
+	once.Do(func() {
+		logger = NewLogger(config.GetConfig())
+	})
+	return logger
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"context"
+	"os"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var zapLogLevelMapping = map[string]zapcore.Level{
+	"debug": zapcore.DebugLevel,
+	"info":  zapcore.InfoLevel,
+	"warn":  zapcore.WarnLevel,
+	"error": zapcore.ErrorLevel,
+	"fatal": zapcore.FatalLevel,
+}
+
+type zapLogger struct {
+	cfg    *config.Config
+	logger *zap.SugaredLogger
+}
+
+func newZapLogger(cfg *config.Config) *zapLogger {
+	return &zapLogger{cfg: cfg}
+}
+
+func (l *zapLogger) getLogLevel() zapcore.Level {
+	level, exists := zapLogLevelMapping[l.cfg.Logger.Level]
+	if !exists {
+		return zapcore.DebugLevel
+	}
+	return level
+}
+
+func (l *zapLogger) Init() {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var encoder zapcore.Encoder
+	if l.cfg.Logger.Encoding == "console" {
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), l.getLogLevel())
+	l.logger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zapcore.ErrorLevel)).
+		Sugar().
+		With(string(constants.AppName), l.cfg.Logger.AppName, string(constants.LoggerName), ZapBackend)
+}
+
+func (l *zapLogger) WithContext(ctx context.Context) Logger {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return l
+	}
+	return &zapLogger{cfg: l.cfg, logger: l.logger.With(string(constants.RequestId), id)}
+}
+
+func (l *zapLogger) Debug(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Debugw(msg, prepareLogFields(cat, sub, extra)...)
+}
+
+func (l *zapLogger) Debugf(template string, args ...interface{}) {
+	l.logger.Debugf(template, args...)
+}
+
+func (l *zapLogger) Info(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Infow(msg, prepareLogFields(cat, sub, extra)...)
+}
+
+func (l *zapLogger) Infof(template string, args ...interface{}) {
+	l.logger.Infof(template, args...)
+}
+
+func (l *zapLogger) Warn(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Warnw(msg, prepareLogFields(cat, sub, extra)...)
+}
+
+func (l *zapLogger) Warnf(template string, args ...interface{}) {
+	l.logger.Warnf(template, args...)
+}
+
+func (l *zapLogger) Error(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Errorw(msg, prepareLogFields(cat, sub, extra)...)
+}
+
+func (l *zapLogger) Errorf(template string, args ...interface{}) {
+	l.logger.Errorf(template, args...)
+}
+
+func (l *zapLogger) Fatal(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Fatalw(msg, prepareLogFields(cat, sub, extra)...)
+}
+
+func (l *zapLogger) Fatalf(template string, args ...interface{}) {
+	l.logger.Fatalf(template, args...)
+}
+
+func prepareLogFields(cat constants.Category, sub constants.SubCategory, extra map[constants.ExtraKey]interface{}) []interface{} {
+	params := make([]interface{}, 0, 4+len(extra)*2)
+	params = append(params, "Category", string(cat), "SubCategory", string(sub))
+	for k, v := range extra {
+		params = append(params, string(k), v)
+	}
+	return params
+}
@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"context"
+	"os"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/config"
+	"github.com/alielmi98/golang-otp-auth/pkg/constants"
+	"github.com/alielmi98/golang-otp-auth/pkg/requestid"
+	"github.com/rs/zerolog"
+)
+
+var zeroLogLevelMapping = map[string]zerolog.Level{
+	"debug": zerolog.DebugLevel,
+	"info":  zerolog.InfoLevel,
+	"warn":  zerolog.WarnLevel,
+	"error": zerolog.ErrorLevel,
+	"fatal": zerolog.FatalLevel,
+}
+
+type zeroLogger struct {
+	cfg    *config.Config
+	logger *zerolog.Logger
+}
+
+func newZeroLogger(cfg *config.Config) *zeroLogger {
+	return &zeroLogger{cfg: cfg}
+}
+
+func (l *zeroLogger) getLogLevel() zerolog.Level {
+	level, exists := zeroLogLevelMapping[l.cfg.Logger.Level]
+	if !exists {
+		return zerolog.DebugLevel
+	}
+	return level
+}
+
+func (l *zeroLogger) Init() {
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+
+	var base zerolog.Logger
+	if l.cfg.Logger.Encoding == "console" {
+		base = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout})
+	} else {
+		base = zerolog.New(os.Stdout)
+	}
+	logger := base.Level(l.getLogLevel()).
+		With().
+		Timestamp().
+		Str(string(constants.AppName), l.cfg.Logger.AppName).
+		Str(string(constants.LoggerName), ZeroLogBackend).
+		Logger()
+	l.logger = &logger
+}
+
+func (l *zeroLogger) WithContext(ctx context.Context) Logger {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return l
+	}
+	logger := l.logger.With().Str(string(constants.RequestId), id).Logger()
+	return &zeroLogger{cfg: l.cfg, logger: &logger}
+}
+
+func (l *zeroLogger) Debug(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Debug().Fields(mapToZeroParams(cat, sub, extra)).Msg(msg)
+}
+
+func (l *zeroLogger) Debugf(template string, args ...interface{}) {
+	l.logger.Debug().Msgf(template, args...)
+}
+
+func (l *zeroLogger) Info(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Info().Fields(mapToZeroParams(cat, sub, extra)).Msg(msg)
+}
+
+func (l *zeroLogger) Infof(template string, args ...interface{}) {
+	l.logger.Info().Msgf(template, args...)
+}
+
+func (l *zeroLogger) Warn(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Warn().Fields(mapToZeroParams(cat, sub, extra)).Msg(msg)
+}
+
+func (l *zeroLogger) Warnf(template string, args ...interface{}) {
+	l.logger.Warn().Msgf(template, args...)
+}
+
+func (l *zeroLogger) Error(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Error().Fields(mapToZeroParams(cat, sub, extra)).Msg(msg)
+}
+
+func (l *zeroLogger) Errorf(template string, args ...interface{}) {
+	l.logger.Error().Msgf(template, args...)
+}
+
+func (l *zeroLogger) Fatal(cat constants.Category, sub constants.SubCategory, msg string, extra map[constants.ExtraKey]interface{}) {
+	l.logger.Fatal().Fields(mapToZeroParams(cat, sub, extra)).Msg(msg)
+}
+
+func (l *zeroLogger) Fatalf(template string, args ...interface{}) {
+	l.logger.Fatal().Msgf(template, args...)
+}
+
+func mapToZeroParams(cat constants.Category, sub constants.SubCategory, extra map[constants.ExtraKey]interface{}) map[string]interface{} {
+	params := make(map[string]interface{}, 2+len(extra))
+	params["Category"] = string(cat)
+	params["SubCategory"] = string(sub)
+	for k, v := range extra {
+		params[string(k)] = v
+	}
+	return params
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "otp_auth"
+
+// OTP purposes used as the "purpose" label
+const (
+	PurposeLogin = "login"
+	// PurposeSecondFactor counts authenticator-app codes
+	PurposeSecondFactor = "second_factor"
+	// PurposePasswordReset counts codes used to reset a password
+	PurposePasswordReset = "password_reset"
+	// PurposeMagicLink counts login links
+	PurposeMagicLink = "magic_link"
+)
+
+// Passkey ceremony and login result labels; QR logins and devices are approved or denied
+const (
+	CeremonyRegistration = "registration"
+	CeremonyLogin        = "login"
+	ResultSuccess        = "success"
+	ResultFailure        = "failure"
+	ResultLocked         = "locked"
+	ResultApproved       = "approved"
+	ResultDenied         = "denied"
+)
+
+var (
+	// HTTP
+	HttpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	PanicsRecovered = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_panics_recovered_total",
+		Help:      "Handler panics caught by the recovery middleware.",
+	})
+
+	// OTP
+	OtpSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_sent_total",
+		Help:      "OTPs generated and handed to a delivery channel.",
+	}, []string{"purpose"})
+	OtpVerified = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_verified_total",
+		Help:      "OTPs successfully verified.",
+	}, []string{"purpose"})
+	OtpFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_failed_total",
+		Help:      "OTP verifications rejected because the code was wrong or already used.",
+	}, []string{"purpose"})
+	OtpExpired = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_expired_total",
+		Help:      "OTP verifications attempted after the code expired.",
+	}, []string{"purpose"})
+
+	OtpDeliveryFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "otp_delivery_failures_total",
+		Help:      "OTPs a delivery channel's gateway failed to accept.",
+	}, []string{"channel"})
+
+	PasskeyCeremonies = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "passkey_ceremonies_total",
+		Help:      "WebAuthn registrations and logins by result.",
+	}, []string{"ceremony", "result"})
+
+	PasswordLogins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "password_logins_total",
+		Help:      "Password login attempts by result.",
+	}, []string{"result"})
+
+	QrLogins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "qr_logins_total",
+		Help:      "QR logins answered on a phone, by result.",
+	}, []string{"result"})
+
+	DeviceAuthorizations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "device_authorizations_total",
+		Help:      "OAuth device authorizations answered by a user, by result.",
+	}, []string{"result"})
+
+	AuthorizationCodes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "authorization_codes_total",
+		Help:      "OpenID Connect authorization codes exchanged at the token endpoint, by result.",
+	}, []string{"result"})
+
+	// Rate limit
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by a rate-limit policy.",
+	}, []string{"policy"})
+
+	// Abuse
+	AbuseVerdicts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "abuse_verdicts_total",
+		Help:      "Send-otp abuse assessments by verdict.",
+	}, []string{"verdict"})
+
+	// Phone policy
+	PhonePolicyRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "phone_policy_rejections_total",
+		Help:      "OTP sends rejected by an admin phone policy rule.",
+	}, []string{"rule"})
+
+	// Token
+	TokensIssued = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_issued_total",
+		Help:      "Access/refresh token pairs issued on login.",
+	})
+	TokenRefreshes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_refreshes_total",
+		Help:      "Token pairs issued by exchanging a refresh token.",
+	})
+
+	// Storage
+	RedisCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Redis command latency by command name.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command"})
+	PostgresQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "postgres_query_duration_seconds",
+		Help:      "Postgres repository call latency by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+)
+
+// Handler serves the default registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObservePostgres records the latency of a repository call; use as `defer metrics.ObservePostgres("op", time.Now())`
+func ObservePostgres(operation string, start time.Time) {
+	PostgresQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -1,22 +1,27 @@
 package ratelimit
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // RateLimiter defines the interface for rate limiting operations
 type RateLimiter interface {
 	// CheckLimit checks if the key has exceeded the rate limit
 	// Returns true if allowed, false if rate limited
-	CheckLimit(key string, limit int, window time.Duration) (bool, error)
-	
+	CheckLimit(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+
 	// GetRemainingAttempts returns the number of remaining attempts for the key
-	GetRemainingAttempts(key string, limit int, window time.Duration) (int, error)
-	
+	GetRemainingAttempts(ctx context.Context, key string, limit int, window time.Duration) (int, error)
+
 	// GetResetTime returns the time when the rate limit will reset for the key
-	GetResetTime(key string, window time.Duration) (time.Time, error)
+	GetResetTime(ctx context.Context, key string, window time.Duration) (time.Time, error)
 }
 
 // OTPRateLimitConfig holds configuration for OTP rate limiting
 type OTPRateLimitConfig struct {
+	Policy      string        // Policy name used in metrics and logs
+	KeyPrefix   string        // Redis key prefix, so policies for different channels count separately
 	MaxAttempts int           // Maximum attempts allowed
 	Window      time.Duration // Time window for rate limiting
 }
@@ -24,6 +29,8 @@ type OTPRateLimitConfig struct {
 // DefaultOTPConfig returns default OTP rate limiting configuration
 func DefaultOTPConfig() OTPRateLimitConfig {
 	return OTPRateLimitConfig{
+		Policy:      "otp_send",
+		KeyPrefix:   "otp",
 		MaxAttempts: 3,
 		Window:      10 * time.Minute,
 	}
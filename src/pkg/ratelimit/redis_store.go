@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -20,12 +21,13 @@ func NewRedisRateLimiter(client *redis.Client) RateLimiter {
 }
 
 // CheckLimit implements sliding window rate limiting using Redis
-func (r *RedisRateLimiter) CheckLimit(key string, limit int, window time.Duration) (bool, error) {
+func (r *RedisRateLimiter) CheckLimit(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	client := r.client.WithContext(ctx)
 	countKey := fmt.Sprintf("rate_limit_count:%s", key)
 	startKey := fmt.Sprintf("rate_limit_start:%s", key)
 
 	// Get current window start time
-	startTs, err := r.client.Get(startKey).Int64()
+	startTs, err := client.Get(startKey).Int64()
 	now := time.Now().Unix()
 	
 	// Handle Redis nil response (key doesn't exist)
@@ -35,7 +37,7 @@ func (r *RedisRateLimiter) CheckLimit(key string, limit int, window time.Duratio
 
 	// If no previous window or window has expired, start new window
 	if err == redis.Nil || now-startTs >= int64(window.Seconds()) {
-		pipe := r.client.TxPipeline()
+		pipe := client.TxPipeline()
 		pipe.Set(startKey, now, window)
 		pipe.Set(countKey, 1, window)
 		_, err := pipe.Exec()
@@ -46,7 +48,7 @@ func (r *RedisRateLimiter) CheckLimit(key string, limit int, window time.Duratio
 	}
 
 	// Check current count in the window
-	count, err := r.client.Get(countKey).Int()
+	count, err := client.Get(countKey).Int()
 	if err != nil && err != redis.Nil {
 		return false, fmt.Errorf("failed to get current count: %w", err)
 	}
@@ -62,7 +64,7 @@ func (r *RedisRateLimiter) CheckLimit(key string, limit int, window time.Duratio
 	}
 
 	// Increment counter
-	err = r.client.Incr(countKey).Err()
+	err = client.Incr(countKey).Err()
 	if err != nil {
 		return false, fmt.Errorf("failed to increment counter: %w", err)
 	}
@@ -71,12 +73,13 @@ func (r *RedisRateLimiter) CheckLimit(key string, limit int, window time.Duratio
 }
 
 // GetRemainingAttempts returns the number of remaining attempts
-func (r *RedisRateLimiter) GetRemainingAttempts(key string, limit int, window time.Duration) (int, error) {
+func (r *RedisRateLimiter) GetRemainingAttempts(ctx context.Context, key string, limit int, window time.Duration) (int, error) {
+	client := r.client.WithContext(ctx)
 	countKey := fmt.Sprintf("rate_limit_count:%s", key)
 	startKey := fmt.Sprintf("rate_limit_start:%s", key)
 
 	// Get current window start time
-	startTs, err := r.client.Get(startKey).Int64()
+	startTs, err := client.Get(startKey).Int64()
 	now := time.Now().Unix()
 	
 	if err != nil && err != redis.Nil {
@@ -89,7 +92,7 @@ func (r *RedisRateLimiter) GetRemainingAttempts(key string, limit int, window ti
 	}
 
 	// Get current count
-	count, err := r.client.Get(countKey).Int()
+	count, err := client.Get(countKey).Int()
 	if err != nil && err != redis.Nil {
 		return 0, fmt.Errorf("failed to get current count: %w", err)
 	}
@@ -107,11 +110,12 @@ func (r *RedisRateLimiter) GetRemainingAttempts(key string, limit int, window ti
 }
 
 // GetResetTime returns when the rate limit will reset
-func (r *RedisRateLimiter) GetResetTime(key string, window time.Duration) (time.Time, error) {
+func (r *RedisRateLimiter) GetResetTime(ctx context.Context, key string, window time.Duration) (time.Time, error) {
+	client := r.client.WithContext(ctx)
 	startKey := fmt.Sprintf("rate_limit_start:%s", key)
 
 	// Get current window start time
-	startTs, err := r.client.Get(startKey).Int64()
+	startTs, err := client.Get(startKey).Int64()
 	if err != nil && err != redis.Nil {
 		return time.Time{}, fmt.Errorf("failed to get start timestamp: %w", err)
 	}
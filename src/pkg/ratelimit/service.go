@@ -1,12 +1,24 @@
 package ratelimit
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/alielmi98/golang-otp-auth/pkg/i18n"
+	"github.com/alielmi98/golang-otp-auth/pkg/metrics"
 	"github.com/alielmi98/golang-otp-auth/pkg/service_errors"
 )
 
+// ErrLimitExceeded is wrapped by the service error returned when a policy rejects a request
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// IsLimitExceeded reports whether err is a policy rejection rather than a storage failure
+func IsLimitExceeded(err error) bool {
+	return errors.Is(err, ErrLimitExceeded)
+}
+
 // OTPRateLimitService provides OTP-specific rate limiting functionality
 type OTPRateLimitService struct {
 	rateLimiter RateLimiter
@@ -22,90 +34,93 @@ func NewOTPRateLimitService(rateLimiter RateLimiter, config OTPRateLimitConfig)
 }
 
 // CheckOTPRateLimit checks if OTP can be sent to the given mobile number
-func (s *OTPRateLimitService) CheckOTPRateLimit(mobileNumber string) error {
-	key := fmt.Sprintf("otp:%s", mobileNumber)
-	
-	allowed, err := s.rateLimiter.CheckLimit(key, s.config.MaxAttempts, s.config.Window)
+func (s *OTPRateLimitService) CheckOTPRateLimit(ctx context.Context, mobileNumber string) error {
+	key := s.key(mobileNumber)
+
+	allowed, err := s.rateLimiter.CheckLimit(ctx, key, s.config.MaxAttempts, s.config.Window)
 	if err != nil {
-		return &service_errors.ServiceError{
-			EndUserMessage:   "Internal server error",
-			TechnicalMessage: "Rate limit check failed",
-			Err:              err,
-		}
+		return newInternalError("Rate limit check failed", err)
 	}
 
 	if !allowed {
-		resetTime, _ := s.rateLimiter.GetResetTime(key, s.config.Window)
-		return &service_errors.ServiceError{
-			EndUserMessage:   fmt.Sprintf("OTP request limit exceeded. Try again after %s", resetTime.Format("15:04:05")),
-			TechnicalMessage: "OTP rate limit exceeded",
-			Err:              nil,
-		}
+		metrics.RateLimitRejections.WithLabelValues(s.config.Policy).Inc()
+		resetTime, _ := s.rateLimiter.GetResetTime(ctx, key, s.config.Window)
+		serviceErr := service_errors.Wrap(service_errors.CodeOtpRateLimited, ErrLimitExceeded)
+		serviceErr.Params = map[string]any{"resetTime": resetTime}
+		serviceErr.EndUserMessage = i18n.Default.MessageOr(string(service_errors.CodeOtpRateLimited), serviceErr.Params, serviceErr.EndUserMessage)
+		return serviceErr
 	}
 
 	return nil
 }
 
+func newInternalError(technicalMessage string, err error) *service_errors.ServiceError {
+	serviceErr := service_errors.Wrap(service_errors.CodeInternal, err)
+	serviceErr.TechnicalMessage = fmt.Sprintf("%s: %v", technicalMessage, err)
+	return serviceErr
+}
+
+func (s *OTPRateLimitService) key(mobileNumber string) string {
+	return fmt.Sprintf("%s:%s", s.config.KeyPrefix, mobileNumber)
+}
+
+// Policy returns the policy name recorded in metrics and audit records
+func (s *OTPRateLimitService) Policy() string {
+	return s.config.Policy
+}
+
 // GetRemainingAttempts returns the number of remaining OTP attempts for a mobile number
-func (s *OTPRateLimitService) GetRemainingAttempts(mobileNumber string) (int, error) {
-	key := fmt.Sprintf("otp:%s", mobileNumber)
-	
-	remaining, err := s.rateLimiter.GetRemainingAttempts(key, s.config.MaxAttempts, s.config.Window)
+func (s *OTPRateLimitService) GetRemainingAttempts(ctx context.Context, mobileNumber string) (int, error) {
+	key := s.key(mobileNumber)
+
+	remaining, err := s.rateLimiter.GetRemainingAttempts(ctx, key, s.config.MaxAttempts, s.config.Window)
 	if err != nil {
-		return 0, &service_errors.ServiceError{
-			EndUserMessage:   "Internal server error",
-			TechnicalMessage: "Failed to get remaining attempts",
-			Err:              err,
-		}
+		return 0, newInternalError("Failed to get remaining attempts", err)
 	}
 
 	return remaining, nil
 }
 
 // GetResetTime returns when the rate limit will reset for a mobile number
-func (s *OTPRateLimitService) GetResetTime(mobileNumber string) (time.Time, error) {
-	key := fmt.Sprintf("otp:%s", mobileNumber)
-	
-	resetTime, err := s.rateLimiter.GetResetTime(key, s.config.Window)
+func (s *OTPRateLimitService) GetResetTime(ctx context.Context, mobileNumber string) (time.Time, error) {
+	key := s.key(mobileNumber)
+
+	resetTime, err := s.rateLimiter.GetResetTime(ctx, key, s.config.Window)
 	if err != nil {
-		return time.Time{}, &service_errors.ServiceError{
-			EndUserMessage:   "Internal server error",
-			TechnicalMessage: "Failed to get reset time",
-			Err:              err,
-		}
+		return time.Time{}, newInternalError("Failed to get reset time", err)
 	}
 
 	return resetTime, nil
 }
 
 // GetRateLimitInfo returns comprehensive rate limit information for a mobile number
-func (s *OTPRateLimitService) GetRateLimitInfo(mobileNumber string) (*OTPRateLimitInfo, error) {
-	remaining, err := s.GetRemainingAttempts(mobileNumber)
+func (s *OTPRateLimitService) GetRateLimitInfo(ctx context.Context, mobileNumber string) (*OTPRateLimitInfo, error) {
+	remaining, err := s.GetRemainingAttempts(ctx, mobileNumber)
 	if err != nil {
 		return nil, err
 	}
 
-	resetTime, err := s.GetResetTime(mobileNumber)
+	resetTime, err := s.GetResetTime(ctx, mobileNumber)
 	if err != nil {
 		return nil, err
 	}
 
 	return &OTPRateLimitInfo{
-		MobileNumber:       mobileNumber,
-		MaxAttempts:        s.config.MaxAttempts,
-		RemainingAttempts:  remaining,
-		WindowDuration:     s.config.Window,
-		ResetTime:          resetTime,
-		IsLimited:          remaining == 0,
+		MobileNumber:      mobileNumber,
+		MaxAttempts:       s.config.MaxAttempts,
+		RemainingAttempts: remaining,
+		WindowDuration:    s.config.Window,
+		ResetTime:         resetTime,
+		IsLimited:         remaining == 0,
 	}, nil
 }
 
 // OTPRateLimitInfo contains comprehensive rate limit information
 type OTPRateLimitInfo struct {
-	MobileNumber       string        `json:"mobile_number"`
-	MaxAttempts        int           `json:"max_attempts"`
-	RemainingAttempts  int           `json:"remaining_attempts"`
-	WindowDuration     time.Duration `json:"window_duration"`
-	ResetTime          time.Time     `json:"reset_time"`
-	IsLimited          bool          `json:"is_limited"`
+	MobileNumber      string        `json:"mobile_number"`
+	MaxAttempts       int           `json:"max_attempts"`
+	RemainingAttempts int           `json:"remaining_attempts"`
+	WindowDuration    time.Duration `json:"window_duration"`
+	ResetTime         time.Time     `json:"reset_time"`
+	IsLimited         bool          `json:"is_limited"`
 }
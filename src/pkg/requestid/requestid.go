@@ -0,0 +1,37 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+const HeaderKey = "X-Request-ID"
+
+// Client-supplied ids are accepted only if they are short and log-safe
+var validId = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx, or "" when there is none
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Resolve keeps a valid incoming id and generates a new one otherwise
+func Resolve(incoming string) string {
+	if validId.MatchString(incoming) {
+		return incoming
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,153 @@
+package service_errors
+
+import (
+	"net/http"
+
+	"github.com/alielmi98/golang-otp-auth/pkg/helper"
+)
+
+// ErrorCode is part of the public API; never rename an existing value
+type ErrorCode string
+
+const (
+	// Token
+	CodeUnexpected          ErrorCode = "UNEXPECTED_ERROR"
+	CodeClaimsNotFound      ErrorCode = "CLAIMS_NOT_FOUND"
+	CodeTokenRequired       ErrorCode = "TOKEN_REQUIRED"
+	CodeTokenExpired        ErrorCode = "TOKEN_EXPIRED"
+	CodeTokenInvalid        ErrorCode = "TOKEN_INVALID"
+	CodeInvalidRefreshToken ErrorCode = "INVALID_REFRESH_TOKEN"
+	CodeInvalidRolesFormat  ErrorCode = "INVALID_ROLES_FORMAT"
+	// OTP
+	CodeOtpExists       ErrorCode = "OTP_EXISTS"
+	CodeOtpUsed         ErrorCode = "OTP_USED"
+	CodeOtpInvalid      ErrorCode = "OTP_INVALID"
+	CodeOtpExpired      ErrorCode = "OTP_EXPIRED"
+	CodeOtpLocked       ErrorCode = "OTP_LOCKED"
+	CodeOtpRateLimited  ErrorCode = "OTP_RATE_LIMITED"
+	CodeOtpThrottled    ErrorCode = "OTP_THROTTLED"
+	CodeChallenge       ErrorCode = "CHALLENGE_REQUIRED"
+	CodeChallengeFailed ErrorCode = "CHALLENGE_FAILED"
+	// User
+	CodeEmailExists        ErrorCode = "EMAIL_EXISTS"
+	CodeUsernameExists     ErrorCode = "USERNAME_EXISTS"
+	CodePermissionDenied   ErrorCode = "PERMISSION_DENIED"
+	CodeInvalidCredentials ErrorCode = "INVALID_CREDENTIALS"
+	CodeInvalidMobile      ErrorCode = "INVALID_MOBILE_NUMBER"
+	CodeInvalidEmail       ErrorCode = "INVALID_EMAIL"
+	CodeWeakPassword       ErrorCode = "PASSWORD_TOO_WEAK"
+	CodePasswordLocked     ErrorCode = "PASSWORD_LOCKED"
+	CodePasswordRequired   ErrorCode = "CURRENT_PASSWORD_REQUIRED"
+	// Second factor
+	CodeTotpNotEnrolled ErrorCode = "TOTP_NOT_ENROLLED"
+	CodeTotpEnrolled    ErrorCode = "TOTP_ALREADY_ENROLLED"
+	CodeMfaTokenInvalid ErrorCode = "MFA_TOKEN_INVALID"
+	CodeRecoveryInvalid ErrorCode = "RECOVERY_CODE_INVALID"
+	// Passkey
+	CodePasskeyInvalid ErrorCode = "PASSKEY_INVALID"
+	CodePasskeyExpired ErrorCode = "PASSKEY_CHALLENGE_EXPIRED"
+	CodePasskeyExists  ErrorCode = "PASSKEY_EXISTS"
+	// Magic link
+	CodeMagicLinkInvalid     ErrorCode = "MAGIC_LINK_INVALID"
+	CodeMagicLinkExpired     ErrorCode = "MAGIC_LINK_EXPIRED"
+	CodeMagicLinkUsed        ErrorCode = "MAGIC_LINK_USED"
+	CodeMagicLinkOtherDevice ErrorCode = "MAGIC_LINK_OTHER_DEVICE"
+	// QR login
+	CodeQrLoginExpired  ErrorCode = "QR_LOGIN_EXPIRED"
+	CodeQrLoginAnswered ErrorCode = "QR_LOGIN_ANSWERED"
+	// Device authorization
+	CodeUserCodeInvalid ErrorCode = "USER_CODE_INVALID"
+	CodeDeviceAnswered  ErrorCode = "DEVICE_AUTHORIZATION_ANSWERED"
+	// OAuth
+	CodeAuthorizationExpired ErrorCode = "AUTHORIZATION_REQUEST_EXPIRED"
+	CodeInvalidOAuthClient   ErrorCode = "INVALID_OAUTH_CLIENT"
+	CodeOAuthClientExists    ErrorCode = "OAUTH_CLIENT_EXISTS"
+	// Phone policy
+	CodePhoneBlocked       ErrorCode = "PHONE_NUMBER_BLOCKED"
+	CodeCountryNotAllowed  ErrorCode = "COUNTRY_NOT_ALLOWED"
+	CodeInvalidPhonePolicy ErrorCode = "INVALID_PHONE_POLICY"
+	// Validation
+	CodeValidation     ErrorCode = "VALIDATION_ERROR"
+	CodeUserIdNotFound ErrorCode = "USER_ID_NOT_FOUND"
+	// DB
+	CodeRecordNotFound ErrorCode = "RECORD_NOT_FOUND"
+	CodeDatabase       ErrorCode = "DATABASE_ERROR"
+	// Internal
+	CodeInternal ErrorCode = "INTERNAL_ERROR"
+	CodeUnknown  ErrorCode = "UNKNOWN_ERROR"
+)
+
+type definition struct {
+	httpStatus int
+	resultCode helper.ResultCode
+	message    string
+}
+
+// definitions is the single mapping from error code to HTTP status, result code and default message
+var definitions = map[ErrorCode]definition{
+	// Token
+	CodeUnexpected:          {http.StatusInternalServerError, helper.InternalError, UnExpectedError},
+	CodeClaimsNotFound:      {http.StatusUnauthorized, helper.AuthError, ClaimsNotFound},
+	CodeTokenRequired:       {http.StatusUnauthorized, helper.AuthError, TokenRequired},
+	CodeTokenExpired:        {http.StatusUnauthorized, helper.AuthError, TokenExpired},
+	CodeTokenInvalid:        {http.StatusUnauthorized, helper.AuthError, TokenInvalid},
+	CodeInvalidRefreshToken: {http.StatusUnauthorized, helper.AuthError, InvalidRefreshToken},
+	CodeInvalidRolesFormat:  {http.StatusBadRequest, helper.BadRequest, InvalidRolesFormat},
+	// OTP
+	CodeOtpExists:       {http.StatusConflict, helper.ConflictError, OptExists},
+	CodeOtpUsed:         {http.StatusBadRequest, helper.BadRequest, OtpUsed},
+	CodeOtpInvalid:      {http.StatusBadRequest, helper.BadRequest, OtpNotValid},
+	CodeOtpExpired:      {http.StatusBadRequest, helper.BadRequest, OtpExpired},
+	CodeOtpLocked:       {http.StatusTooManyRequests, helper.OtpLimiterError, OtpLocked},
+	CodeOtpRateLimited:  {http.StatusTooManyRequests, helper.OtpLimiterError, OtpRateLimited},
+	CodeOtpThrottled:    {http.StatusTooManyRequests, helper.OtpLimiterError, OtpThrottled},
+	CodeChallenge:       {http.StatusForbidden, helper.ForbiddenError, ChallengeRequired},
+	CodeChallengeFailed: {http.StatusForbidden, helper.ForbiddenError, ChallengeFailed},
+	// User
+	CodeEmailExists:        {http.StatusConflict, helper.ConflictError, EmailExists},
+	CodeUsernameExists:     {http.StatusConflict, helper.ConflictError, UsernameExists},
+	CodePermissionDenied:   {http.StatusForbidden, helper.ForbiddenError, PermissionDenied},
+	CodeInvalidCredentials: {http.StatusUnauthorized, helper.AuthError, UsernameOrPasswordInvalid},
+	CodeInvalidMobile:      {http.StatusBadRequest, helper.ValidationError, InvalidMobileNumber},
+	CodeInvalidEmail:       {http.StatusBadRequest, helper.ValidationError, InvalidEmail},
+	CodeWeakPassword:       {http.StatusBadRequest, helper.ValidationError, WeakPassword},
+	CodePasswordLocked:     {http.StatusTooManyRequests, helper.LimiterError, PasswordLocked},
+	CodePasswordRequired:   {http.StatusUnauthorized, helper.AuthError, CurrentPasswordRequired},
+	// Second factor
+	CodeTotpNotEnrolled: {http.StatusNotFound, helper.NotFoundError, TotpNotEnrolled},
+	CodeTotpEnrolled:    {http.StatusConflict, helper.ConflictError, TotpEnrolled},
+	CodeMfaTokenInvalid: {http.StatusUnauthorized, helper.AuthError, MfaTokenInvalid},
+	CodeRecoveryInvalid: {http.StatusBadRequest, helper.BadRequest, RecoveryCodeInvalid},
+	// Passkey
+	CodePasskeyInvalid: {http.StatusUnauthorized, helper.AuthError, PasskeyInvalid},
+	CodePasskeyExpired: {http.StatusBadRequest, helper.BadRequest, PasskeyExpired},
+	CodePasskeyExists:  {http.StatusConflict, helper.ConflictError, PasskeyExists},
+	// Magic link
+	CodeMagicLinkInvalid:     {http.StatusUnauthorized, helper.AuthError, MagicLinkInvalid},
+	CodeMagicLinkExpired:     {http.StatusBadRequest, helper.BadRequest, MagicLinkExpired},
+	CodeMagicLinkUsed:        {http.StatusBadRequest, helper.BadRequest, MagicLinkUsed},
+	CodeMagicLinkOtherDevice: {http.StatusForbidden, helper.ForbiddenError, MagicLinkOtherDevice},
+	// QR login
+	CodeQrLoginExpired:  {http.StatusBadRequest, helper.BadRequest, QrLoginExpired},
+	CodeQrLoginAnswered: {http.StatusConflict, helper.ConflictError, QrLoginAnswered},
+	// Device authorization
+	CodeUserCodeInvalid: {http.StatusBadRequest, helper.BadRequest, UserCodeInvalid},
+	CodeDeviceAnswered:  {http.StatusConflict, helper.ConflictError, DeviceAnswered},
+	// OAuth
+	CodeAuthorizationExpired: {http.StatusBadRequest, helper.BadRequest, AuthorizationExpired},
+	CodeInvalidOAuthClient:   {http.StatusBadRequest, helper.ValidationError, InvalidOAuthClient},
+	CodeOAuthClientExists:    {http.StatusConflict, helper.ConflictError, OAuthClientExists},
+	// Phone policy
+	CodePhoneBlocked:       {http.StatusForbidden, helper.ForbiddenError, PhoneBlocked},
+	CodeCountryNotAllowed:  {http.StatusForbidden, helper.ForbiddenError, CountryNotAllowed},
+	CodeInvalidPhonePolicy: {http.StatusBadRequest, helper.ValidationError, InvalidPhonePolicy},
+	// Validation
+	CodeValidation:     {http.StatusBadRequest, helper.ValidationError, ValidationError},
+	CodeUserIdNotFound: {http.StatusUnauthorized, helper.AuthError, UserIdNotFound},
+	// DB
+	CodeRecordNotFound: {http.StatusNotFound, helper.NotFoundError, RecordNotFound},
+	CodeDatabase:       {http.StatusInternalServerError, helper.DatabaseError, InternalError},
+	// Internal
+	CodeInternal: {http.StatusInternalServerError, helper.InternalError, InternalError},
+	CodeUnknown:  {http.StatusInternalServerError, helper.UnknownError, UnknownError},
+}
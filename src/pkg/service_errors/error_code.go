@@ -13,11 +13,51 @@ const (
 	OptExists   = "Otp exists"
 	OtpUsed     = "Otp used"
 	OtpNotValid = "Otp invalid"
+	OtpExpired  = "Otp expired"
+	OtpLocked   = "Too many invalid otp attempts"
+	// Rate limit
+	OtpRateLimited    = "OTP request limit exceeded"
+	OtpThrottled      = "Too many suspicious OTP requests"
+	ChallengeRequired = "Challenge required"
+	ChallengeFailed   = "Challenge failed"
 	// User
 	EmailExists               = "Email exists"
 	UsernameExists            = "Username exists"
 	PermissionDenied          = "Permission denied"
 	UsernameOrPasswordInvalid = "username or password invalid"
+	InvalidMobileNumber       = "mobile number is not valid"
+	InvalidEmail              = "email is not valid"
+	WeakPassword              = "password does not meet the strength policy"
+	PasswordLocked            = "Too many wrong passwords"
+	CurrentPasswordRequired   = "current password is missing or wrong"
+	// Second factor
+	TotpNotEnrolled     = "TOTP is not enrolled"
+	TotpEnrolled        = "TOTP is already enrolled"
+	MfaTokenInvalid     = "Second-factor login token is invalid or expired"
+	RecoveryCodeInvalid = "Recovery code is invalid or used"
+	// Passkey
+	PasskeyInvalid = "Passkey verification failed"
+	PasskeyExpired = "Passkey challenge expired"
+	PasskeyExists  = "Passkey already registered"
+	// Magic link
+	MagicLinkInvalid     = "Login link is invalid"
+	MagicLinkExpired     = "Login link expired"
+	MagicLinkUsed        = "Login link used"
+	MagicLinkOtherDevice = "Login link was requested on another device"
+	// QR login
+	QrLoginExpired  = "QR login expired"
+	QrLoginAnswered = "QR login already answered"
+	// Device authorization
+	UserCodeInvalid = "User code is invalid or expired"
+	DeviceAnswered  = "Device authorization already answered"
+	// OAuth
+	AuthorizationExpired = "Authorization request expired"
+	InvalidOAuthClient   = "Invalid OAuth client"
+	OAuthClientExists    = "OAuth client exists"
+	// Phone policy
+	PhoneBlocked       = "This phone number cannot receive codes"
+	CountryNotAllowed  = "Phone numbers from this country are not supported"
+	InvalidPhonePolicy = "Invalid phone policy value"
 	// Validation
 	ValidationError = "validation error"
 	UserIdNotFound  = "failed to get user ID from context"
@@ -25,4 +65,5 @@ const (
 	// DB
 	RecordNotFound = "record not found"
 	UnknownError   = "unknown error"
+	InternalError  = "Internal server error"
 )
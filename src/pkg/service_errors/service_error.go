@@ -1,11 +1,71 @@
 package service_errors
 
+import "github.com/alielmi98/golang-otp-auth/pkg/helper"
+
 type ServiceError struct {
-	EndUserMessage   string `json:"endUserMessage"`
-	TechnicalMessage string `json:"technicalMessage"`
-	Err              error
+	// Code is the stable, machine-readable identifier clients branch on, e.g. OTP_EXPIRED
+	Code             ErrorCode         `json:"code"`
+	HttpStatus       int               `json:"-"`
+	ResultCode       helper.ResultCode `json:"-"`
+	EndUserMessage   string            `json:"endUserMessage"`
+	TechnicalMessage string            `json:"technicalMessage"`
+	// Params fill the placeholders of the localized message, e.g. resetTime for OTP_RATE_LIMITED
+	Params map[string]any `json:"-"`
+	Err    error
+}
+
+// New builds the error for code with the status, result code and message from the central mapping
+func New(code ErrorCode) *ServiceError {
+	def, ok := definitions[code]
+	if !ok {
+		def = definitions[CodeUnknown]
+	}
+	return &ServiceError{
+		Code:           code,
+		HttpStatus:     def.httpStatus,
+		ResultCode:     def.resultCode,
+		EndUserMessage: def.message,
+	}
+}
+
+// Wrap is New with the underlying cause attached; the cause is reachable through errors.Is/As
+// but never rendered to clients
+func Wrap(code ErrorCode, err error) *ServiceError {
+	s := New(code)
+	s.Err = err
+	if err != nil {
+		s.TechnicalMessage = err.Error()
+	}
+	return s
 }
 
 func (s *ServiceError) Error() string {
 	return s.EndUserMessage
 }
+
+func (s *ServiceError) Unwrap() error {
+	return s.Err
+}
+
+// Is matches another ServiceError by code, so errors.Is(err, service_errors.New(CodeOtpExpired)) works
+func (s *ServiceError) Is(target error) bool {
+	t, ok := target.(*ServiceError)
+	return ok && t.Code != "" && t.Code == s.Code
+}
+
+// ErrorCode, StatusCode, ResponseCode and MessageParams satisfy helper.TypedError
+func (s *ServiceError) ErrorCode() string {
+	return string(s.Code)
+}
+
+func (s *ServiceError) StatusCode() int {
+	return s.HttpStatus
+}
+
+func (s *ServiceError) ResponseCode() helper.ResultCode {
+	return s.ResultCode
+}
+
+func (s *ServiceError) MessageParams() map[string]any {
+	return s.Params
+}